@@ -0,0 +1,225 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: gate/v1/gate.proto
+
+package gatev1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	GateService_Decide_FullMethodName           = "/planningengine.gate.v1.GateService/Decide"
+	GateService_BatchDecide_FullMethodName      = "/planningengine.gate.v1.GateService/BatchDecide"
+	GateService_GetPolicyVersion_FullMethodName = "/planningengine.gate.v1.GateService/GetPolicyVersion"
+)
+
+// GateServiceClient is the client API for GateService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// GateService answers "is it safe to target N more devices for this deployment
+// stage right now?" using the same fact → policy → audit pipeline as the HTTP API.
+//
+// Fail-safe errors are reported as gRPC status codes. Callers must PAUSE the
+// affected stage and alert on UNAVAILABLE (fact source unavailable, policy or
+// config not loaded) and FAILED_PRECONDITION (fact data stale); INTERNAL means
+// the policy could not be evaluated and must be treated the same way.
+type GateServiceClient interface {
+	// Decide evaluates a single request.
+	Decide(ctx context.Context, in *DecideRequest, opts ...grpc.CallOption) (*DecideResponse, error)
+	// BatchDecide evaluates several requests against the same policy bundle.
+	// Per-request failures are reported inline; the call itself only fails if
+	// the batch is malformed or the policy bundle cannot be loaded.
+	BatchDecide(ctx context.Context, in *BatchDecideRequest, opts ...grpc.CallOption) (*BatchDecideResponse, error)
+	// GetPolicyVersion reports the policy and configuration versions currently
+	// used for decisions.
+	GetPolicyVersion(ctx context.Context, in *GetPolicyVersionRequest, opts ...grpc.CallOption) (*GetPolicyVersionResponse, error)
+}
+
+type gateServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGateServiceClient(cc grpc.ClientConnInterface) GateServiceClient {
+	return &gateServiceClient{cc}
+}
+
+func (c *gateServiceClient) Decide(ctx context.Context, in *DecideRequest, opts ...grpc.CallOption) (*DecideResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DecideResponse)
+	err := c.cc.Invoke(ctx, GateService_Decide_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gateServiceClient) BatchDecide(ctx context.Context, in *BatchDecideRequest, opts ...grpc.CallOption) (*BatchDecideResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchDecideResponse)
+	err := c.cc.Invoke(ctx, GateService_BatchDecide_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gateServiceClient) GetPolicyVersion(ctx context.Context, in *GetPolicyVersionRequest, opts ...grpc.CallOption) (*GetPolicyVersionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetPolicyVersionResponse)
+	err := c.cc.Invoke(ctx, GateService_GetPolicyVersion_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GateServiceServer is the server API for GateService service.
+// All implementations must embed UnimplementedGateServiceServer
+// for forward compatibility.
+//
+// GateService answers "is it safe to target N more devices for this deployment
+// stage right now?" using the same fact → policy → audit pipeline as the HTTP API.
+//
+// Fail-safe errors are reported as gRPC status codes. Callers must PAUSE the
+// affected stage and alert on UNAVAILABLE (fact source unavailable, policy or
+// config not loaded) and FAILED_PRECONDITION (fact data stale); INTERNAL means
+// the policy could not be evaluated and must be treated the same way.
+type GateServiceServer interface {
+	// Decide evaluates a single request.
+	Decide(context.Context, *DecideRequest) (*DecideResponse, error)
+	// BatchDecide evaluates several requests against the same policy bundle.
+	// Per-request failures are reported inline; the call itself only fails if
+	// the batch is malformed or the policy bundle cannot be loaded.
+	BatchDecide(context.Context, *BatchDecideRequest) (*BatchDecideResponse, error)
+	// GetPolicyVersion reports the policy and configuration versions currently
+	// used for decisions.
+	GetPolicyVersion(context.Context, *GetPolicyVersionRequest) (*GetPolicyVersionResponse, error)
+	mustEmbedUnimplementedGateServiceServer()
+}
+
+// UnimplementedGateServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedGateServiceServer struct{}
+
+func (UnimplementedGateServiceServer) Decide(context.Context, *DecideRequest) (*DecideResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Decide not implemented")
+}
+func (UnimplementedGateServiceServer) BatchDecide(context.Context, *BatchDecideRequest) (*BatchDecideResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchDecide not implemented")
+}
+func (UnimplementedGateServiceServer) GetPolicyVersion(context.Context, *GetPolicyVersionRequest) (*GetPolicyVersionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPolicyVersion not implemented")
+}
+func (UnimplementedGateServiceServer) mustEmbedUnimplementedGateServiceServer() {}
+func (UnimplementedGateServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeGateServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GateServiceServer will
+// result in compilation errors.
+type UnsafeGateServiceServer interface {
+	mustEmbedUnimplementedGateServiceServer()
+}
+
+func RegisterGateServiceServer(s grpc.ServiceRegistrar, srv GateServiceServer) {
+	// If the following call pancis, it indicates UnimplementedGateServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&GateService_ServiceDesc, srv)
+}
+
+func _GateService_Decide_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DecideRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GateServiceServer).Decide(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GateService_Decide_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GateServiceServer).Decide(ctx, req.(*DecideRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GateService_BatchDecide_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchDecideRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GateServiceServer).BatchDecide(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GateService_BatchDecide_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GateServiceServer).BatchDecide(ctx, req.(*BatchDecideRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GateService_GetPolicyVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPolicyVersionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GateServiceServer).GetPolicyVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GateService_GetPolicyVersion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GateServiceServer).GetPolicyVersion(ctx, req.(*GetPolicyVersionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// GateService_ServiceDesc is the grpc.ServiceDesc for GateService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var GateService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "planningengine.gate.v1.GateService",
+	HandlerType: (*GateServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Decide",
+			Handler:    _GateService_Decide_Handler,
+		},
+		{
+			MethodName: "BatchDecide",
+			Handler:    _GateService_BatchDecide_Handler,
+		},
+		{
+			MethodName: "GetPolicyVersion",
+			Handler:    _GateService_GetPolicyVersion_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "gate/v1/gate.proto",
+}
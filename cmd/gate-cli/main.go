@@ -0,0 +1,228 @@
+// Command gate-cli evaluates a deployment gate decision without the service, so that a rollout
+// plan can be checked against current or simulated facts before it starts:
+//
+//	gate-cli --dry-run --facts=local_facts.json --policy=./policy/bundle.tar.gz \
+//	    --deployment=dep-123 --stage=canary --requested-count=50
+//
+// Facts are read from a JSON or YAML file, or collected live from the fact providers of the
+// PKL configuration. Decisions are not audited. The exit code is 0 for allow, 1 for deny and
+// 2 for any error, including facts that could not be collected.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/asimihsan/planning_engine/internal/api/httpapi"
+	"github.com/asimihsan/planning_engine/internal/engine/opa"
+	factregistry "github.com/asimihsan/planning_engine/internal/fact/registry"
+	"github.com/asimihsan/planning_engine/internal/policy/file"
+	"github.com/asimihsan/planning_engine/pkg/config/loader"
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+// Exit codes.
+const (
+	exitAllow = 0
+	exitDeny  = 1
+	exitError = 2
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// result is printed with -format json. It has the shape of a POST /v1/decisions response,
+// with the facts the decision was made on.
+type result struct {
+	httpapi.DecideResponse
+	Facts        map[string]any           `json:"facts,omitempty"`
+	FactMeta     map[string]gate.FactMeta `json:"fact_meta,omitempty"`     // How each fact was collected
+	MissingFacts []string                 `json:"missing_facts,omitempty"` // Optional facts that could not be collected
+}
+
+// run evaluates one decision and returns the process exit code.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("gate-cli", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	dryRun := fs.Bool("dry-run", true, "evaluate without auditing the decision; the only supported mode")
+	factsPath := fs.String("facts", "", "JSON or YAML file of fact values keyed by fact ID; if empty, facts are collected from the providers in -config")
+	configPath := fs.String("config", "policy/local/local.pkl", "path to the PKL configuration file, for live facts")
+	policyPath := fs.String("policy", "policy/rego/main.rego", "Rego file, OPA bundle directory or bundle tarball (.tar.gz), optionally as a file:// URI")
+	policyQuery := fs.String("query", "data.gate.response", "Rego query producing the gate response")
+	format := fs.String("format", "text", "output format: text or json")
+	var req gate.DecisionRequest
+	fs.StringVar(&req.DeploymentID, "deployment", "", "deployment ID")
+	fs.StringVar(&req.Stage, "stage", "", "stage")
+	fs.IntVar(&req.RequestedCount, "requested-count", 0, "number of devices requested")
+	fs.StringVar(&req.Caller, "caller", "gate-cli", "caller recorded in input.request")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if !*dryRun {
+		fmt.Fprintln(stderr, "gate-cli only supports dry runs; use the service to make audited decisions")
+		return exitError
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(stderr, "unknown format %q\n", *format)
+		return exitError
+	}
+
+	out := result{DecideResponse: httpapi.DecideResponse{Outcome: httpapi.OutcomeError}}
+	decision, snapshot, err := evaluate(ctx, req, *factsPath, *configPath, strings.TrimPrefix(*policyPath, "file://"), *policyQuery)
+	out.Facts, out.FactMeta, out.MissingFacts = snapshot.Facts, snapshot.Meta, snapshot.Missing
+	switch {
+	case err != nil:
+		out.Error = err.Error()
+	case decision.Allow:
+		out.Outcome, out.Decision = httpapi.OutcomeAllow, &decision
+	default:
+		out.Outcome, out.Decision = httpapi.OutcomeDeny, &decision
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(out); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitError
+		}
+	} else {
+		printResult(stdout, out)
+	}
+
+	switch out.Outcome {
+	case httpapi.OutcomeAllow:
+		return exitAllow
+	case httpapi.OutcomeDeny:
+		return exitDeny
+	default:
+		return exitError
+	}
+}
+
+// evaluate collects or loads the facts, validates them against the policy's input schema and
+// evaluates them, as the gate would, returning the decision and the facts it was made on.
+func evaluate(ctx context.Context, req gate.DecisionRequest, factsPath, configPath, policyPath, query string) (gate.Decision, gate.FactSnapshot, error) {
+	var (
+		snapshot  gate.FactSnapshot
+		configSHA string
+		err       error
+	)
+	// The service rejects an invalid request whatever the facts, so the CLI does too
+	if err := req.Validate(); err != nil {
+		return gate.Decision{}, snapshot, err
+	}
+	if factsPath != "" {
+		snapshot.Facts, err = loadFacts(factsPath)
+	} else {
+		snapshot, configSHA, err = collectFacts(ctx, req, configPath)
+	}
+	if err != nil {
+		return gate.Decision{}, snapshot, err
+	}
+
+	bundle, err := file.New(policyPath, query).GetPolicyBundle(ctx)
+	if err != nil {
+		return gate.Decision{}, snapshot, err
+	}
+	meta := gate.InputMeta{
+		Timestamp: time.Now(),
+		PolicySHA: bundle.ID(),
+		ConfigSHA: configSHA,
+		Missing:   snapshot.Missing,
+		Facts:     snapshot.Meta,
+	}
+	if signed, ok := bundle.(gate.SignedPolicyBundle); ok {
+		meta.PolicyKeyID = signed.SigningKeyID()
+	}
+
+	input := gate.NewPolicyInput(req, snapshot.Facts, meta).Map()
+	if validated, ok := bundle.(gate.SchemaPolicyBundle); ok {
+		if err := validated.ValidateInput(ctx, input); err != nil {
+			return gate.Decision{}, snapshot, err
+		}
+	}
+
+	start := time.Now()
+	decision, err := opa.NewEngine().Evaluate(ctx, bundle, input)
+	if err != nil {
+		return gate.Decision{}, snapshot, err
+	}
+	decision.EvalDuration = time.Since(start)
+	decision.PolicySHA, decision.PolicyKeyID, decision.ConfigSHA = meta.PolicySHA, meta.PolicyKeyID, meta.ConfigSHA
+	return decision, snapshot, nil
+}
+
+// loadFacts reads fact values keyed by fact ID from a JSON or YAML file.
+func loadFacts(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading facts: %w", err)
+	}
+	if ext := filepath.Ext(path); ext == ".yaml" || ext == ".yml" {
+		if data, err = yaml.YAMLToJSON(data); err != nil {
+			return nil, fmt.Errorf("parsing facts %s: %w", path, err)
+		}
+	}
+
+	// Keep numbers as written, as the policy would see them from a live provider
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var facts map[string]any
+	if err := dec.Decode(&facts); err != nil {
+		return nil, fmt.Errorf("parsing facts %s: %w", path, err)
+	}
+	if facts == nil {
+		return nil, fmt.Errorf("parsing facts %s: %w", path, errors.New("expected an object of fact values keyed by fact ID"))
+	}
+	return facts, nil
+}
+
+// collectFacts snapshots the configured fact providers for req, with the configured staleness
+// and timeout limits, and returns the snapshot and the configuration's SHA.
+func collectFacts(ctx context.Context, req gate.DecisionRequest, configPath string) (gate.FactSnapshot, string, error) {
+	cfg, sha, err := loader.LoadFromPathWithSHA(ctx, configPath)
+	if err != nil {
+		return gate.FactSnapshot{}, "", err
+	}
+	snapshot, err := factregistry.New(cfg).Collect(ctx, req.DeploymentID, req.Stage, factregistry.SnapshotOpts(cfg))
+	return snapshot, sha, err
+}
+
+func printResult(w io.Writer, out result) {
+	fmt.Fprintf(w, "Decision: %s\n", out.Outcome)
+	if out.Error != "" {
+		fmt.Fprintf(w, "Error: %s\n", out.Error)
+	}
+	if len(out.MissingFacts) > 0 {
+		fmt.Fprintf(w, "Missing optional facts: %s\n", strings.Join(out.MissingFacts, ", "))
+	}
+	if d := out.Decision; d != nil {
+		if len(d.DenyReasons) > 0 {
+			fmt.Fprintln(w, "Reasons:")
+			for _, reason := range d.DenyReasons {
+				fmt.Fprintf(w, "  - %s\n", reason)
+			}
+		}
+		fmt.Fprintf(w, "Policy SHA: %s\n", d.PolicySHA)
+		fmt.Fprintf(w, "Evaluation time: %s\n", d.EvalDuration)
+		if d.UsedFacts != nil {
+			fmt.Fprintf(w, "Facts read: %s\n", strings.Join(d.UsedFacts, ", "))
+		}
+	}
+}
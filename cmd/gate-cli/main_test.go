@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/asimihsan/planning_engine/internal/api/httpapi"
+)
+
+const testPolicy = "../../policy/rego/main.rego"
+
+func writeFacts(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func runCLI(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun(t *testing.T) {
+	request := []string{"--deployment=dep-123", "--stage=canary", "--requested-count=50", "--policy=" + testPolicy}
+
+	t.Run("Allow from JSON facts", func(t *testing.T) {
+		facts := writeFacts(t, "facts.json", `{"pending_delta": 100, "max_pending_allowed": 500}`)
+
+		code, stdout, _ := runCLI(append(request, "--dry-run", "--facts="+facts)...)
+		assert.Equal(t, exitAllow, code)
+		assert.Contains(t, stdout, "Decision: allow")
+		assert.Contains(t, stdout, "Evaluation time: ")
+	})
+
+	t.Run("Deny from YAML facts", func(t *testing.T) {
+		facts := writeFacts(t, "facts.yaml", "pending_delta: 480\nmax_pending_allowed: 500\n")
+
+		code, stdout, _ := runCLI(append(request, "--facts="+facts, "--format=json")...)
+		assert.Equal(t, exitDeny, code)
+
+		var out result
+		require.NoError(t, json.Unmarshal([]byte(stdout), &out))
+		assert.Equal(t, httpapi.OutcomeDeny, out.Outcome)
+		assert.Equal(t, []string{"pending_delta exceeds allowed limit"}, out.DenyReasons)
+		assert.Equal(t, []string{"max_pending_allowed", "pending_delta"}, out.UsedFacts)
+		assert.NotEmpty(t, out.PolicySHA)
+		assert.Equal(t, map[string]any{"pending_delta": 480.0, "max_pending_allowed": 500.0}, out.Facts)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		facts := writeFacts(t, "facts.json", `{"pending_delta": 100, "max_pending_allowed": 500}`)
+		malformed := writeFacts(t, "facts.json", `[1, 2]`)
+
+		for name, args := range map[string][]string{
+			"Missing facts file": append(request, "--facts=missing.json"),
+			"Malformed facts":    append(request, "--facts="+malformed),
+			"Schema violation":   append(request, "--facts="+writeFacts(t, "invalid.json", `{"pending_delta": "100", "max_pending_allowed": 500}`)),
+			"Missing policy":     append(request, "--facts="+facts, "--policy=missing.rego"),
+			"Missing deployment": {"--stage=canary", "--requested-count=50", "--policy=" + testPolicy, "--facts=" + facts},
+			"Missing stage":      {"--deployment=dep-123", "--requested-count=50", "--policy=" + testPolicy, "--facts=" + facts},
+			"Negative count":     {"--deployment=dep-123", "--stage=canary", "--requested-count=-1", "--policy=" + testPolicy, "--facts=" + facts},
+			"Not a dry run":      append(request, "--dry-run=false", "--facts="+facts),
+			"Unknown flag":       {"--verbose"},
+		} {
+			code, stdout, stderr := runCLI(args...)
+			assert.Equal(t, exitError, code, name)
+			assert.NotEmpty(t, stdout+stderr, name)
+		}
+	})
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	awsdynamodb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+
+	"github.com/asimihsan/planning_engine/internal/api/httpapi"
+	"github.com/asimihsan/planning_engine/internal/audit/chain"
+	"github.com/asimihsan/planning_engine/internal/audit/dynamodb"
+	auditfile "github.com/asimihsan/planning_engine/internal/audit/file"
+	appconfig "github.com/asimihsan/planning_engine/internal/config"
+	"github.com/asimihsan/planning_engine/internal/config/logtarget"
+	"github.com/asimihsan/planning_engine/pkg/config/loader"
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+// Exit codes of the audit subcommands.
+const (
+	exitOK     = 0
+	exitBroken = 1 // The audit log failed verification
+	exitError  = 2
+)
+
+const auditUsage = `usage: planning-engine audit <command> [flags]
+
+commands:
+  verify    check the hash chain of an audit log file or DynamoDB table
+  query     list the decisions in an audit log file or DynamoDB table
+`
+
+// runAudit runs an audit subcommand and returns the process exit code.
+func runAudit(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, auditUsage)
+		return exitError
+	}
+
+	switch args[0] {
+	case "verify":
+		return runAuditVerify(ctx, args[1:], stdout, stderr)
+	case "query":
+		return runAuditQuery(ctx, args[1:], stdout, stderr)
+	default:
+		fmt.Fprintf(stderr, "unknown audit command %q\n%s", args[0], auditUsage)
+		return exitError
+	}
+}
+
+// auditSource names the audit log to read: a file, or a DynamoDB table.
+type auditSource struct {
+	configPath string
+	path       string
+	table      string
+	endpoint   string
+}
+
+func (s *auditSource) register(fs *flag.FlagSet) {
+	fs.StringVar(&s.configPath, "config", "policy/local/local.pkl", "path to the PKL configuration file naming the audit log, if -file and -table are not given")
+	fs.StringVar(&s.path, "file", "", "path of a JSON Lines audit log; its rotated files are read too")
+	fs.StringVar(&s.table, "table", "", "name of a DynamoDB audit table")
+	fs.StringVar(&s.endpoint, "endpoint", "", "DynamoDB endpoint override, e.g. for DynamoDB Local")
+}
+
+// resolve fills in the audit log from the configuration if none was named on the command line.
+func (s *auditSource) resolve(ctx context.Context) error {
+	if s.path != "" || s.table != "" {
+		return nil
+	}
+
+	cfg, _, err := loader.LoadFromPathWithSHA(ctx, s.configPath)
+	if err != nil {
+		return fmt.Errorf("loading configuration: %w", err)
+	}
+	return s.fromConfig(cfg.Audit)
+}
+
+// fromConfig names the first configured audit log target that can be read back.
+func (s *auditSource) fromConfig(cfg *appconfig.Audit) error {
+	targets := []logtarget.LogTarget{cfg.LogTarget}
+	if len(cfg.Sinks) > 0 {
+		targets = targets[:0]
+		for _, sink := range cfg.Sinks {
+			targets = append(targets, sink.Target)
+		}
+	}
+
+	for _, target := range targets {
+		switch target {
+		case logtarget.File:
+			s.path = cfg.File.Path
+			return nil
+		case logtarget.Dynamodb:
+			s.table = cfg.DynamoTableName
+			if s.endpoint == "" && cfg.DynamoEndpoint != nil {
+				s.endpoint = *cfg.DynamoEndpoint
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("none of the configured audit log targets %v can be read back", targets)
+}
+
+// query resolves the audit log and returns its records matching q.
+func (s *auditSource) query(ctx context.Context, q gate.AuditQuery) ([]gate.AuditRecord, error) {
+	if err := s.resolve(ctx); err != nil {
+		return nil, err
+	}
+	reader, err := s.reader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return reader.Query(ctx, q)
+}
+
+// reader returns a reader of the named audit log.
+func (s *auditSource) reader(ctx context.Context) (gate.AuditReader, error) {
+	if s.path != "" {
+		return auditfile.NewReader(s.path), nil
+	}
+	client, err := s.dynamoClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return dynamodb.NewReader(client, s.table), nil
+}
+
+func (s *auditSource) dynamoClient(ctx context.Context) (*awsdynamodb.Client, error) {
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("loading AWS configuration: %w", err)
+	}
+	return awsdynamodb.NewFromConfig(awsCfg, func(o *awsdynamodb.Options) {
+		if s.endpoint != "" {
+			o.BaseEndpoint = &s.endpoint
+		}
+	}), nil
+}
+
+// runAuditVerify walks the hash chain of an audit log, reports the first broken link and
+// prints the chain head, which can be pinned to later detect truncation.
+func runAuditVerify(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("audit verify", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var source auditSource
+	source.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if err := source.resolve(ctx); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	if source.path != "" {
+		head, count, err := auditfile.Verify(source.path)
+		if err != nil {
+			return reportVerifyError(stderr, err)
+		}
+		fmt.Fprintf(stdout, "Verified %d records in %s\nChain head: %s\n", count, source.path, head)
+		return exitOK
+	}
+
+	client, err := source.dynamoClient(ctx)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	heads, err := dynamodb.Verify(ctx, client, source.table)
+	if err != nil {
+		return reportVerifyError(stderr, err)
+	}
+	fmt.Fprintf(stdout, "Verified %d chains in %s\n", len(heads), source.table)
+	for _, h := range heads {
+		fmt.Fprintf(stdout, "%s: %d records, chain head: %s\n", h.DeploymentStage, h.Count, h.Head)
+	}
+	return exitOK
+}
+
+func reportVerifyError(stderr io.Writer, err error) int {
+	fmt.Fprintln(stderr, err)
+	if errors.Is(err, chain.ErrBroken) {
+		return exitBroken
+	}
+	return exitError
+}
+
+// auditFilter holds the flags selecting audit records.
+type auditFilter struct {
+	base                  gate.AuditQuery // The fields set directly by flags
+	outcome, since, until string
+}
+
+func (f *auditFilter) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.base.DeploymentID, "deployment", "", "only decisions for this deployment ID")
+	fs.StringVar(&f.base.Stage, "stage", "", "only decisions for this stage")
+	fs.StringVar(&f.outcome, "outcome", "", "only decisions with this outcome: allow, deny or error")
+	fs.StringVar(&f.base.PolicySHA, "policy-sha", "", "only decisions made by the policy with this SHA")
+	fs.StringVar(&f.since, "since", "", "only decisions at or after this RFC 3339 time, or this long ago, e.g. 6h")
+	fs.StringVar(&f.until, "until", "", "only decisions before this RFC 3339 time, or this long ago")
+	fs.IntVar(&f.base.Limit, "limit", 0, "only the newest decisions, if positive")
+}
+
+// query returns the audit query selected by the flags.
+func (f *auditFilter) query(now time.Time) (gate.AuditQuery, error) {
+	q := f.base
+	q.Outcome = gate.Outcome(f.outcome)
+	switch q.Outcome {
+	case "", gate.OutcomeAllow, gate.OutcomeDeny, gate.OutcomeError:
+	default:
+		return q, fmt.Errorf("unknown outcome %q", f.outcome)
+	}
+
+	var err error
+	if f.since != "" {
+		if q.Since, err = gate.ParseAuditTime(f.since, now); err != nil {
+			return q, fmt.Errorf("-since: %w", err)
+		}
+	}
+	if f.until != "" {
+		if q.Until, err = gate.ParseAuditTime(f.until, now); err != nil {
+			return q, fmt.Errorf("-until: %w", err)
+		}
+	}
+	return q, nil
+}
+
+// runAuditQuery prints the audit records matching its flags, as a table or as JSON.
+func runAuditQuery(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("audit query", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var source auditSource
+	source.register(fs)
+	var filter auditFilter
+	filter.register(fs)
+	format := fs.String("format", "table", "output format: table or json")
+	groupBy := fs.String("group-by", "", `with "reason", print how many decisions gave each deny reason or error class instead of the decisions`)
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if *format != "table" && *format != "json" {
+		fmt.Fprintf(stderr, "unknown format %q\n", *format)
+		return exitError
+	}
+	if *groupBy != "" && *groupBy != "reason" {
+		fmt.Fprintf(stderr, "unknown grouping %q\n", *groupBy)
+		return exitError
+	}
+	q, err := filter.query(time.Now())
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	records, err := source.query(ctx, q)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	reasons := gate.CountReasons(records)
+
+	switch {
+	case *format == "json":
+		if records == nil {
+			records = []gate.AuditRecord{}
+		}
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(httpapi.AuditResponse{Records: records, Reasons: reasons}); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitError
+		}
+	case *groupBy == "reason":
+		w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "COUNT\tREASON")
+		for _, r := range reasons {
+			fmt.Fprintf(w, "%d\t%s\n", r.Count, r.Reason)
+		}
+		_ = w.Flush()
+	default:
+		w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TIME\tDEPLOYMENT\tSTAGE\tOUTCOME\tREASON\tDECISION ID")
+		for _, r := range records {
+			reason := strings.Join(r.DenyReasons, "; ")
+			if r.Outcome == gate.OutcomeError {
+				reason = r.ErrorClass
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", r.Timestamp.UTC().Format(time.RFC3339), r.DeploymentID, r.Stage, r.Outcome, reason, r.DecisionID)
+		}
+		_ = w.Flush()
+	}
+	return exitOK
+}
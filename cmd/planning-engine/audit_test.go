@@ -0,0 +1,257 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	awsdynamodb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/asimihsan/planning_engine/internal/api/httpapi"
+	"github.com/asimihsan/planning_engine/internal/audit/dynamodb"
+	"github.com/asimihsan/planning_engine/internal/audit/dynamodb_mock"
+	auditfile "github.com/asimihsan/planning_engine/internal/audit/file"
+	appconfig "github.com/asimihsan/planning_engine/internal/config"
+	"github.com/asimihsan/planning_engine/internal/config/logtarget"
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+func runCLI(run func(context.Context, []string, io.Writer, io.Writer) int, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// auditRecords are an allow, a deny and a fact error for dep-123, a minute apart and ending
+// a minute ago.
+func auditRecords() []gate.AuditRecord {
+	start := time.Now().Add(-3 * time.Minute).UTC()
+	facts := map[string]any{"pending_delta": 100, "max_pending_allowed": 500}
+	return []gate.AuditRecord{
+		{DecisionID: "d-allow", Timestamp: start, DeploymentID: "dep-123", Stage: "canary", RequestedCount: 50,
+			Outcome: gate.OutcomeAllow, PolicySHA: "policy-sha", UsedFacts: facts},
+		{DecisionID: "d-deny", Timestamp: start.Add(time.Minute), DeploymentID: "dep-123", Stage: "canary", RequestedCount: 450,
+			Outcome: gate.OutcomeDeny, DenyReasons: []string{"pending_delta exceeds allowed limit"}, PolicySHA: "policy-sha", UsedFacts: facts},
+		{DecisionID: "d-error", Timestamp: start.Add(2 * time.Minute), DeploymentID: "dep-123", Stage: "prod",
+			Outcome: gate.OutcomeError, ErrorClass: "fact_stale", ErrorDetails: "gate: fact stale"},
+	}
+}
+
+// writeAuditFile writes records to a new file audit log and returns its path.
+func writeAuditFile(t *testing.T, records []gate.AuditRecord) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	logger := auditfile.New(path)
+	for _, record := range records {
+		require.NoError(t, logger.Log(context.Background(), record))
+	}
+	require.NoError(t, logger.Close())
+	return path
+}
+
+// writeAuditTable writes records to a new audit table on a mock DynamoDB and returns the
+// endpoint and table flags reading it.
+func writeAuditTable(t *testing.T, records []gate.AuditRecord) []string {
+	t.Helper()
+	t.Setenv("AWS_REGION", "us-east-1")
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+
+	srv := dynamodb_mock.NewServer()
+	t.Cleanup(srv.Close)
+	client := awsdynamodb.New(awsdynamodb.Options{
+		BaseEndpoint: aws.String(srv.URL()),
+		Region:       "us-east-1",
+		Credentials:  credentials.NewStaticCredentialsProvider("test", "test", ""),
+	})
+	ctx := context.Background()
+	require.NoError(t, dynamodb.CreateTable(ctx, client, "audit"))
+	logger := dynamodb.New(client, "audit")
+	for _, record := range records {
+		require.NoError(t, logger.Log(ctx, record))
+	}
+	return []string{"-endpoint=" + srv.URL(), "-table=audit"}
+}
+
+func TestRunAudit(t *testing.T) {
+	path := writeAuditFile(t, auditRecords())
+	table := writeAuditTable(t, auditRecords())
+
+	// A copy of the log with a decision edited after it was written
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	tampered := filepath.Join(t.TempDir(), "tampered.jsonl")
+	require.NoError(t, os.WriteFile(tampered, bytes.Replace(data, []byte(`"outcome":"deny"`), []byte(`"outcome":"allow"`), 1), 0o600))
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		want     []string // Substrings of stdout, or of stderr for failures
+		notWant  []string
+	}{
+		{
+			name:     "Verify file",
+			args:     []string{"verify", "-file=" + path},
+			wantCode: exitOK,
+			want:     []string{"Verified 3 records in " + path, "Chain head: "},
+		},
+		{
+			name:     "Verify table",
+			args:     append([]string{"verify"}, table...),
+			wantCode: exitOK,
+			want:     []string{"Verified 2 chains in audit", "dep-123#canary: 2 records", "dep-123#prod: 1 records"},
+		},
+		{
+			name:     "Verify tampered file",
+			args:     []string{"verify", "-file=" + tampered},
+			wantCode: exitBroken,
+			want:     []string{"audit chain broken"},
+		},
+		{
+			name:     "Verify missing file",
+			args:     []string{"verify", "-file=" + filepath.Join(t.TempDir(), "missing.jsonl")},
+			wantCode: exitError,
+			want:     []string{"no audit log"},
+		},
+		{
+			name:     "Verify without a readable configuration",
+			args:     []string{"verify", "-config=" + filepath.Join(t.TempDir(), "missing.pkl")},
+			wantCode: exitError,
+			want:     []string{"loading configuration"},
+		},
+		{
+			name:     "Query file as a table",
+			args:     []string{"query", "-file=" + path},
+			wantCode: exitOK,
+			want:     []string{"TIME", "DECISION ID", "d-allow", "d-deny", "pending_delta exceeds allowed limit", "d-error", "fact_stale"},
+		},
+		{
+			name:     "Query table with filters",
+			args:     append([]string{"query", "-deployment=dep-123", "-stage=canary", "-outcome=deny", "-since=1h"}, table...),
+			wantCode: exitOK,
+			want:     []string{"d-deny"},
+			notWant:  []string{"d-allow", "d-error"},
+		},
+		{
+			name:     "Query the newest records",
+			args:     []string{"query", "-file=" + path, "-limit=1"},
+			wantCode: exitOK,
+			want:     []string{"d-error"},
+			notWant:  []string{"d-allow", "d-deny"},
+		},
+		{
+			name:     "Group by reason",
+			args:     []string{"query", "-file=" + path, "-group-by=reason"},
+			wantCode: exitOK,
+			want:     []string{"COUNT", "REASON", "pending_delta exceeds allowed limit", "fact_stale"},
+			notWant:  []string{"d-deny"},
+		},
+		{name: "No command", wantCode: exitError, want: []string{"usage: planning-engine audit"}},
+		{name: "Unknown command", args: []string{"repair"}, wantCode: exitError, want: []string{`unknown audit command "repair"`}},
+		{name: "Unknown format", args: []string{"query", "-file=" + path, "-format=yaml"}, wantCode: exitError, want: []string{`unknown format "yaml"`}},
+		{name: "Unknown grouping", args: []string{"query", "-file=" + path, "-group-by=stage"}, wantCode: exitError, want: []string{`unknown grouping "stage"`}},
+		{name: "Unknown outcome", args: []string{"query", "-file=" + path, "-outcome=pause"}, wantCode: exitError, want: []string{`unknown outcome "pause"`}},
+		{name: "Malformed since", args: []string{"query", "-file=" + path, "-since=yesterday"}, wantCode: exitError, want: []string{"-since"}},
+		{name: "Unknown flag", args: []string{"query", "-verbose"}, wantCode: exitError, want: []string{"-verbose"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, stdout, stderr := runCLI(runAudit, tt.args...)
+			assert.Equal(t, tt.wantCode, code, stderr)
+			out := stdout
+			if tt.wantCode != exitOK {
+				out = stderr
+			}
+			for _, want := range tt.want {
+				assert.Contains(t, out, want)
+			}
+			for _, notWant := range tt.notWant {
+				assert.NotContains(t, out, notWant)
+			}
+		})
+	}
+
+	t.Run("Query as JSON", func(t *testing.T) {
+		code, stdout, stderr := runCLI(runAudit, "query", "-file="+path, "-outcome=deny", "-format=json")
+		require.Equal(t, exitOK, code, stderr)
+
+		var resp httpapi.AuditResponse
+		require.NoError(t, json.Unmarshal([]byte(stdout), &resp))
+		require.Len(t, resp.Records, 1)
+		assert.Equal(t, "d-deny", resp.Records[0].DecisionID)
+		assert.Equal(t, []gate.ReasonCount{{Reason: "pending_delta exceeds allowed limit", Count: 1}}, resp.Reasons)
+	})
+
+	t.Run("Empty query as JSON", func(t *testing.T) {
+		code, stdout, _ := runCLI(runAudit, "query", "-file="+path, "-deployment=other", "-format=json")
+		require.Equal(t, exitOK, code)
+		assert.Contains(t, stdout, `"records": []`)
+	})
+}
+
+func TestAuditSource_FromConfig(t *testing.T) {
+	endpoint := "http://localhost:8000"
+	file := &appconfig.AuditFile{Path: "/var/log/audit.jsonl"}
+
+	tests := []struct {
+		name    string
+		cfg     appconfig.Audit
+		want    auditSource
+		wantErr bool
+	}{
+		{
+			name: "File target",
+			cfg:  appconfig.Audit{LogTarget: logtarget.File, File: file},
+			want: auditSource{path: "/var/log/audit.jsonl"},
+		},
+		{
+			name: "DynamoDB target",
+			cfg:  appconfig.Audit{LogTarget: logtarget.Dynamodb, DynamoTableName: "audit", DynamoEndpoint: &endpoint},
+			want: auditSource{table: "audit", endpoint: endpoint},
+		},
+		{
+			name: "First readable sink",
+			cfg: appconfig.Audit{
+				LogTarget: logtarget.Stdout,
+				Sinks:     []*appconfig.AuditSink{{Target: logtarget.Stdout}, {Target: logtarget.Dynamodb}, {Target: logtarget.File}},
+				File:      file, DynamoTableName: "audit",
+			},
+			want: auditSource{table: "audit"},
+		},
+		{
+			name:    "Nothing to read back",
+			cfg:     appconfig.Audit{LogTarget: logtarget.Stdout},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var source auditSource
+			err := source.fromConfig(&tt.cfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, source)
+		})
+	}
+
+	t.Run("Flag endpoint wins", func(t *testing.T) {
+		source := auditSource{endpoint: "http://override"}
+		require.NoError(t, source.fromConfig(&appconfig.Audit{LogTarget: logtarget.Dynamodb, DynamoTableName: "audit", DynamoEndpoint: &endpoint}))
+		assert.Equal(t, "http://override", source.endpoint)
+	})
+}
@@ -2,48 +2,80 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
+	"github.com/apple/pkl-go/pkl"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	awsdynamodb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/davecgh/go-spew/spew"
+	"github.com/open-policy-agent/opa/v1/bundle"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
 
-	"github.com/asimihsan/planning_engine/internal/fact/config"
-	"github.com/asimihsan/planning_engine/internal/fact/mock_required"
+	gatev1 "github.com/asimihsan/planning_engine/api/gate/v1"
+	"github.com/asimihsan/planning_engine/internal/api/grpcapi"
+	"github.com/asimihsan/planning_engine/internal/api/httpapi"
+	"github.com/asimihsan/planning_engine/internal/audit/dynamodb"
+	"github.com/asimihsan/planning_engine/internal/audit/fanout"
+	auditfile "github.com/asimihsan/planning_engine/internal/audit/file"
+	"github.com/asimihsan/planning_engine/internal/audit/stdout"
+	appconfig "github.com/asimihsan/planning_engine/internal/config"
+	"github.com/asimihsan/planning_engine/internal/config/logtarget"
+	"github.com/asimihsan/planning_engine/internal/engine/opa"
+	factregistry "github.com/asimihsan/planning_engine/internal/fact/registry"
 	"github.com/asimihsan/planning_engine/internal/metrics"
+	"github.com/asimihsan/planning_engine/internal/policy/file"
+	"github.com/asimihsan/planning_engine/internal/policy/s3"
+	"github.com/asimihsan/planning_engine/internal/release"
 	"github.com/asimihsan/planning_engine/pkg/config/loader"
 	"github.com/asimihsan/planning_engine/pkg/gate"
 )
 
 func main() {
-	// Initialize context
-	ctx := context.Background()
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "audit":
+			os.Exit(runAudit(context.Background(), os.Args[2:], os.Stdout, os.Stderr))
+		case "replay":
+			os.Exit(runReplay(context.Background(), os.Args[2:], os.Stdout, os.Stderr))
+		}
+	}
+
+	configPath := flag.String("config", "policy/local/local.pkl", "path to the PKL configuration file")
+	policyURI := flag.String("policy", "", "policy bundle URI (file://<path> or s3://<bucket>/<manifest key>); overrides the configured policy.bundleURI")
+	policyQuery := flag.String("query", "data.gate.response", "Rego query producing the gate response")
+	flag.Parse()
+
+	// Cancel the root context on SIGINT/SIGTERM so servers can drain
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	// Register Prometheus metrics
 	metrics.MustRegister()
 
 	// Load configuration with enhanced loader
-	cfg, sha, err := loader.LoadFromPathWithSHA(ctx, "policy/local/local.pkl")
+	cfg, sha, err := loader.LoadFromPathWithSHA(ctx, *configPath)
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
 	fmt.Printf("Config SHA: %s\n", sha)
 
-	// Initialize registry with configuration
-	registry := gate.NewFactRegistry()
-
-	// Initialize and register fact providers
-	pendingDeltaProvider := &mock_required.PendingDeltaProvider{}
-
-	// Use the new ConfigProvider for max_pending_allowed
-	maxPendingProvider := config.NewMaxPendingAllowedProvider(cfg)
-
-	// Register the providers with the registry
-	registry.Register(pendingDeltaProvider)
-	registry.Register(maxPendingProvider)
+	// Initialize registry with the configured fact providers
+	registry := factregistry.New(cfg).WithObserver(metrics.Observer{})
 
 	// Print configuration details
 	fmt.Printf("Configuration loaded successfully:\n%s\n", spew.Sdump(cfg))
@@ -60,24 +92,214 @@ func main() {
 		}
 	}()
 
-	// Example usage of registry with options
-	fmt.Println("Testing registry snapshot with options...")
-	opts := gate.SnapshotOpts{
-		MaxAge:             cfg.FactProviders.MaxStaleness.GoDuration(),
-		PerProviderTimeout: cfg.FactProviders.ProviderTimeout.GoDuration(),
+	opts := factregistry.SnapshotOpts(cfg)
+
+	if *policyURI != "" {
+		cfg.Policy.BundleURI = *policyURI
 	}
+	fmt.Printf("Policy bundle URI: %s\n", cfg.Policy.BundleURI)
 
-	// In a real application, you would use this with actual providers
-	// For now, we'll just demonstrate that the configuration works
-	_, err = registry.SnapshotWithOpts(ctx, "test-deployment", "test-stage", opts)
+	auditLogger, err := newAuditLogger(ctx, cfg.Audit)
 	if err != nil {
-		fmt.Printf("Snapshot error (expected with no providers): %v\n", err)
+		log.Fatalf("Failed to create audit logger: %v", err)
+	}
+	fmt.Printf("Audit log target: %s\n", cfg.Audit.LogTarget)
+
+	verification, err := newVerificationConfig(cfg.Policy.Verification)
+	if err != nil {
+		log.Fatalf("Failed to configure policy bundle verification: %v", err)
+	}
+
+	var g *gate.Gate
+	store, err := newManifestStore(ctx, cfg.Policy)
+	switch {
+	case err != nil:
+		log.Fatalf("Failed to create policy store: %v", err)
+	case store != nil:
+		// Policy and config are released together through a manifest and hot-swapped as a pair
+		reloader := release.NewReloader(release.NewLoader(store, *policyQuery, decodeConfig).WithVerification(verification), cfg.Policy.RefreshInterval.GoDuration())
+		if _, err := reloader.Reload(ctx); err != nil {
+			log.Fatalf("Failed to load initial release: %v", err)
+		}
+		current, _ := reloader.CurrentRelease(ctx)
+		fmt.Printf("Serving release %s (config SHA: %s)\n", current.Manifest.Version, current.ConfigSHA)
+		go reloader.Run(ctx)
+
+		g = gate.NewReleaseGate(registry, opa.NewEngine(), reloader, auditLogger, opts)
+	default:
+		g = gate.NewGate(
+			registry,
+			opa.NewEngine(),
+			file.New(strings.TrimPrefix(cfg.Policy.BundleURI, "file://"), *policyQuery).
+				WithRefreshInterval(cfg.Policy.RefreshInterval.GoDuration()).
+				WithVerification(verification),
+			auditLogger,
+			opts,
+			sha,
+		)
 	}
+	g.WithObserver(metrics.Observer{})
+
+	// Serve GET /v1/audit from the first audit log target that can be read back
+	apiHandler := httpapi.New(g)
+	var auditSrc auditSource
+	if err := auditSrc.fromConfig(cfg.Audit); err != nil {
+		fmt.Printf("Audit queries disabled: %v\n", err)
+	} else {
+		reader, err := auditSrc.reader(ctx)
+		if err != nil {
+			log.Fatalf("Failed to create audit reader: %v", err)
+		}
+		apiHandler.WithAuditReader(reader)
+	}
+
+	// Start decision server
+	apiServer := &http.Server{
+		Addr:              net.JoinHostPort(cfg.Host, strconv.Itoa(int(cfg.Port))),
+		Handler:           apiHandler,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		fmt.Printf("Starting decision server on %s\n", apiServer.Addr)
+		if err := apiServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to start decision server: %v", err)
+		}
+	}()
+
+	// Start gRPC decision server
+	grpcAddr := net.JoinHostPort(cfg.Host, strconv.Itoa(int(cfg.GrpcPort)))
+	grpcListener, err := net.Listen("tcp", grpcAddr)
+	if err != nil {
+		log.Fatalf("Failed to listen on %s: %v", grpcAddr, err)
+	}
+	grpcServer := grpc.NewServer()
+	gatev1.RegisterGateServiceServer(grpcServer, grpcapi.New(g))
+	go func() {
+		fmt.Printf("Starting gRPC decision server on %s\n", grpcAddr)
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			log.Fatalf("Failed to start gRPC decision server: %v", err)
+		}
+	}()
 
 	fmt.Println("Application started successfully!")
 
-	// Keep running to serve metrics
-	for {
-		time.Sleep(time.Hour)
+	<-ctx.Done()
+	fmt.Println("Shutting down...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := apiServer.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Decision server shutdown: %v", err)
+	}
+	grpcServer.GracefulStop()
+
+	// Flush audit records only once no more decisions can be made
+	if closer, ok := auditLogger.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			log.Printf("Audit logger shutdown: %v", err)
+		}
+	}
+}
+
+// newManifestStore returns the release store for a manifest bundle URI: an s3:// URI, or a
+// file:// URI naming a .json manifest. It returns nil for a plain file:// Rego policy or bundle.
+func newManifestStore(ctx context.Context, cfg *appconfig.Policy) (release.Store, error) {
+	switch {
+	case strings.HasPrefix(cfg.BundleURI, "s3://"):
+		awsCfg, err := awsconfig.LoadDefaultConfig(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("loading AWS configuration: %w", err)
+		}
+		client := awss3.NewFromConfig(awsCfg, func(o *awss3.Options) {
+			if cfg.S3Endpoint != nil {
+				o.BaseEndpoint = cfg.S3Endpoint
+				o.UsePathStyle = true
+			}
+		})
+		return s3.NewStore(client, cfg.BundleURI)
+	case strings.HasPrefix(cfg.BundleURI, "file://") && strings.HasSuffix(cfg.BundleURI, ".json"):
+		return release.NewDirStore(strings.TrimPrefix(cfg.BundleURI, "file://")), nil
+	case strings.HasPrefix(cfg.BundleURI, "file://"):
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unsupported policy bundle URI: %s", cfg.BundleURI)
+	}
+}
+
+// newAuditLogger returns the audit logger selected by audit.logTarget, or a fan-out to
+// audit.sinks if any are configured.
+func newAuditLogger(ctx context.Context, cfg *appconfig.Audit) (gate.AuditLogger, error) {
+	if len(cfg.Sinks) == 0 {
+		return newAuditSink(ctx, cfg, cfg.LogTarget)
+	}
+
+	sinks := make([]fanout.Sink, 0, len(cfg.Sinks))
+	for _, sinkCfg := range cfg.Sinks {
+		sink, err := newAuditSink(ctx, cfg, sinkCfg.Target)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, fanout.Sink{
+			Name:      sinkCfg.Target.String(),
+			Logger:    sink,
+			QueueSize: sinkCfg.QueueSize,
+			Overflow:  fanout.Overflow(sinkCfg.Overflow),
+		})
 	}
+	return fanout.New(sinks...), nil
+}
+
+// newAuditSink returns the audit logger for a single log target.
+func newAuditSink(ctx context.Context, cfg *appconfig.Audit, target logtarget.LogTarget) (gate.AuditLogger, error) {
+	switch target {
+	case logtarget.Dynamodb:
+		awsCfg, err := awsconfig.LoadDefaultConfig(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("loading AWS configuration: %w", err)
+		}
+		client := awsdynamodb.NewFromConfig(awsCfg, func(o *awsdynamodb.Options) {
+			if cfg.DynamoEndpoint != nil {
+				o.BaseEndpoint = cfg.DynamoEndpoint
+			}
+		})
+		return dynamodb.New(client, cfg.DynamoTableName).WithRetention(cfg.Retention.GoDuration()), nil
+	case logtarget.File:
+		return auditfile.New(cfg.File.Path).
+			WithMaxSize(int64(cfg.File.MaxSize.ToUnit(pkl.Bytes).Value)).
+			WithMaxAge(cfg.File.MaxAge.GoDuration()).
+			WithMaxBackups(cfg.File.MaxBackups).
+			WithCompression(cfg.File.Compress).
+			WithSyncInterval(cfg.File.SyncInterval.GoDuration()), nil
+	case logtarget.Stdout:
+		return stdout.New(), nil
+	default:
+		return nil, fmt.Errorf("unsupported audit log target: %s", target)
+	}
+}
+
+// newVerificationConfig returns the policy bundle verification config, or nil if bundles
+// are not required to be signed.
+func newVerificationConfig(cfg *appconfig.BundleVerification) (*bundle.VerificationConfig, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	keys := make(map[string]opa.VerificationKey, len(cfg.Keys))
+	for id, k := range cfg.Keys {
+		keys[id] = opa.VerificationKey{Algorithm: k.Algorithm, PublicKey: k.PublicKey}
+	}
+
+	var keyID, scope string
+	if cfg.KeyID != nil {
+		keyID = *cfg.KeyID
+	}
+	if cfg.Scope != nil {
+		scope = *cfg.Scope
+	}
+	return opa.NewVerificationConfig(keys, keyID, scope)
+}
+
+// decodeConfig decodes release configuration for config-backed fact providers.
+func decodeConfig(ctx context.Context, data []byte) (any, error) {
+	return loader.LoadFromBytes(ctx, data)
 }
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/asimihsan/planning_engine/internal/engine/opa"
+	"github.com/asimihsan/planning_engine/internal/policy/file"
+	"github.com/asimihsan/planning_engine/internal/policy/s3"
+	"github.com/asimihsan/planning_engine/internal/replay"
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+// runReplay re-evaluates audited decisions against a candidate policy and prints which
+// decisions it would have changed.
+func runReplay(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var source auditSource
+	source.register(fs)
+	var filter auditFilter
+	filter.register(fs)
+	policyURI := fs.String("policy", "", "candidate policy URI (file://<path> or s3://<bucket>/<manifest key>)")
+	policyQuery := fs.String("query", "data.gate.response", "Rego query producing the gate response")
+	s3Endpoint := fs.String("s3-endpoint", "", "S3 endpoint override for an s3:// policy, e.g. for LocalStack")
+	format := fs.String("format", "table", "output format: table or json")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if *policyURI == "" {
+		fmt.Fprintln(stderr, "-policy is required")
+		return exitError
+	}
+	if *format != "table" && *format != "json" {
+		fmt.Fprintf(stderr, "unknown format %q\n", *format)
+		return exitError
+	}
+	q, err := filter.query(time.Now())
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	policies, err := newPolicyProvider(ctx, *policyURI, *policyQuery, *s3Endpoint)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	candidate, err := policies.GetPolicyBundle(ctx)
+	if err != nil {
+		fmt.Fprintf(stderr, "loading candidate policy: %v\n", err)
+		return exitError
+	}
+	records, err := source.query(ctx, q)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	report, err := replay.Replay(ctx, opa.NewEngine(), candidate, records)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitError
+		}
+		return exitOK
+	}
+	printReport(stdout, report)
+	return exitOK
+}
+
+// newPolicyProvider returns the policy provider for a candidate policy URI. A URI without a
+// scheme is a file path.
+func newPolicyProvider(ctx context.Context, uri, query, s3Endpoint string) (gate.PolicyProvider, error) {
+	if !strings.HasPrefix(uri, "s3://") {
+		return file.New(strings.TrimPrefix(uri, "file://"), query), nil
+	}
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("loading AWS configuration: %w", err)
+	}
+	client := awss3.NewFromConfig(awsCfg, func(o *awss3.Options) {
+		if s3Endpoint != "" {
+			o.BaseEndpoint = &s3Endpoint
+			o.UsePathStyle = true
+		}
+	})
+	return s3.New(client, uri, query, 0)
+}
+
+func printReport(w io.Writer, report replay.Report) {
+	t := report.Total
+	fmt.Fprintf(w, "Candidate policy: %s\n", report.PolicySHA)
+	fmt.Fprintf(w, "Replayed %d decisions: %d unchanged, %d allow→deny, %d deny→allow, %d with changed reasons, %d failed\n",
+		t.Replayed, t.Unchanged, t.AllowToDeny, t.DenyToAllow, t.ReasonsChanged, t.Failed)
+	if t.Skipped > 0 {
+		fmt.Fprintf(w, "Skipped %d error records, which have no decision to compare\n", t.Skipped)
+	}
+	if t.MissingFacts > 0 {
+		fmt.Fprintf(w, "Warning: %d decisions were replayed without facts the candidate reads but the audited policy did not\n", t.MissingFacts)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "\nDEPLOYMENT\tREPLAYED\tUNCHANGED\tALLOW→DENY\tDENY→ALLOW\tREASONS CHANGED\tFAILED\tSKIPPED")
+	for _, d := range report.Deployments {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\n",
+			d.DeploymentID, d.Replayed, d.Unchanged, d.AllowToDeny, d.DenyToAllow, d.ReasonsChanged, d.Failed, d.Skipped)
+	}
+	_ = tw.Flush()
+
+	for _, section := range []struct {
+		title   string
+		reasons []gate.ReasonCount
+	}{
+		{"REASONS ADDED", report.ReasonsAdded},
+		{"REASONS REMOVED", report.ReasonsRemoved},
+	} {
+		if len(section.reasons) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s\n", section.title)
+		for _, r := range section.reasons {
+			fmt.Fprintf(w, "%6d  %s\n", r.Count, r.Reason)
+		}
+	}
+
+	if len(report.Changes) == 0 {
+		return
+	}
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "\nDECISION ID\tDEPLOYMENT\tSTAGE\tCHANGE\tBEFORE\tAFTER")
+	for _, c := range report.Changes {
+		after := strings.Join(c.NewDenyReasons, "; ")
+		if c.Error != "" {
+			after = c.Error
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			c.DecisionID, c.DeploymentID, c.Stage, c.Kind, strings.Join(c.DenyReasons, "; "), after)
+	}
+	_ = tw.Flush()
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/asimihsan/planning_engine/internal/replay"
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+// frozenCanary denies every canary decision, and otherwise keeps the pending limit of main.rego.
+const frozenCanary = `package gate
+
+default allow := false
+
+deny_reasons contains "canary frozen" if input.request.stage == "canary"
+deny_reasons contains "pending_delta exceeds allowed limit" if {
+    input.request.stage != "canary"
+    input.facts.pending_delta + input.request.requested_count > input.facts.max_pending_allowed
+}
+
+allow if count(deny_reasons) == 0
+
+response := {"allow": allow, "deny_reasons": deny_reasons}
+`
+
+// serveS3 serves objects, keyed by "bucket/key", as a path-style S3 endpoint.
+func serveS3(t *testing.T, objects map[string]string) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := objects[strings.TrimPrefix(r.URL.Path, "/")]
+		if !ok {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`<Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`))
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestRunReplay(t *testing.T) {
+	path := writeAuditFile(t, auditRecords())
+	table := writeAuditTable(t, auditRecords()) // Also sets up AWS credentials for S3
+	candidate := filepath.Join(t.TempDir(), "candidate.rego")
+	require.NoError(t, os.WriteFile(candidate, []byte(frozenCanary), 0o600))
+
+	config := `maxPendingAllowed = 500`
+	s3Endpoint := serveS3(t, map[string]string{
+		"policies/candidate/manifest.json": fmt.Sprintf(`{"version": "candidate", "policy": "candidate.rego", "policy_digest": %q, "config": "config.pkl", "config_digest": %q}`,
+			gate.Digest([]byte(frozenCanary)), gate.Digest([]byte(config))),
+		"policies/candidate/candidate.rego": frozenCanary,
+		"policies/candidate/config.pkl":     config,
+	})
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		want     []string // Substrings of stdout, or of stderr for failures
+	}{
+		{
+			name:     "File candidate",
+			args:     []string{"-file=" + path, "-policy=file://" + candidate},
+			wantCode: exitOK,
+			want: []string{
+				"Candidate policy: ",
+				"Replayed 2 decisions: 0 unchanged, 1 allow→deny, 0 deny→allow, 1 with changed reasons, 0 failed",
+				"Skipped 1 error records",
+				"REASONS ADDED", "canary frozen",
+				"REASONS REMOVED", "pending_delta exceeds allowed limit",
+				"d-allow", "d-deny",
+			},
+		},
+		{
+			name:     "Candidate path without a scheme",
+			args:     append([]string{"-policy=" + candidate}, table...),
+			wantCode: exitOK,
+			want:     []string{"Replayed 2 decisions: 0 unchanged, 1 allow→deny"},
+		},
+		{
+			name:     "Filtered records",
+			args:     []string{"-file=" + path, "-policy=" + candidate, "-stage=prod"},
+			wantCode: exitOK,
+			want:     []string{"Replayed 0 decisions", "Skipped 1 error records"},
+		},
+		{name: "No candidate", args: []string{"-file=" + path}, wantCode: exitError, want: []string{"-policy is required"}},
+		{name: "Missing candidate", args: []string{"-file=" + path, "-policy=missing.rego"}, wantCode: exitError, want: []string{"loading candidate policy"}},
+		{name: "Missing S3 candidate", args: []string{"-file=" + path, "-policy=s3://policies/missing.json", "-s3-endpoint=" + s3Endpoint}, wantCode: exitError, want: []string{"loading candidate policy"}},
+		{name: "Unknown format", args: []string{"-file=" + path, "-policy=" + candidate, "-format=yaml"}, wantCode: exitError, want: []string{`unknown format "yaml"`}},
+		{name: "Unknown outcome", args: []string{"-file=" + path, "-policy=" + candidate, "-outcome=pause"}, wantCode: exitError, want: []string{`unknown outcome "pause"`}},
+		{name: "Unreadable audit log", args: []string{"-config=" + filepath.Join(t.TempDir(), "missing.pkl"), "-policy=" + candidate}, wantCode: exitError, want: []string{"loading configuration"}},
+		{name: "Unknown flag", args: []string{"-verbose"}, wantCode: exitError, want: []string{"-verbose"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, stdout, stderr := runCLI(runReplay, tt.args...)
+			assert.Equal(t, tt.wantCode, code, stderr)
+			out := stdout
+			if tt.wantCode != exitOK {
+				out = stderr
+			}
+			for _, want := range tt.want {
+				assert.Contains(t, out, want)
+			}
+		})
+	}
+
+	t.Run("S3 candidate as JSON", func(t *testing.T) {
+		code, stdout, stderr := runCLI(runReplay, "-file="+path, "-policy=s3://policies/candidate/manifest.json", "-s3-endpoint="+s3Endpoint, "-format=json")
+		require.Equal(t, exitOK, code, stderr)
+
+		var report replay.Report
+		require.NoError(t, json.Unmarshal([]byte(stdout), &report))
+		assert.NotEmpty(t, report.PolicySHA)
+		assert.Equal(t, replay.Counts{Replayed: 2, AllowToDeny: 1, ReasonsChanged: 1, Skipped: 1}, report.Total)
+		assert.Equal(t, []gate.ReasonCount{{Reason: "canary frozen", Count: 2}}, report.ReasonsAdded)
+		require.Len(t, report.Changes, 2)
+		assert.Equal(t, "d-allow", report.Changes[0].DecisionID)
+		assert.Equal(t, replay.AllowToDeny, report.Changes[0].Kind)
+	})
+}
@@ -0,0 +1,133 @@
+// Package grpcapi exposes gate decisions over gRPC.
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	gatev1 "github.com/asimihsan/planning_engine/api/gate/v1"
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+// MaxBatchSize bounds the number of requests accepted by BatchDecide.
+const MaxBatchSize = 100
+
+// Server implements gatev1.GateServiceServer on top of a gate.Gate.
+type Server struct {
+	gatev1.UnimplementedGateServiceServer
+
+	gate *gate.Gate
+}
+
+var _ gatev1.GateServiceServer = (*Server)(nil)
+
+// New creates a new gRPC decision server.
+func New(g *gate.Gate) *Server {
+	return &Server{gate: g}
+}
+
+// Decide implements gatev1.GateServiceServer.
+func (s *Server) Decide(ctx context.Context, req *gatev1.DecideRequest) (*gatev1.DecideResponse, error) {
+	decision, err := s.gate.Decide(ctx, toDecisionRequest(req))
+	if err != nil {
+		return nil, toStatus(err).Err()
+	}
+	return &gatev1.DecideResponse{Decision: toProtoDecision(decision)}, nil
+}
+
+// BatchDecide implements gatev1.GateServiceServer.
+func (s *Server) BatchDecide(ctx context.Context, req *gatev1.BatchDecideRequest) (*gatev1.BatchDecideResponse, error) {
+	if len(req.GetRequests()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "at least one request is required")
+	}
+	if len(req.GetRequests()) > MaxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "batch size %d exceeds limit of %d", len(req.GetRequests()), MaxBatchSize)
+	}
+
+	reqs := make([]gate.DecisionRequest, len(req.GetRequests()))
+	for i, r := range req.GetRequests() {
+		reqs[i] = toDecisionRequest(r)
+	}
+
+	results := s.gate.DecideBatch(ctx, reqs)
+
+	resp := &gatev1.BatchDecideResponse{Results: make([]*gatev1.BatchDecideResult, len(results))}
+	for i, r := range results {
+		if r.Err != nil {
+			st := toStatus(r.Err)
+			resp.Results[i] = &gatev1.BatchDecideResult{Result: &gatev1.BatchDecideResult_Failure{
+				Failure: &gatev1.Failure{Code: int32(st.Code()), Message: st.Message()},
+			}}
+			continue
+		}
+		resp.Results[i] = &gatev1.BatchDecideResult{Result: &gatev1.BatchDecideResult_Decision{
+			Decision: toProtoDecision(r.Decision),
+		}}
+	}
+	return resp, nil
+}
+
+// GetPolicyVersion implements gatev1.GateServiceServer.
+func (s *Server) GetPolicyVersion(ctx context.Context, _ *gatev1.GetPolicyVersionRequest) (*gatev1.GetPolicyVersionResponse, error) {
+	policySHA, configSHA, err := s.gate.PolicyVersion(ctx)
+	if err != nil {
+		return nil, toStatus(err).Err()
+	}
+	return &gatev1.GetPolicyVersionResponse{
+		PolicySha: policySHA,
+		ConfigSha: configSHA,
+	}, nil
+}
+
+func toDecisionRequest(req *gatev1.DecideRequest) gate.DecisionRequest {
+	return gate.DecisionRequest{
+		DeploymentID:   req.GetDeploymentId(),
+		Stage:          req.GetStage(),
+		RequestedCount: int(req.GetRequestedCount()),
+		Caller:         req.GetCaller(),
+	}
+}
+
+func toProtoDecision(decision gate.Decision) *gatev1.Decision {
+	return &gatev1.Decision{
+		Allow:        decision.Allow,
+		DenyReasons:  decision.DenyReasons,
+		PolicySha:    decision.PolicySHA,
+		ConfigSha:    decision.ConfigSHA,
+		EvalDuration: durationpb.New(decision.EvalDuration),
+	}
+}
+
+// toStatus maps the sentinel errors from pkg/gate onto gRPC status codes.
+// Errors that already carry a status are returned unchanged.
+func toStatus(err error) *status.Status {
+	if st, ok := status.FromError(err); ok {
+		return st
+	}
+
+	var code codes.Code
+	switch {
+	case errors.Is(err, gate.ErrInvalidRequest):
+		code = codes.InvalidArgument
+	case errors.Is(err, gate.ErrFactSourceUnavailable):
+		code = codes.Unavailable
+	case errors.Is(err, gate.ErrFactStale):
+		code = codes.FailedPrecondition
+	case errors.Is(err, gate.ErrPolicyLoad), errors.Is(err, gate.ErrConfigLoad):
+		code = codes.Unavailable
+	case errors.Is(err, gate.ErrPolicyEvaluation), errors.Is(err, gate.ErrAuditLog), errors.Is(err, gate.ErrFactInvalid):
+		code = codes.Internal
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	default:
+		code = codes.Internal
+	}
+	return status.New(code, fmt.Sprint(err))
+}
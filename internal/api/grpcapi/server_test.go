@@ -0,0 +1,189 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	gatev1 "github.com/asimihsan/planning_engine/api/gate/v1"
+	auditmock "github.com/asimihsan/planning_engine/internal/audit/mock"
+	"github.com/asimihsan/planning_engine/internal/engine/opa"
+	"github.com/asimihsan/planning_engine/internal/fact/mock"
+	"github.com/asimihsan/planning_engine/internal/policy/file"
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+// pendingDeltaByDeployment serves pending_delta per deployment, or an error for unknown ones.
+type pendingDeltaByDeployment map[string]any
+
+func (p pendingDeltaByDeployment) Describe() gate.Schema {
+	return gate.Schema{ID: "pending_delta", Description: "Number of devices newly targeted"}
+}
+
+func (p pendingDeltaByDeployment) Collect(_ context.Context, deploymentID, _ string) (gate.Fact, error) {
+	v, ok := p[deploymentID]
+	if !ok {
+		return nil, gate.ErrFactSourceUnavailable
+	}
+	return gate.NewFact("pending_delta", v, time.Now()), nil
+}
+
+// startServer serves a Server over an in-memory bufconn listener and returns a connected client.
+func startServer(t *testing.T, policyPath string, providers ...gate.FactProvider) (gatev1.GateServiceClient, *auditmock.Logger) {
+	t.Helper()
+
+	registry := gate.NewFactRegistry()
+	for _, p := range providers {
+		registry.Register(p)
+	}
+	logger := auditmock.NewLogger()
+	srv := New(gate.NewGate(
+		registry,
+		opa.NewEngine(),
+		file.New(policyPath, "data.gate.response"),
+		logger,
+		gate.SnapshotOpts{MaxAge: time.Minute},
+		"test-config-sha",
+	))
+
+	lis := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	gatev1.RegisterGateServiceServer(grpcServer, srv)
+	go func() {
+		_ = grpcServer.Serve(lis)
+	}()
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return gatev1.NewGateServiceClient(conn), logger
+}
+
+const policyPath = "../../../policy/rego/main.rego"
+
+func TestServer_Decide(t *testing.T) {
+	ctx := context.Background()
+	maxPending := mock.NewProvider("max_pending_allowed", 500, "Maximum allowed devices in pending state")
+
+	t.Run("Allow", func(t *testing.T) {
+		client, logger := startServer(t, policyPath, maxPending,
+			mock.NewProvider("pending_delta", 100, "Number of devices newly targeted"))
+
+		resp, err := client.Decide(ctx, &gatev1.DecideRequest{DeploymentId: "dep", Stage: "canary", RequestedCount: 10})
+		require.NoError(t, err)
+		assert.True(t, resp.GetDecision().GetAllow())
+		assert.NotEmpty(t, resp.GetDecision().GetPolicySha())
+		assert.Equal(t, "test-config-sha", resp.GetDecision().GetConfigSha())
+		assert.Len(t, logger.Decisions(), 1)
+	})
+
+	t.Run("Deny", func(t *testing.T) {
+		client, _ := startServer(t, policyPath, maxPending,
+			mock.NewProvider("pending_delta", 600, "Number of devices newly targeted"))
+
+		resp, err := client.Decide(ctx, &gatev1.DecideRequest{DeploymentId: "dep", Stage: "canary"})
+		require.NoError(t, err)
+		assert.False(t, resp.GetDecision().GetAllow())
+		assert.Equal(t, []string{"pending_delta exceeds allowed limit"}, resp.GetDecision().GetDenyReasons())
+	})
+
+	t.Run("Fact errors map to status codes", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			provider gate.FactProvider
+			wantCode codes.Code
+		}{
+			{
+				name:     "unavailable",
+				provider: mock.NewProvider("pending_delta", 0, "").WithError(gate.ErrFactSourceUnavailable),
+				wantCode: codes.Unavailable,
+			},
+			{
+				name:     "stale",
+				provider: mock.NewProviderWithTimestamp("pending_delta", 0, "", time.Now().Add(-time.Hour)),
+				wantCode: codes.FailedPrecondition,
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				client, logger := startServer(t, policyPath, maxPending, tt.provider)
+
+				_, err := client.Decide(ctx, &gatev1.DecideRequest{DeploymentId: "dep", Stage: "canary"})
+				assert.Equal(t, tt.wantCode, status.Code(err))
+				assert.Len(t, logger.Errors(), 1)
+				assert.Empty(t, logger.Decisions())
+			})
+		}
+	})
+
+	t.Run("Policy load failure", func(t *testing.T) {
+		client, logger := startServer(t, "does-not-exist.rego", maxPending)
+
+		_, err := client.Decide(ctx, &gatev1.DecideRequest{DeploymentId: "dep", Stage: "canary"})
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		require.Len(t, logger.Errors(), 1)
+		assert.Equal(t, gate.ErrorClass(gate.ErrPolicyLoad), logger.Errors()[0].ErrorClass)
+	})
+
+	t.Run("Invalid argument", func(t *testing.T) {
+		client, _ := startServer(t, policyPath, maxPending)
+
+		_, err := client.Decide(ctx, &gatev1.DecideRequest{Stage: "canary"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestServer_BatchDecide(t *testing.T) {
+	ctx := context.Background()
+	client, logger := startServer(t, policyPath,
+		mock.NewProvider("max_pending_allowed", 500, "Maximum allowed devices in pending state"),
+		pendingDeltaByDeployment{"small": 100, "large": 600},
+	)
+
+	resp, err := client.BatchDecide(ctx, &gatev1.BatchDecideRequest{Requests: []*gatev1.DecideRequest{
+		{DeploymentId: "small", Stage: "canary"},
+		{DeploymentId: "large", Stage: "canary"},
+		{DeploymentId: "unknown", Stage: "canary"},
+		{DeploymentId: "", Stage: "canary"},
+	}})
+	require.NoError(t, err)
+	require.Len(t, resp.GetResults(), 4)
+
+	assert.True(t, resp.GetResults()[0].GetDecision().GetAllow())
+	assert.False(t, resp.GetResults()[1].GetDecision().GetAllow())
+	assert.Equal(t, int32(codes.Unavailable), resp.GetResults()[2].GetFailure().GetCode())
+	assert.Equal(t, int32(codes.InvalidArgument), resp.GetResults()[3].GetFailure().GetCode())
+
+	assert.Len(t, logger.Decisions(), 2)
+	assert.Len(t, logger.Errors(), 1)
+
+	t.Run("Empty batch", func(t *testing.T) {
+		_, err := client.BatchDecide(ctx, &gatev1.BatchDecideRequest{})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestServer_GetPolicyVersion(t *testing.T) {
+	client, _ := startServer(t, policyPath)
+
+	resp, err := client.GetPolicyVersion(context.Background(), &gatev1.GetPolicyVersionRequest{})
+	require.NoError(t, err)
+	assert.Len(t, resp.GetPolicySha(), 64)
+	assert.Equal(t, "test-config-sha", resp.GetConfigSha())
+}
@@ -0,0 +1,194 @@
+// Package httpapi exposes gate decisions over HTTP.
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+// Outcome values reported in DecideResponse.
+const (
+	OutcomeAllow = "allow"
+	OutcomeDeny  = "deny"
+	OutcomePause = "pause" // no trustworthy decision could be made; callers must pause and alert
+	OutcomeError = "error"
+)
+
+// DecideRequest is the body accepted by POST /v1/decisions.
+type DecideRequest struct {
+	DeploymentID   string `json:"deployment_id"`
+	Stage          string `json:"stage"`
+	RequestedCount int    `json:"requested_count"`
+	Caller         string `json:"caller,omitempty"`
+}
+
+// DecideResponse is the body returned by POST /v1/decisions.
+// The decision fields are only present for allow and deny outcomes.
+type DecideResponse struct {
+	Outcome string `json:"outcome"`
+	*gate.Decision
+	Error string `json:"error,omitempty"`
+}
+
+// Bounds on GET /v1/audit, which may be served from a table holding every decision.
+const (
+	// DefaultAuditLimit bounds the records returned when no limit is given.
+	DefaultAuditLimit = 1000
+	// MaxAuditLimit is the largest limit accepted.
+	MaxAuditLimit = 1000
+	// MaxAuditScanWindow is the longest since window accepted for a query without a stage,
+	// which reads every stage of the deployment.
+	MaxAuditScanWindow = 24 * time.Hour
+)
+
+// AuditResponse is the body returned by GET /v1/audit. Reasons counts the deny reasons and
+// error classes of the returned records, most frequent first.
+type AuditResponse struct {
+	Records []gate.AuditRecord `json:"records"`
+	Reasons []gate.ReasonCount `json:"reasons"`
+	Error   string             `json:"error,omitempty"`
+}
+
+// Server serves decisions from a gate.Gate.
+type Server struct {
+	gate  *gate.Gate
+	audit gate.AuditReader
+	mux   *http.ServeMux
+	now   func() time.Time
+}
+
+// New creates a new HTTP decision server.
+func New(g *gate.Gate) *Server {
+	s := &Server{
+		gate: g,
+		mux:  http.NewServeMux(),
+		now:  time.Now,
+	}
+	s.mux.HandleFunc("POST /v1/decisions", s.handleDecide)
+	s.mux.HandleFunc("GET /v1/audit", s.handleAudit)
+	return s
+}
+
+// WithAuditReader serves GET /v1/audit from reader. Without one, the endpoint responds 501.
+func (s *Server) WithAuditReader(reader gate.AuditReader) *Server {
+	s.audit = reader
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleDecide(w http.ResponseWriter, r *http.Request) {
+	var req DecideRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, DecideResponse{Outcome: OutcomeError, Error: fmt.Sprintf("decoding request: %v", err)})
+		return
+	}
+
+	decision, err := s.gate.Decide(r.Context(), gate.DecisionRequest{
+		DeploymentID:   req.DeploymentID,
+		Stage:          req.Stage,
+		RequestedCount: req.RequestedCount,
+		Caller:         req.Caller,
+	})
+
+	var pauseErr *gate.PauseError
+	switch {
+	case errors.As(err, &pauseErr):
+		writeJSON(w, http.StatusServiceUnavailable, DecideResponse{Outcome: OutcomePause, Error: err.Error()})
+	case errors.Is(err, gate.ErrInvalidRequest):
+		writeJSON(w, http.StatusBadRequest, DecideResponse{Outcome: OutcomeError, Error: err.Error()})
+	case err != nil:
+		writeJSON(w, http.StatusInternalServerError, DecideResponse{Outcome: OutcomeError, Error: err.Error()})
+	case decision.Allow:
+		writeJSON(w, http.StatusOK, DecideResponse{Outcome: OutcomeAllow, Decision: &decision})
+	default:
+		writeJSON(w, http.StatusOK, DecideResponse{Outcome: OutcomeDeny, Decision: &decision})
+	}
+}
+
+// handleAudit returns the audit records matching the query parameters deployment_id, stage,
+// outcome, policy_sha, since, until and limit. since and until are RFC 3339 times or durations
+// before now, e.g. since=6h. deployment_id is required, and a query without a stage must set
+// since within MaxAuditScanWindow.
+func (s *Server) handleAudit(w http.ResponseWriter, r *http.Request) {
+	if s.audit == nil {
+		writeJSON(w, http.StatusNotImplemented, AuditResponse{Error: "the configured audit log cannot be queried"})
+		return
+	}
+	q, err := parseAuditQuery(r.URL.Query(), s.now())
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, AuditResponse{Error: err.Error()})
+		return
+	}
+
+	records, err := s.audit.Query(r.Context(), q)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, AuditResponse{Error: err.Error()})
+		return
+	}
+	if records == nil {
+		records = []gate.AuditRecord{}
+	}
+	writeJSON(w, http.StatusOK, AuditResponse{Records: records, Reasons: gate.CountReasons(records)})
+}
+
+func parseAuditQuery(values url.Values, now time.Time) (gate.AuditQuery, error) {
+	q := gate.AuditQuery{
+		DeploymentID: values.Get("deployment_id"),
+		Stage:        values.Get("stage"),
+		Outcome:      gate.Outcome(values.Get("outcome")),
+		PolicySHA:    values.Get("policy_sha"),
+		Limit:        DefaultAuditLimit,
+	}
+	switch q.Outcome {
+	case "", gate.OutcomeAllow, gate.OutcomeDeny, gate.OutcomeError:
+	default:
+		return q, fmt.Errorf("%w: unknown outcome %q", gate.ErrInvalidRequest, q.Outcome)
+	}
+
+	var err error
+	if since := values.Get("since"); since != "" {
+		if q.Since, err = gate.ParseAuditTime(since, now); err != nil {
+			return q, fmt.Errorf("since: %w", err)
+		}
+	}
+	if until := values.Get("until"); until != "" {
+		if q.Until, err = gate.ParseAuditTime(until, now); err != nil {
+			return q, fmt.Errorf("until: %w", err)
+		}
+	}
+	if limit := values.Get("limit"); limit != "" {
+		if q.Limit, err = strconv.Atoi(limit); err != nil || q.Limit <= 0 || q.Limit > MaxAuditLimit {
+			return q, fmt.Errorf("%w: limit must be an integer from 1 to %d", gate.ErrInvalidRequest, MaxAuditLimit)
+		}
+	}
+
+	// Bound what a query may read: a stage selects one partition, otherwise the window is capped.
+	// DynamoDB serves either from an index of the deployment, never a table scan.
+	if q.DeploymentID == "" {
+		return q, fmt.Errorf("%w: deployment_id is required", gate.ErrInvalidRequest)
+	}
+	if q.Stage == "" && (q.Since.IsZero() || now.Sub(q.Since) > MaxAuditScanWindow) {
+		return q, fmt.Errorf("%w: a query without a stage must set since within %s", gate.ErrInvalidRequest, MaxAuditScanWindow)
+	}
+	return q, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("httpapi: failed to encode response: %v", err)
+	}
+}
@@ -0,0 +1,245 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	auditmock "github.com/asimihsan/planning_engine/internal/audit/mock"
+	"github.com/asimihsan/planning_engine/internal/engine/opa"
+	"github.com/asimihsan/planning_engine/internal/fact/mock"
+	"github.com/asimihsan/planning_engine/internal/policy/file"
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+func newTestServer(t *testing.T, providers ...gate.FactProvider) (*Server, *auditmock.Logger) {
+	t.Helper()
+
+	registry := gate.NewFactRegistry()
+	for _, p := range providers {
+		registry.Register(p)
+	}
+	logger := auditmock.NewLogger()
+	srv := New(gate.NewGate(
+		registry,
+		opa.NewEngine(),
+		file.New("../../../policy/rego/main.rego", "data.gate.response"),
+		logger,
+		gate.SnapshotOpts{MaxAge: time.Minute},
+		"test-config-sha",
+	))
+	return srv, logger
+}
+
+func postDecision(t *testing.T, srv http.Handler, body any) (*httptest.ResponseRecorder, DecideResponse) {
+	t.Helper()
+
+	payload, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/decisions", bytes.NewReader(payload)))
+
+	var resp DecideResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	return rec, resp
+}
+
+func TestServer_Decide(t *testing.T) {
+	req := DecideRequest{DeploymentID: "test-deployment", Stage: "test-stage", RequestedCount: 10}
+
+	t.Run("Allow", func(t *testing.T) {
+		srv, logger := newTestServer(t,
+			mock.NewProvider("pending_delta", 100, "Number of devices newly targeted"),
+			mock.NewProvider("max_pending_allowed", 500, "Maximum allowed devices in pending state"),
+		)
+
+		rec, resp := postDecision(t, srv, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, OutcomeAllow, resp.Outcome)
+		require.NotNil(t, resp.Decision)
+		assert.True(t, resp.Allow)
+		assert.NotEmpty(t, resp.PolicySHA)
+		assert.Equal(t, "test-config-sha", resp.ConfigSHA)
+		assert.Len(t, logger.Decisions(), 1)
+	})
+
+	t.Run("Deny", func(t *testing.T) {
+		srv, logger := newTestServer(t,
+			mock.NewProvider("pending_delta", 600, "Number of devices newly targeted"),
+			mock.NewProvider("max_pending_allowed", 500, "Maximum allowed devices in pending state"),
+		)
+
+		rec, resp := postDecision(t, srv, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, OutcomeDeny, resp.Outcome)
+		require.NotNil(t, resp.Decision)
+		assert.False(t, resp.Allow)
+		assert.Equal(t, []string{"pending_delta exceeds allowed limit"}, resp.DenyReasons)
+		assert.Len(t, logger.Decisions(), 1)
+	})
+
+	t.Run("Stale fact pauses", func(t *testing.T) {
+		srv, logger := newTestServer(t,
+			mock.NewProviderWithTimestamp("pending_delta", 100, "Number of devices newly targeted", time.Now().Add(-time.Hour)),
+			mock.NewProvider("max_pending_allowed", 500, "Maximum allowed devices in pending state"),
+		)
+
+		rec, resp := postDecision(t, srv, req)
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Equal(t, OutcomePause, resp.Outcome)
+		assert.Nil(t, resp.Decision)
+		require.Len(t, logger.Errors(), 1)
+		assert.Equal(t, gate.ErrorClass(gate.ErrFactStale), logger.Errors()[0].ErrorClass)
+		assert.Empty(t, logger.Decisions())
+	})
+
+	t.Run("Unavailable fact pauses", func(t *testing.T) {
+		srv, logger := newTestServer(t,
+			mock.NewProvider("pending_delta", 100, "Number of devices newly targeted").WithError(gate.ErrFactSourceUnavailable),
+			mock.NewProvider("max_pending_allowed", 500, "Maximum allowed devices in pending state"),
+		)
+
+		rec, resp := postDecision(t, srv, req)
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Equal(t, OutcomePause, resp.Outcome)
+		require.Len(t, logger.Errors(), 1)
+		assert.Equal(t, gate.ErrorClass(gate.ErrFactSourceUnavailable), logger.Errors()[0].ErrorClass)
+	})
+
+	t.Run("Policy load failure pauses", func(t *testing.T) {
+		registry := gate.NewFactRegistry()
+		logger := auditmock.NewLogger()
+		srv := New(gate.NewGate(registry, opa.NewEngine(), file.New("missing.rego", "data.gate.response"),
+			logger, gate.SnapshotOpts{}, "test-config-sha"))
+
+		rec, resp := postDecision(t, srv, req)
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Equal(t, OutcomePause, resp.Outcome)
+		require.Len(t, logger.Errors(), 1)
+		assert.Equal(t, gate.ErrorClass(gate.ErrPolicyLoad), logger.Errors()[0].ErrorClass)
+	})
+
+	t.Run("Invalid request", func(t *testing.T) {
+		srv, logger := newTestServer(t)
+
+		rec, resp := postDecision(t, srv, DecideRequest{Stage: "test-stage"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, OutcomeError, resp.Outcome)
+		assert.Empty(t, logger.Decisions())
+		assert.Empty(t, logger.Errors())
+	})
+
+	t.Run("Wrong method", func(t *testing.T) {
+		srv, _ := newTestServer(t)
+
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/decisions", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	})
+}
+
+// stubReader returns fixed records and captures the query it was given.
+type stubReader struct {
+	records []gate.AuditRecord
+	query   gate.AuditQuery
+}
+
+func (r *stubReader) Query(_ context.Context, q gate.AuditQuery) ([]gate.AuditRecord, error) {
+	r.query = q
+	return r.records, nil
+}
+
+func getAudit(t *testing.T, srv http.Handler, target string) (*httptest.ResponseRecorder, AuditResponse) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+
+	var resp AuditResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	return rec, resp
+}
+
+func TestServer_Audit(t *testing.T) {
+	now := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Query", func(t *testing.T) {
+		reader := &stubReader{records: []gate.AuditRecord{
+			{DecisionID: "1", Outcome: gate.OutcomeDeny, DenyReasons: []string{"pending_delta exceeds allowed limit"}},
+			{DecisionID: "2", Outcome: gate.OutcomeDeny, DenyReasons: []string{"pending_delta exceeds allowed limit"}},
+		}}
+		srv, _ := newTestServer(t)
+		srv.WithAuditReader(reader).now = func() time.Time { return now }
+
+		rec, resp := getAudit(t, srv, "/v1/audit?deployment_id=dep&stage=canary&outcome=deny&policy_sha=sha&since=6h&until=2025-04-01T11:00:00Z&limit=10")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, gate.AuditQuery{
+			DeploymentID: "dep",
+			Stage:        "canary",
+			Outcome:      gate.OutcomeDeny,
+			PolicySHA:    "sha",
+			Since:        now.Add(-6 * time.Hour),
+			Until:        time.Date(2025, 4, 1, 11, 0, 0, 0, time.UTC),
+			Limit:        10,
+		}, reader.query)
+		assert.Len(t, resp.Records, 2)
+		assert.Equal(t, []gate.ReasonCount{{Reason: "pending_delta exceeds allowed limit", Count: 2}}, resp.Reasons)
+	})
+
+	t.Run("Default limit and no records", func(t *testing.T) {
+		reader := &stubReader{}
+		srv, _ := newTestServer(t)
+		srv.WithAuditReader(reader)
+
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/audit?deployment_id=dep&stage=canary", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"records": [], "reasons": []}`, rec.Body.String())
+		assert.Equal(t, DefaultAuditLimit, reader.query.Limit)
+	})
+
+	t.Run("Deployment query within the scan window", func(t *testing.T) {
+		reader := &stubReader{}
+		srv, _ := newTestServer(t)
+		srv.WithAuditReader(reader).now = func() time.Time { return now }
+
+		rec, _ := getAudit(t, srv, "/v1/audit?deployment_id=dep&since=6h")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, now.Add(-6*time.Hour), reader.query.Since)
+	})
+
+	t.Run("Invalid query", func(t *testing.T) {
+		srv, _ := newTestServer(t)
+		srv.WithAuditReader(&stubReader{})
+
+		for _, target := range []string{
+			"/v1/audit?deployment_id=dep&stage=canary&outcome=pause",
+			"/v1/audit?deployment_id=dep&stage=canary&since=yesterday",
+			"/v1/audit?deployment_id=dep&stage=canary&limit=0",
+			"/v1/audit?deployment_id=dep&stage=canary&limit=1001",
+			"/v1/audit?stage=canary",                 // Scans every deployment
+			"/v1/audit?deployment_id=dep",            // Scans every stage with no window
+			"/v1/audit?deployment_id=dep&since=168h", // Scan window too long
+		} {
+			rec, resp := getAudit(t, srv, target)
+			assert.Equal(t, http.StatusBadRequest, rec.Code, target)
+			assert.NotEmpty(t, resp.Error, target)
+		}
+	})
+
+	t.Run("No audit reader", func(t *testing.T) {
+		srv, _ := newTestServer(t)
+
+		rec, resp := getAudit(t, srv, "/v1/audit")
+		assert.Equal(t, http.StatusNotImplemented, rec.Code)
+		assert.NotEmpty(t, resp.Error)
+	})
+}
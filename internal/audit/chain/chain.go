@@ -0,0 +1,108 @@
+// Package chain makes audit logs tamper-evident by hash-chaining their records.
+//
+// Each record stores the SHA-256 of its canonical JSON in Hash, and the Hash of the record
+// before it in PrevHash. Editing, removing or reordering a stored record breaks the chain from
+// that point on, which Verifier reports. The Hash of the last record, the chain head, can be
+// pinned elsewhere to also detect records removed from the end.
+package chain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+// ErrBroken is wrapped by Verifier errors.
+var ErrBroken = errors.New("audit chain broken")
+
+// Link sets record's PrevHash to prev and its Hash over the result. The timestamp is
+// normalized to UTC first, so the record hashes the same however it is stored.
+func Link(record *gate.AuditRecord, prev string) error {
+	record.Timestamp = record.Timestamp.UTC()
+	record.PrevHash = prev
+	record.Hash = ""
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshaling audit record: %w", err)
+	}
+	hash, err := Hash(data)
+	if err != nil {
+		return err
+	}
+	record.Hash = hash
+	return nil
+}
+
+// Hash returns the hex SHA-256 of an encoded audit record's canonical JSON: the record's
+// fields other than "hash", with object keys sorted and numbers kept as written.
+func Hash(data []byte) (string, error) {
+	var fields map[string]any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&fields); err != nil {
+		return "", fmt.Errorf("decoding audit record: %w", err)
+	}
+	delete(fields, "hash")
+
+	canonical, err := json.Marshal(fields)
+	if err != nil {
+		return "", fmt.Errorf("encoding audit record: %w", err)
+	}
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Verifier checks records of one chain in order.
+type Verifier struct {
+	head  string
+	count int
+}
+
+// Add checks the next encoded record. The first record's PrevHash is taken on trust, since
+// the records before it may have been expired by retention.
+func (v *Verifier) Add(data []byte) error {
+	var record gate.AuditRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return fmt.Errorf("%w: record %d is not valid JSON: %v", ErrBroken, v.count+1, err)
+	}
+	if record.Hash == "" {
+		return fmt.Errorf("%w: record %d (%s) has no hash", ErrBroken, v.count+1, record.DecisionID)
+	}
+	if v.count > 0 && record.PrevHash != v.head {
+		return fmt.Errorf("%w: record %d (%s) does not follow the record before it; records were removed or reordered",
+			ErrBroken, v.count+1, record.DecisionID)
+	}
+	hash, err := Hash(data)
+	if err != nil {
+		return fmt.Errorf("%w: record %d (%s): %v", ErrBroken, v.count+1, record.DecisionID, err)
+	}
+	if hash != record.Hash {
+		return fmt.Errorf("%w: record %d (%s) does not match its hash; it was modified",
+			ErrBroken, v.count+1, record.DecisionID)
+	}
+
+	v.head = hash
+	v.count++
+	return nil
+}
+
+// AddRecord checks the next record.
+func (v *Verifier) AddRecord(record gate.AuditRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshaling audit record: %w", err)
+	}
+	return v.Add(data)
+}
+
+// Head returns the Hash of the last verified record, or "" if there is none.
+func (v *Verifier) Head() string { return v.head }
+
+// Count returns the number of records verified.
+func (v *Verifier) Count() int { return v.count }
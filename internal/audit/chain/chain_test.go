@@ -0,0 +1,100 @@
+package chain
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+func testRecord(id string) gate.AuditRecord {
+	return gate.AuditRecord{
+		DecisionID:   id,
+		Timestamp:    time.Date(2025, 4, 1, 12, 0, 0, 500, time.FixedZone("CEST", 2*60*60)),
+		DeploymentID: "dep",
+		Stage:        "canary",
+		Outcome:      gate.OutcomeAllow,
+		UsedFacts:    map[string]any{"pending_delta": 100, "error_rate": 0.01},
+	}
+}
+
+func TestLink(t *testing.T) {
+	first, second := testRecord("1"), testRecord("2")
+	require.NoError(t, Link(&first, ""))
+	require.NoError(t, Link(&second, first.Hash))
+
+	assert.Len(t, first.Hash, 64)
+	assert.Equal(t, time.UTC, first.Timestamp.Location())
+	assert.Equal(t, first.Hash, second.PrevHash)
+	assert.NotEqual(t, first.Hash, second.Hash)
+
+	// The hash does not depend on field order or number formatting
+	reordered := []byte(`{"hash":"ignored","used_facts":{"error_rate":0.01,"pending_delta":100},"deployment_id":"dep",` +
+		`"decision_id":"1","timestamp":"2025-04-01T10:00:00.0000005Z","stage":"canary","requested_count":0,` +
+		`"outcome":"allow","config_sha":"","snapshot_duration_ns":0,"eval_duration_ns":0}`)
+	hash, err := Hash(reordered)
+	require.NoError(t, err)
+	assert.Equal(t, first.Hash, hash)
+}
+
+func TestVerifier(t *testing.T) {
+	encode := func(records ...gate.AuditRecord) [][]byte {
+		prev := "anchor" // Records before the first may have expired
+		var lines [][]byte
+		for _, record := range records {
+			require.NoError(t, Link(&record, prev))
+			prev = record.Hash
+			line, err := json.Marshal(record)
+			require.NoError(t, err)
+			lines = append(lines, line)
+		}
+		return lines
+	}
+
+	t.Run("Intact", func(t *testing.T) {
+		var v Verifier
+		lines := encode(testRecord("1"), testRecord("2"), testRecord("3"))
+		for _, line := range lines {
+			require.NoError(t, v.Add(line))
+		}
+		assert.Equal(t, 3, v.Count())
+
+		var last gate.AuditRecord
+		require.NoError(t, json.Unmarshal(lines[2], &last))
+		assert.Equal(t, last.Hash, v.Head())
+	})
+
+	t.Run("Modified", func(t *testing.T) {
+		var v Verifier
+		lines := encode(testRecord("1"), testRecord("2"))
+		var record gate.AuditRecord
+		require.NoError(t, json.Unmarshal(lines[1], &record))
+		record.Outcome = gate.OutcomeDeny
+
+		require.NoError(t, v.Add(lines[0]))
+		err := v.AddRecord(record)
+		assert.ErrorIs(t, err, ErrBroken)
+		assert.Contains(t, err.Error(), "record 2 (2) does not match its hash")
+	})
+
+	t.Run("Removed", func(t *testing.T) {
+		var v Verifier
+		lines := encode(testRecord("1"), testRecord("2"), testRecord("3"))
+
+		require.NoError(t, v.Add(lines[0]))
+		err := v.Add(lines[2])
+		assert.ErrorIs(t, err, ErrBroken)
+		assert.Contains(t, err.Error(), "record 2 (3) does not follow")
+	})
+
+	t.Run("Unchained", func(t *testing.T) {
+		var v Verifier
+		line, err := json.Marshal(testRecord("1"))
+		require.NoError(t, err)
+		assert.ErrorIs(t, v.Add(line), ErrBroken)
+	})
+}
@@ -0,0 +1,271 @@
+// Package dynamodb persists audit records to a DynamoDB table.
+package dynamodb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/asimihsan/planning_engine/internal/audit/chain"
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+// Attribute names of an audit record. The table is keyed by AttrPartitionKey ("<deploymentID>#<stage>")
+// and AttrSortKey (an ISO8601 timestamp), so a rollout step's history can be queried in order.
+// The records of each partition form a hash chain, see Verify.
+const (
+	AttrPartitionKey       = "deploymentStage"
+	AttrSortKey            = "timestamp"
+	AttrDecisionID         = "decisionID"
+	AttrDeploymentID       = "deploymentID"
+	AttrStage              = "stage"
+	AttrRequestedCount     = "requestedCount"
+	AttrCaller             = "caller"
+	AttrOutcome            = "outcome" // A gate.Outcome
+	AttrPolicySHA          = "policySHA"
+	AttrPolicyKeyID        = "policyKeyID"
+	AttrConfigRev          = "configRev"
+	AttrUsedFactsJSON      = "usedFactsJSON"    // Only the facts the policy read
+	AttrMissingFactsJSON   = "missingFactsJSON" // Optional facts that could not be collected
+	AttrFactMetaJSON       = "factMetaJSON"     // How each used fact was collected
+	AttrReasonsJSON        = "reasonsJSON"
+	AttrErrorClass         = "errorClass"
+	AttrErrorDetails       = "errorDetails"
+	AttrSnapshotDurationNS = "snapshotDurationNS"
+	AttrEvalDurationNS     = "evalDurationNS"
+	AttrExpiresAt          = "expiresAt" // TTL attribute, in epoch seconds
+	AttrPrevHash           = "prevHash"
+	AttrHash               = "hash"
+)
+
+// DefaultRetention is how long records are kept unless overridden with WithRetention.
+const DefaultRetention = 90 * 24 * time.Hour
+
+// sortKeyLayout is ISO8601 with fixed-width nanoseconds, so that keys sort chronologically.
+const sortKeyLayout = "2006-01-02T15:04:05.000000000Z"
+
+// API is the subset of the DynamoDB client used by Logger.
+type API interface {
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error)
+}
+
+// Logger implements gate.AuditLogger by writing one item per decision or system error.
+//
+// Each record is hash-chained to the previous record of its deployment and stage. Writes to
+// one deployment and stage are serialized to keep its chain linear; several processes auditing the same deployment and
+// stage to one table fork its chain, which Verify reports. A record whose timestamp is already
+// taken in its partition is written one nanosecond later instead.
+type Logger struct {
+	client    API
+	table     string
+	retention time.Duration
+
+	mu    sync.Mutex             // Guards locks and heads
+	locks map[string]*sync.Mutex // Serializes writes per partition key
+	heads map[string]string      // Last hash written per partition key
+}
+
+var _ gate.AuditLogger = (*Logger)(nil)
+
+// New creates a new DynamoDB audit logger for table.
+func New(client API, table string) *Logger {
+	return &Logger{
+		client:    client,
+		table:     table,
+		retention: DefaultRetention,
+		locks:     map[string]*sync.Mutex{},
+		heads:     map[string]string{},
+	}
+}
+
+// WithRetention sets how long records are kept before the table's TTL expires them.
+// A zero retention writes no TTL attribute, so records are kept forever.
+func (l *Logger) WithRetention(retention time.Duration) *Logger {
+	l.retention = retention
+	return l
+}
+
+// Log implements gate.AuditLogger.
+func (l *Logger) Log(ctx context.Context, record gate.AuditRecord) error {
+	partition := record.DeploymentID + "#" + record.Stage
+
+	unlock := l.lock(partition)
+	defer unlock()
+
+	prev, err := l.head(ctx, partition)
+	if err != nil {
+		return err
+	}
+	err = l.write(ctx, &record, prev)
+	var conflict *types.ConditionalCheckFailedException
+	if errors.As(err, &conflict) {
+		// The partition already has a record with this timestamp, e.g. from a coarse clock:
+		// write this one a nanosecond later, so it still sorts after the record it follows
+		record.Timestamp = record.Timestamp.Add(time.Nanosecond)
+		err = l.write(ctx, &record, prev)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err != nil {
+		// The write may have landed anyway, e.g. if the request timed out: read the head
+		// again before the next record rather than fork the chain
+		delete(l.heads, partition)
+		return err
+	}
+	l.heads[partition] = record.Hash
+	return nil
+}
+
+// lock locks partition for writing and returns the function that unlocks it.
+func (l *Logger) lock(partition string) func() {
+	l.mu.Lock()
+	mu, ok := l.locks[partition]
+	if !ok {
+		mu = &sync.Mutex{}
+		l.locks[partition] = mu
+	}
+	l.mu.Unlock()
+
+	mu.Lock()
+	return mu.Unlock
+}
+
+// write links record to prev and puts it.
+func (l *Logger) write(ctx context.Context, record *gate.AuditRecord, prev string) error {
+	if err := chain.Link(record, prev); err != nil {
+		return err
+	}
+	item, err := recordItem(*record)
+	if err != nil {
+		return err
+	}
+	if l.retention > 0 {
+		item[AttrExpiresAt] = numberValue(record.Timestamp.Add(l.retention).Unix())
+	}
+	return l.put(ctx, item)
+}
+
+// head returns the hash of the last record in partition, reading it from the table the first time.
+func (l *Logger) head(ctx context.Context, partition string) (string, error) {
+	l.mu.Lock()
+	head, ok := l.heads[partition]
+	l.mu.Unlock()
+	if ok {
+		return head, nil
+	}
+
+	out, err := l.client.Query(ctx, &dynamodb.QueryInput{
+		TableName:                 aws.String(l.table),
+		KeyConditionExpression:    aws.String("#pk = :pk"),
+		ExpressionAttributeNames:  map[string]string{"#pk": AttrPartitionKey},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":pk": stringValue(partition)},
+		ScanIndexForward:          aws.Bool(false),
+		Limit:                     aws.Int32(1),
+		ConsistentRead:            aws.Bool(true),
+	})
+	if err != nil {
+		return "", fmt.Errorf("reading audit chain head from %s: %w", l.table, err)
+	}
+	if len(out.Items) > 0 {
+		head = stringAttr(out.Items[0], AttrHash)
+	}
+	l.mu.Lock()
+	l.heads[partition] = head
+	l.mu.Unlock()
+	return head, nil
+}
+
+// recordItem encodes record as an item, without the TTL attribute.
+func recordItem(record gate.AuditRecord) (map[string]types.AttributeValue, error) {
+	timestamp := record.Timestamp.UTC()
+	item := map[string]types.AttributeValue{
+		AttrPartitionKey:       stringValue(record.DeploymentID + "#" + record.Stage),
+		AttrSortKey:            stringValue(timestamp.Format(sortKeyLayout)),
+		AttrDecisionID:         stringValue(record.DecisionID),
+		AttrDeploymentID:       stringValue(record.DeploymentID),
+		AttrStage:              stringValue(record.Stage),
+		AttrRequestedCount:     numberValue(int64(record.RequestedCount)),
+		AttrOutcome:            stringValue(string(record.Outcome)),
+		AttrPolicySHA:          stringValue(record.PolicySHA),
+		AttrConfigRev:          stringValue(record.ConfigSHA),
+		AttrSnapshotDurationNS: numberValue(record.SnapshotDuration.Nanoseconds()),
+		AttrEvalDurationNS:     numberValue(record.EvalDuration.Nanoseconds()),
+	}
+	if record.Caller != "" {
+		item[AttrCaller] = stringValue(record.Caller)
+	}
+	if record.PolicyKeyID != "" {
+		item[AttrPolicyKeyID] = stringValue(record.PolicyKeyID)
+	}
+	if len(record.DenyReasons) > 0 {
+		reasonsJSON, err := json.Marshal(record.DenyReasons)
+		if err != nil {
+			return nil, fmt.Errorf("marshaling deny reasons: %w", err)
+		}
+		item[AttrReasonsJSON] = stringValue(string(reasonsJSON))
+	}
+	if len(record.FactMeta) > 0 {
+		metaJSON, err := json.Marshal(record.FactMeta)
+		if err != nil {
+			return nil, fmt.Errorf("marshaling fact metadata: %w", err)
+		}
+		item[AttrFactMetaJSON] = stringValue(string(metaJSON))
+	}
+	if len(record.MissingFacts) > 0 {
+		missingJSON, err := json.Marshal(record.MissingFacts)
+		if err != nil {
+			return nil, fmt.Errorf("marshaling missing facts: %w", err)
+		}
+		item[AttrMissingFactsJSON] = stringValue(string(missingJSON))
+	}
+	if record.Outcome == gate.OutcomeError {
+		item[AttrErrorClass] = stringValue(record.ErrorClass)
+		item[AttrErrorDetails] = stringValue(record.ErrorDetails)
+	} else {
+		usedFactsJSON, err := json.Marshal(record.UsedFacts)
+		if err != nil {
+			return nil, fmt.Errorf("marshaling used facts: %w", err)
+		}
+		item[AttrUsedFactsJSON] = stringValue(string(usedFactsJSON))
+	}
+	if record.PrevHash != "" {
+		item[AttrPrevHash] = stringValue(record.PrevHash)
+	}
+	if record.Hash != "" {
+		item[AttrHash] = stringValue(record.Hash)
+	}
+	return item, nil
+}
+
+// put writes an item, refusing to overwrite an existing record with the same key.
+// A refused write wraps a *types.ConditionalCheckFailedException.
+func (l *Logger) put(ctx context.Context, item map[string]types.AttributeValue) error {
+	_, err := l.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                aws.String(l.table),
+		Item:                     item,
+		ConditionExpression:      aws.String("attribute_not_exists(#sk)"),
+		ExpressionAttributeNames: map[string]string{"#sk": AttrSortKey},
+	})
+	if err != nil {
+		return fmt.Errorf("writing audit record to %s: %w", l.table, err)
+	}
+	return nil
+}
+
+func stringValue(s string) types.AttributeValue {
+	return &types.AttributeValueMemberS{Value: s}
+}
+
+func numberValue(n int64) types.AttributeValue {
+	return &types.AttributeValueMemberN{Value: strconv.FormatInt(n, 10)}
+}
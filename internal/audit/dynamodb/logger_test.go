@@ -0,0 +1,254 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/asimihsan/planning_engine/internal/audit/dynamodb_mock"
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+const testTable = "DeploymentGateAuditLog"
+
+func newTestClient(t *testing.T) (*dynamodb.Client, *dynamodb_mock.Server) {
+	t.Helper()
+	srv := dynamodb_mock.NewServer()
+	t.Cleanup(srv.Close)
+
+	client := dynamodb.New(dynamodb.Options{
+		BaseEndpoint: aws.String(srv.URL()),
+		Region:       "us-east-1",
+		Credentials:  credentials.NewStaticCredentialsProvider("test", "test", ""),
+	})
+	require.NoError(t, CreateTable(context.Background(), client, testTable))
+	return client, srv
+}
+
+func newTestLogger(t *testing.T) (*Logger, *dynamodb_mock.Server) {
+	t.Helper()
+	client, srv := newTestClient(t)
+	return New(client, testTable), srv
+}
+
+func testRecord(outcome gate.Outcome) gate.AuditRecord {
+	return gate.AuditRecord{
+		DecisionID:       "5f0c6f4e-3a1b-4c1d-9e55-1f6b0d7c2a10",
+		Timestamp:        time.Date(2025, 4, 1, 12, 0, 0, 500, time.UTC),
+		DeploymentID:     "dep",
+		Stage:            "canary",
+		RequestedCount:   10,
+		Outcome:          outcome,
+		PolicySHA:        "policy-sha",
+		ConfigSHA:        "config-sha",
+		SnapshotDuration: 2 * time.Millisecond,
+		EvalDuration:     1500 * time.Microsecond,
+	}
+}
+
+// lostResponseClient fails PutItem calls after writing their item, as a request that timed
+// out after the write landed does.
+type lostResponseClient struct {
+	API
+	lose bool
+}
+
+func (c *lostResponseClient) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	out, err := c.API.PutItem(ctx, params, optFns...)
+	if err == nil && c.lose {
+		return nil, errors.New("request timed out")
+	}
+	return out, err
+}
+
+// blockingClient holds PutItem calls for one partition until release is closed.
+type blockingClient struct {
+	API
+	partition string
+	blocked   chan struct{}
+	release   chan struct{}
+}
+
+func (c *blockingClient) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	if params.Item[AttrPartitionKey].(*types.AttributeValueMemberS).Value == c.partition {
+		close(c.blocked)
+		<-c.release
+	}
+	return c.API.PutItem(ctx, params, optFns...)
+}
+
+func TestLogger(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Allow decision", func(t *testing.T) {
+		logger, srv := newTestLogger(t)
+		record := testRecord(gate.OutcomeAllow)
+		record.Caller = "rollout-worker"
+		record.PolicyKeyID = "release-key"
+		record.UsedFacts = map[string]any{"pending_delta": 100}
+
+		require.NoError(t, logger.Log(ctx, record))
+
+		items := srv.Items(testTable)
+		require.Len(t, items, 1)
+		item := items[0]
+		assert.Equal(t, "dep#canary", item[AttrPartitionKey]["S"])
+		assert.Equal(t, "2025-04-01T12:00:00.000000500Z", item[AttrSortKey]["S"])
+		assert.Equal(t, record.DecisionID, item[AttrDecisionID]["S"])
+		assert.Equal(t, "dep", item[AttrDeploymentID]["S"])
+		assert.Equal(t, "canary", item[AttrStage]["S"])
+		assert.Equal(t, "10", item[AttrRequestedCount]["N"])
+		assert.Equal(t, "rollout-worker", item[AttrCaller]["S"])
+		assert.Equal(t, string(gate.OutcomeAllow), item[AttrOutcome]["S"])
+		assert.Equal(t, "policy-sha", item[AttrPolicySHA]["S"])
+		assert.Equal(t, "release-key", item[AttrPolicyKeyID]["S"])
+		assert.Equal(t, "config-sha", item[AttrConfigRev]["S"])
+		assert.JSONEq(t, `{"pending_delta": 100}`, item[AttrUsedFactsJSON]["S"])
+		assert.Equal(t, "2000000", item[AttrSnapshotDurationNS]["N"])
+		assert.Equal(t, "1500000", item[AttrEvalDurationNS]["N"])
+		assert.NotContains(t, item, AttrReasonsJSON)
+		assert.NotContains(t, item, AttrMissingFactsJSON)
+		assert.NotContains(t, item, AttrErrorClass)
+		assert.NotContains(t, item, AttrPrevHash)
+		assert.Len(t, item[AttrHash]["S"], 64)
+
+		expiresAt := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC).Add(DefaultRetention).Unix()
+		assert.Equal(t, strconv.FormatInt(expiresAt, 10), item[AttrExpiresAt]["N"])
+		assert.Equal(t, AttrExpiresAt, srv.TTLAttribute(testTable))
+	})
+
+	t.Run("Deny decision", func(t *testing.T) {
+		logger, srv := newTestLogger(t)
+		record := testRecord(gate.OutcomeDeny)
+		record.DenyReasons = []string{"pending_delta exceeds allowed limit"}
+		record.UsedFacts = map[string]any{"pending_delta": 100, "error_rate": 0.01}
+		record.MissingFacts = []string{"crash_rate"}
+		record.FactMeta = map[string]gate.FactMeta{"pending_delta": {Latency: time.Millisecond, CacheHit: true}}
+
+		require.NoError(t, logger.Log(ctx, record))
+
+		item := srv.Items(testTable)[0]
+		assert.Equal(t, string(gate.OutcomeDeny), item[AttrOutcome]["S"])
+		assert.JSONEq(t, `["crash_rate"]`, item[AttrMissingFactsJSON]["S"])
+		assert.JSONEq(t, `{"pending_delta": {"collected_at": "0001-01-01T00:00:00Z", "age_ns": 0, "latency_ns": 1000000, "cache_hit": true}}`, item[AttrFactMetaJSON]["S"])
+		assert.JSONEq(t, `["pending_delta exceeds allowed limit"]`, item[AttrReasonsJSON]["S"])
+		assert.JSONEq(t, `{"pending_delta": 100, "error_rate": 0.01}`, item[AttrUsedFactsJSON]["S"])
+		assert.NotContains(t, item, AttrPolicyKeyID)
+		assert.NotContains(t, item, AttrCaller)
+	})
+
+	t.Run("System error", func(t *testing.T) {
+		logger, srv := newTestLogger(t)
+		record := testRecord(gate.OutcomeError)
+		record.ErrorClass = gate.ErrorClass(gate.ErrFactStale)
+		record.ErrorDetails = gate.ErrFactStale.Error()
+
+		require.NoError(t, logger.Log(ctx, record))
+
+		item := srv.Items(testTable)[0]
+		assert.Equal(t, "dep#canary", item[AttrPartitionKey]["S"])
+		assert.Equal(t, string(gate.OutcomeError), item[AttrOutcome]["S"])
+		assert.Equal(t, "fact_stale", item[AttrErrorClass]["S"])
+		assert.Equal(t, gate.ErrFactStale.Error(), item[AttrErrorDetails]["S"])
+		assert.NotContains(t, item, AttrUsedFactsJSON)
+	})
+
+	t.Run("No retention", func(t *testing.T) {
+		logger, srv := newTestLogger(t)
+		logger.WithRetention(0)
+
+		require.NoError(t, logger.Log(ctx, testRecord(gate.OutcomeAllow)))
+		assert.NotContains(t, srv.Items(testTable)[0], AttrExpiresAt)
+	})
+
+	t.Run("Same timestamp as the previous record", func(t *testing.T) {
+		logger, srv := newTestLogger(t)
+
+		require.NoError(t, logger.Log(ctx, testRecord(gate.OutcomeAllow)))
+		// Same deployment, stage and timestamp, so the second record's key is taken
+		deny := testRecord(gate.OutcomeDeny)
+		deny.DecisionID = "9a8b7c6d-5e4f-4a3b-8c2d-1e0f9a8b7c6d"
+		require.NoError(t, logger.Log(ctx, deny))
+
+		items := srv.Items(testTable)
+		require.Len(t, items, 2)
+		assert.Equal(t, string(gate.OutcomeAllow), items[0][AttrOutcome]["S"])
+		assert.Equal(t, "2025-04-01T12:00:00.000000500Z", items[0][AttrSortKey]["S"])
+		assert.Equal(t, string(gate.OutcomeDeny), items[1][AttrOutcome]["S"])
+		assert.Equal(t, "2025-04-01T12:00:00.000000501Z", items[1][AttrSortKey]["S"])
+		assert.Equal(t, items[0][AttrHash]["S"], items[1][AttrPrevHash]["S"])
+		heads, err := Verify(ctx, logger.client.(ScanAPI), testTable)
+		require.NoError(t, err)
+		assert.Equal(t, []ChainHead{{DeploymentStage: "dep#canary", Head: items[1][AttrHash]["S"], Count: 2}}, heads)
+	})
+
+	t.Run("Never overwrite a record", func(t *testing.T) {
+		logger, srv := newTestLogger(t)
+		record := testRecord(gate.OutcomeAllow)
+		require.NoError(t, logger.Log(ctx, record))
+		record.Timestamp = record.Timestamp.Add(time.Nanosecond)
+		require.NoError(t, logger.Log(ctx, record))
+
+		// Both the timestamp and the one after it are taken
+		err := logger.Log(ctx, testRecord(gate.OutcomeDeny))
+		assert.Error(t, err)
+		require.Len(t, srv.Items(testTable), 2)
+		for _, item := range srv.Items(testTable) {
+			assert.Equal(t, string(gate.OutcomeAllow), item[AttrOutcome]["S"])
+		}
+	})
+
+	t.Run("Read the head again after a failed write", func(t *testing.T) {
+		client, srv := newTestClient(t)
+		lossy := &lostResponseClient{API: client, lose: true}
+		logger := New(lossy, testTable)
+
+		require.Error(t, logger.Log(ctx, testRecord(gate.OutcomeAllow)))
+		lossy.lose = false
+		next := testRecord(gate.OutcomeDeny)
+		next.Timestamp = next.Timestamp.Add(time.Second)
+		require.NoError(t, logger.Log(ctx, next))
+
+		// The first write landed, so the next record follows it
+		items := srv.Items(testTable)
+		require.Len(t, items, 2)
+		assert.Equal(t, items[0][AttrHash]["S"], items[1][AttrPrevHash]["S"])
+	})
+
+	t.Run("Partitions are written independently", func(t *testing.T) {
+		client, srv := newTestClient(t)
+		blocking := &blockingClient{API: client, partition: "dep#canary", blocked: make(chan struct{}), release: make(chan struct{})}
+		logger := New(blocking, testTable)
+
+		done := make(chan error)
+		go func() { done <- logger.Log(ctx, testRecord(gate.OutcomeAllow)) }()
+		<-blocking.blocked
+
+		// A write to another stage does not wait for the blocked one
+		other := testRecord(gate.OutcomeAllow)
+		other.Stage = "prod"
+		require.NoError(t, logger.Log(ctx, other))
+		assert.Len(t, srv.Items(testTable), 1)
+
+		close(blocking.release)
+		require.NoError(t, <-done)
+		assert.Len(t, srv.Items(testTable), 2)
+	})
+
+	t.Run("Missing table", func(t *testing.T) {
+		logger, _ := newTestLogger(t)
+		logger.table = "missing"
+
+		err := logger.Log(ctx, testRecord(gate.OutcomeAllow))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,148 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+// ReaderAPI is the subset of the DynamoDB client used by Reader.
+type ReaderAPI interface {
+	ScanAPI
+	Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error)
+}
+
+// Reader implements gate.AuditReader over an audit table written by Logger.
+type Reader struct {
+	client ReaderAPI
+	table  string
+}
+
+var _ gate.AuditReader = (*Reader)(nil)
+
+// NewReader creates a reader of the audit table.
+func NewReader(client ReaderAPI, table string) *Reader {
+	return &Reader{client: client, table: table}
+}
+
+// Query implements gate.AuditReader. A query naming both a deployment and a stage reads only
+// that partition between q.Since and q.Until, newest first, and stops once q.Limit records
+// match. A query naming only a deployment reads its records from DeploymentIndex the same way.
+// Any other query scans the whole table, keeping only the newest q.Limit matches.
+func (r *Reader) Query(ctx context.Context, q gate.AuditQuery) ([]gate.AuditRecord, error) {
+	var records []gate.AuditRecord
+	collect := func(items []map[string]types.AttributeValue) error {
+		for _, item := range items {
+			record, err := itemRecord(item)
+			if err != nil {
+				return fmt.Errorf("%s at %s: %w", stringAttr(item, AttrPartitionKey), stringAttr(item, AttrSortKey), err)
+			}
+			if q.Matches(record) {
+				records = append(records, record)
+			}
+		}
+		sortRecords(records)
+		records = gate.LimitRecords(records, q.Limit)
+		return nil
+	}
+
+	enough := func(items []map[string]types.AttributeValue) (bool, error) {
+		err := collect(items)
+		return q.Limit > 0 && len(records) >= q.Limit, err
+	}
+
+	var err error
+	switch {
+	case q.DeploymentID != "" && q.Stage != "":
+		err = r.query(ctx, q, "", AttrPartitionKey, q.DeploymentID+"#"+q.Stage, enough)
+	case q.DeploymentID != "":
+		err = r.query(ctx, q, DeploymentIndex, AttrDeploymentID, q.DeploymentID, enough)
+	default:
+		err = r.scan(ctx, collect)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// sortRecords orders records oldest first.
+func sortRecords(records []gate.AuditRecord) {
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Timestamp.Before(records[j].Timestamp)
+	})
+}
+
+// query reads the items whose key attribute is key, from the table or from index if set,
+// newest first, a page of at most q.Limit items at a time, until page reports it has enough.
+func (r *Reader) query(ctx context.Context, q gate.AuditQuery, index, keyAttr, key string, page func([]map[string]types.AttributeValue) (bool, error)) error {
+	condition := "#pk = :pk"
+	names := map[string]string{"#pk": keyAttr}
+	values := map[string]types.AttributeValue{":pk": stringValue(key)}
+
+	since, until := q.Since.UTC().Format(sortKeyLayout), q.Until.UTC().Format(sortKeyLayout)
+	switch {
+	case !q.Since.IsZero() && !q.Until.IsZero():
+		condition += " AND #sk BETWEEN :since AND :until"
+		values[":since"], values[":until"] = stringValue(since), stringValue(until)
+	case !q.Since.IsZero():
+		condition += " AND #sk >= :since"
+		values[":since"] = stringValue(since)
+	case !q.Until.IsZero():
+		condition += " AND #sk < :until"
+		values[":until"] = stringValue(until)
+	}
+	if !q.Since.IsZero() || !q.Until.IsZero() {
+		names["#sk"] = AttrSortKey
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String(r.table),
+		KeyConditionExpression:    aws.String(condition),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+		ScanIndexForward:          aws.Bool(false),
+	}
+	if index != "" {
+		input.IndexName = aws.String(index)
+	}
+	if q.Limit > 0 {
+		input.Limit = aws.Int32(int32(min(q.Limit, math.MaxInt32)))
+	}
+	for {
+		out, err := r.client.Query(ctx, input)
+		if err != nil {
+			return fmt.Errorf("querying audit table %s: %w", r.table, err)
+		}
+		done, err := page(out.Items)
+		if err != nil || done || len(out.LastEvaluatedKey) == 0 {
+			return err
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+}
+
+// scan reads the whole table, a page at a time.
+func (r *Reader) scan(ctx context.Context, page func([]map[string]types.AttributeValue) error) error {
+	var startKey map[string]types.AttributeValue
+	for {
+		out, err := r.client.Scan(ctx, &dynamodb.ScanInput{
+			TableName:         aws.String(r.table),
+			ExclusiveStartKey: startKey,
+		})
+		if err != nil {
+			return fmt.Errorf("scanning audit table %s: %w", r.table, err)
+		}
+		if err := page(out.Items); err != nil || len(out.LastEvaluatedKey) == 0 {
+			return err
+		}
+		startKey = out.LastEvaluatedKey
+	}
+}
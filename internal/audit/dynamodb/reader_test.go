@@ -0,0 +1,113 @@
+package dynamodb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+func TestReader_Query(t *testing.T) {
+	ctx := context.Background()
+	client, srv := newTestClient(t)
+	logger := New(client, testTable)
+
+	// Four canary records a minute apart, alternating deny and allow, and one prod error
+	start := testRecord(gate.OutcomeAllow).Timestamp
+	for i := 0; i < 4; i++ {
+		record := testRecord(gate.OutcomeAllow)
+		record.DecisionID = string(rune('a' + i))
+		record.Timestamp = start.Add(time.Duration(i) * time.Minute)
+		if i%2 == 0 {
+			record.Outcome = gate.OutcomeDeny
+			record.DenyReasons = []string{"pending_delta exceeds allowed limit"}
+		}
+		require.NoError(t, logger.Log(ctx, record))
+	}
+	prod := testRecord(gate.OutcomeError)
+	prod.DecisionID = "e"
+	prod.Stage = "prod"
+	prod.ErrorClass = "fact_stale"
+	prod.Timestamp = start.Add(90 * time.Second)
+	require.NoError(t, logger.Log(ctx, prod))
+
+	reader := NewReader(client, testTable)
+	ids := func(records []gate.AuditRecord) []string {
+		var ids []string
+		for _, r := range records {
+			ids = append(ids, r.DecisionID)
+		}
+		return ids
+	}
+
+	t.Run("One partition", func(t *testing.T) {
+		records, err := reader.Query(ctx, gate.AuditQuery{DeploymentID: "dep", Stage: "canary", Outcome: gate.OutcomeDeny})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "c"}, ids(records))
+		assert.Equal(t, []string{"pending_delta exceeds allowed limit"}, records[0].DenyReasons)
+
+		records, err = reader.Query(ctx, gate.AuditQuery{DeploymentID: "dep", Stage: "canary", Since: start.Add(time.Minute), Until: start.Add(3 * time.Minute)})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"b", "c"}, ids(records))
+
+		records, err = reader.Query(ctx, gate.AuditQuery{DeploymentID: "dep", Stage: "canary", Since: start.Add(2 * time.Minute)})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"c", "d"}, ids(records))
+
+		records, err = reader.Query(ctx, gate.AuditQuery{DeploymentID: "dep", Stage: "canary", Until: start.Add(time.Minute)})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a"}, ids(records))
+	})
+
+	t.Run("Limit stops reading the partition", func(t *testing.T) {
+		read := srv.ItemsRead("Query")
+		records, err := reader.Query(ctx, gate.AuditQuery{DeploymentID: "dep", Stage: "canary", Limit: 2})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"c", "d"}, ids(records))
+		assert.Equal(t, 2, srv.ItemsRead("Query")-read)
+
+		// Pages are read newest first until enough records match
+		read = srv.ItemsRead("Query")
+		records, err = reader.Query(ctx, gate.AuditQuery{DeploymentID: "dep", Stage: "canary", Outcome: gate.OutcomeDeny, Limit: 1})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"c"}, ids(records))
+		assert.Equal(t, 2, srv.ItemsRead("Query")-read)
+	})
+
+	t.Run("One deployment", func(t *testing.T) {
+		scanned := srv.ItemsRead("Scan")
+		records, err := reader.Query(ctx, gate.AuditQuery{DeploymentID: "dep"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b", "e", "c", "d"}, ids(records))
+
+		// The deployment index is read newest first, a page at a time, and never scanned
+		read := srv.ItemsRead("Query")
+		records, err = reader.Query(ctx, gate.AuditQuery{DeploymentID: "dep", Since: start.Add(time.Minute), Limit: 2})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"c", "d"}, ids(records))
+		assert.Equal(t, 2, srv.ItemsRead("Query")-read)
+
+		records, err = reader.Query(ctx, gate.AuditQuery{DeploymentID: "dep", Outcome: gate.OutcomeError, Limit: 1})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"e"}, ids(records))
+		assert.Equal(t, scanned, srv.ItemsRead("Scan"))
+
+		records, err = reader.Query(ctx, gate.AuditQuery{DeploymentID: "other"})
+		require.NoError(t, err)
+		assert.Empty(t, records)
+	})
+
+	t.Run("Whole table", func(t *testing.T) {
+		records, err := reader.Query(ctx, gate.AuditQuery{Outcome: gate.OutcomeError})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"e"}, ids(records))
+
+		records, err = reader.Query(ctx, gate.AuditQuery{Limit: 2})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"c", "d"}, ids(records))
+	})
+}
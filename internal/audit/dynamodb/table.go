@@ -0,0 +1,62 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// TableAPI is the subset of the DynamoDB client used by CreateTable.
+type TableAPI interface {
+	CreateTable(ctx context.Context, params *dynamodb.CreateTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.CreateTableOutput, error)
+	UpdateTimeToLive(ctx context.Context, params *dynamodb.UpdateTimeToLiveInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateTimeToLiveOutput, error)
+}
+
+// DeploymentIndex is a global secondary index of the audit table keyed by AttrDeploymentID and
+// AttrSortKey, so that Reader can query a deployment's records across its stages.
+const DeploymentIndex = "deploymentID-timestamp"
+
+// CreateTable creates an on-demand audit table with the Logger's key schema and DeploymentIndex,
+// and enables TTL on AttrExpiresAt. It is meant for DynamoDB Local and tests; production tables are
+// provisioned separately.
+func CreateTable(ctx context.Context, client TableAPI, table string) error {
+	_, err := client.CreateTable(ctx, &dynamodb.CreateTableInput{
+		TableName:   aws.String(table),
+		BillingMode: types.BillingModePayPerRequest,
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String(AttrPartitionKey), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String(AttrSortKey), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String(AttrDeploymentID), AttributeType: types.ScalarAttributeTypeS},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String(AttrPartitionKey), KeyType: types.KeyTypeHash},
+			{AttributeName: aws.String(AttrSortKey), KeyType: types.KeyTypeRange},
+		},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{{
+			IndexName: aws.String(DeploymentIndex),
+			KeySchema: []types.KeySchemaElement{
+				{AttributeName: aws.String(AttrDeploymentID), KeyType: types.KeyTypeHash},
+				{AttributeName: aws.String(AttrSortKey), KeyType: types.KeyTypeRange},
+			},
+			Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("creating audit table %s: %w", table, err)
+	}
+
+	_, err = client.UpdateTimeToLive(ctx, &dynamodb.UpdateTimeToLiveInput{
+		TableName: aws.String(table),
+		TimeToLiveSpecification: &types.TimeToLiveSpecification{
+			AttributeName: aws.String(AttrExpiresAt),
+			Enabled:       aws.Bool(true),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("enabling TTL on audit table %s: %w", table, err)
+	}
+	return nil
+}
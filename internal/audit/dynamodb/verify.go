@@ -0,0 +1,167 @@
+package dynamodb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/asimihsan/planning_engine/internal/audit/chain"
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+// ScanAPI is the subset of the DynamoDB client used by Verify.
+type ScanAPI interface {
+	Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
+}
+
+// ChainHead summarizes the verified hash chain of one deployment and stage.
+type ChainHead struct {
+	DeploymentStage string // The partition key, "<deploymentID>#<stage>"
+	Head            string // Hash of the newest record
+	Count           int    // Number of records verified
+}
+
+// Verify reads every record in table and walks the hash chain of each deployment and stage
+// in timestamp order. It returns the verified chains sorted by partition key. The first broken
+// link is reported as an error wrapping chain.ErrBroken, naming the partition.
+func Verify(ctx context.Context, client ScanAPI, table string) ([]ChainHead, error) {
+	partitions := map[string][]map[string]types.AttributeValue{}
+	var startKey map[string]types.AttributeValue
+	for {
+		out, err := client.Scan(ctx, &dynamodb.ScanInput{
+			TableName:         aws.String(table),
+			ExclusiveStartKey: startKey,
+			ConsistentRead:    aws.Bool(true),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("scanning audit table %s: %w", table, err)
+		}
+		for _, item := range out.Items {
+			pk := stringAttr(item, AttrPartitionKey)
+			partitions[pk] = append(partitions[pk], item)
+		}
+		if len(out.LastEvaluatedKey) == 0 {
+			break
+		}
+		startKey = out.LastEvaluatedKey
+	}
+
+	keys := make([]string, 0, len(partitions))
+	for pk := range partitions {
+		keys = append(keys, pk)
+	}
+	sort.Strings(keys)
+
+	heads := make([]ChainHead, 0, len(keys))
+	for _, pk := range keys {
+		items := partitions[pk]
+		sort.Slice(items, func(i, j int) bool {
+			return stringAttr(items[i], AttrSortKey) < stringAttr(items[j], AttrSortKey)
+		})
+
+		var v chain.Verifier
+		for _, item := range items {
+			record, err := itemRecord(item)
+			if err != nil {
+				return heads, fmt.Errorf("%s at %s: %w", pk, stringAttr(item, AttrSortKey), err)
+			}
+			if err := v.AddRecord(record); err != nil {
+				return heads, fmt.Errorf("%s at %s: %w", pk, stringAttr(item, AttrSortKey), err)
+			}
+		}
+		heads = append(heads, ChainHead{DeploymentStage: pk, Head: v.Head(), Count: v.Count()})
+	}
+	return heads, nil
+}
+
+// itemRecord decodes an item written by Logger back into the record it was written from.
+// Fact values keep their JSON number formatting, so the record hashes as it did when written.
+func itemRecord(item map[string]types.AttributeValue) (gate.AuditRecord, error) {
+	timestamp, err := time.Parse(sortKeyLayout, stringAttr(item, AttrSortKey))
+	if err != nil {
+		return gate.AuditRecord{}, fmt.Errorf("parsing %s: %w", AttrSortKey, err)
+	}
+	record := gate.AuditRecord{
+		DecisionID:   stringAttr(item, AttrDecisionID),
+		Timestamp:    timestamp,
+		DeploymentID: stringAttr(item, AttrDeploymentID),
+		Stage:        stringAttr(item, AttrStage),
+		Caller:       stringAttr(item, AttrCaller),
+		Outcome:      gate.Outcome(stringAttr(item, AttrOutcome)),
+		ErrorClass:   stringAttr(item, AttrErrorClass),
+		ErrorDetails: stringAttr(item, AttrErrorDetails),
+		PolicySHA:    stringAttr(item, AttrPolicySHA),
+		PolicyKeyID:  stringAttr(item, AttrPolicyKeyID),
+		ConfigSHA:    stringAttr(item, AttrConfigRev),
+		PrevHash:     stringAttr(item, AttrPrevHash),
+		Hash:         stringAttr(item, AttrHash),
+	}
+
+	requestedCount, err := numberAttr(item, AttrRequestedCount)
+	if err != nil {
+		return gate.AuditRecord{}, err
+	}
+	record.RequestedCount = int(requestedCount)
+	snapshotDuration, err := numberAttr(item, AttrSnapshotDurationNS)
+	if err != nil {
+		return gate.AuditRecord{}, err
+	}
+	record.SnapshotDuration = time.Duration(snapshotDuration)
+	evalDuration, err := numberAttr(item, AttrEvalDurationNS)
+	if err != nil {
+		return gate.AuditRecord{}, err
+	}
+	record.EvalDuration = time.Duration(evalDuration)
+
+	if reasons := stringAttr(item, AttrReasonsJSON); reasons != "" {
+		if err := json.Unmarshal([]byte(reasons), &record.DenyReasons); err != nil {
+			return gate.AuditRecord{}, fmt.Errorf("decoding %s: %w", AttrReasonsJSON, err)
+		}
+	}
+	if meta := stringAttr(item, AttrFactMetaJSON); meta != "" {
+		if err := json.Unmarshal([]byte(meta), &record.FactMeta); err != nil {
+			return gate.AuditRecord{}, fmt.Errorf("decoding %s: %w", AttrFactMetaJSON, err)
+		}
+	}
+	if missing := stringAttr(item, AttrMissingFactsJSON); missing != "" {
+		if err := json.Unmarshal([]byte(missing), &record.MissingFacts); err != nil {
+			return gate.AuditRecord{}, fmt.Errorf("decoding %s: %w", AttrMissingFactsJSON, err)
+		}
+	}
+	if facts := stringAttr(item, AttrUsedFactsJSON); facts != "" {
+		dec := json.NewDecoder(bytes.NewReader([]byte(facts)))
+		dec.UseNumber()
+		if err := dec.Decode(&record.UsedFacts); err != nil {
+			return gate.AuditRecord{}, fmt.Errorf("decoding %s: %w", AttrUsedFactsJSON, err)
+		}
+	}
+	return record, nil
+}
+
+func stringAttr(item map[string]types.AttributeValue, name string) string {
+	if v, ok := item[name].(*types.AttributeValueMemberS); ok {
+		return v.Value
+	}
+	return ""
+}
+
+// numberAttr returns a number attribute, or 0 if it is missing.
+func numberAttr(item map[string]types.AttributeValue, name string) (int64, error) {
+	v, ok := item[name].(*types.AttributeValueMemberN)
+	if !ok {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(v.Value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parsing %s: %w", name, err)
+	}
+	return n, nil
+}
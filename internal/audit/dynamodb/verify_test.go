@@ -0,0 +1,95 @@
+package dynamodb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/asimihsan/planning_engine/internal/audit/chain"
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+func TestVerify(t *testing.T) {
+	ctx := context.Background()
+
+	// writeChains logs three canary records, restarting the logger in between, and one prod record.
+	writeChains := func(t *testing.T) *dynamodb.Client {
+		client, _ := newTestClient(t)
+		logger := New(client, testTable)
+		for i := 0; i < 3; i++ {
+			if i == 2 {
+				// A new process picks up the chain from the table
+				logger = New(client, testTable)
+			}
+			record := testRecord(gate.OutcomeDeny)
+			record.Timestamp = record.Timestamp.Add(time.Duration(i) * time.Second)
+			record.DenyReasons = []string{"pending_delta exceeds allowed limit"}
+			record.UsedFacts = map[string]any{"pending_delta": 600, "error_rate": 0.01}
+			record.MissingFacts = []string{"crash_rate"}
+			record.FactMeta = map[string]gate.FactMeta{"pending_delta": {
+				CollectedAt: record.Timestamp.Add(-time.Second),
+				Age:         time.Second,
+				Latency:     1500 * time.Microsecond,
+				Source:      "http://levelsrv/metrics/pending_delta",
+			}}
+			require.NoError(t, logger.Log(ctx, record))
+		}
+
+		record := testRecord(gate.OutcomeError)
+		record.Stage = "prod"
+		record.Caller = "rollout-worker"
+		record.ErrorClass = "fact_stale"
+		record.ErrorDetails = gate.ErrFactStale.Error()
+		require.NoError(t, logger.Log(ctx, record))
+		return client
+	}
+
+	t.Run("Intact chains", func(t *testing.T) {
+		client := writeChains(t)
+
+		heads, err := Verify(ctx, client, testTable)
+		require.NoError(t, err)
+		require.Len(t, heads, 2)
+		assert.Equal(t, "dep#canary", heads[0].DeploymentStage)
+		assert.Equal(t, 3, heads[0].Count)
+		assert.Equal(t, "dep#prod", heads[1].DeploymentStage)
+		assert.Equal(t, 1, heads[1].Count)
+
+		// The head is the hash of the newest record
+		out, err := client.Query(ctx, &dynamodb.QueryInput{
+			TableName:                 aws.String(testTable),
+			KeyConditionExpression:    aws.String("#pk = :pk"),
+			ExpressionAttributeNames:  map[string]string{"#pk": AttrPartitionKey},
+			ExpressionAttributeValues: map[string]types.AttributeValue{":pk": stringValue("dep#canary")},
+		})
+		require.NoError(t, err)
+		require.Len(t, out.Items, 3)
+		assert.Equal(t, stringAttr(out.Items[2], AttrHash), heads[0].Head)
+		assert.Equal(t, stringAttr(out.Items[1], AttrHash), stringAttr(out.Items[2], AttrPrevHash))
+	})
+
+	t.Run("Modified record", func(t *testing.T) {
+		client := writeChains(t)
+		out, err := client.Query(ctx, &dynamodb.QueryInput{
+			TableName:                 aws.String(testTable),
+			KeyConditionExpression:    aws.String("#pk = :pk"),
+			ExpressionAttributeNames:  map[string]string{"#pk": AttrPartitionKey},
+			ExpressionAttributeValues: map[string]types.AttributeValue{":pk": stringValue("dep#canary")},
+		})
+		require.NoError(t, err)
+		item := out.Items[1]
+		item[AttrOutcome] = stringValue(string(gate.OutcomeAllow))
+		_, err = client.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String(testTable), Item: item})
+		require.NoError(t, err)
+
+		_, err = Verify(ctx, client, testTable)
+		assert.ErrorIs(t, err, chain.ErrBroken)
+		assert.Contains(t, err.Error(), "dep#canary at 2025-04-01T12:00:01.000000500Z")
+	})
+}
@@ -0,0 +1,299 @@
+// Package dynamodb_mock provides an in-process DynamoDB stand-in for tests that cannot reach
+// DynamoDB Local. It speaks the DynamoDB JSON protocol, so it is driven by the real AWS client.
+//
+// Only what the audit logger and reader need is supported: CreateTable, UpdateTimeToLive,
+// PutItem with an attribute_not_exists condition, Query of the table or a global secondary
+// index by partition key and optionally a sort key range, paginated by Limit, and an
+// unpaginated Scan.
+package dynamodb_mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Item is a DynamoDB item in wire format, e.g. {"outcome": {"S": "allow"}}.
+type Item map[string]map[string]string
+
+type table struct {
+	keys         keySchema
+	indexes      map[string]keySchema // Global secondary indexes by name
+	ttlAttribute string
+	items        []Item
+}
+
+type keySchema struct {
+	partitionKey, sortKey string
+}
+
+func newKeySchema(elements []keySchemaElement) keySchema {
+	var k keySchema
+	for _, e := range elements {
+		if e.KeyType == "HASH" {
+			k.partitionKey = e.AttributeName
+		} else {
+			k.sortKey = e.AttributeName
+		}
+	}
+	return k
+}
+
+// Server provides a mock DynamoDB endpoint for testing.
+type Server struct {
+	server *httptest.Server
+
+	mu     sync.Mutex
+	tables map[string]*table
+	items  map[string]int // Items returned per operation
+}
+
+// NewServer creates and starts a new mock DynamoDB endpoint.
+func NewServer() *Server {
+	s := &Server{tables: map[string]*table{}, items: map[string]int{}}
+	s.server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// URL returns the URL of the mock server.
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// Close shuts down the mock server.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Items returns a copy of the items in tableName, in insertion order.
+func (s *Server) Items(tableName string) []Item {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.tables[tableName]
+	if !ok {
+		return nil
+	}
+	return append([]Item(nil), t.items...)
+}
+
+// ItemsRead returns the number of items returned by op, e.g. "Query", so far.
+func (s *Server) ItemsRead(op string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.items[op]
+}
+
+// TTLAttribute returns the TTL attribute enabled on tableName, if any.
+func (s *Server) TTLAttribute(tableName string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t, ok := s.tables[tableName]; ok {
+		return t.ttlAttribute
+	}
+	return ""
+}
+
+type request struct {
+	TableName                 string             `json:"TableName"`
+	Item                      Item               `json:"Item"`
+	ConditionExpression       string             `json:"ConditionExpression"`
+	KeyConditionExpression    string             `json:"KeyConditionExpression"`
+	ExpressionAttributeNames  map[string]string  `json:"ExpressionAttributeNames"`
+	ExpressionAttributeValues Item               `json:"ExpressionAttributeValues"`
+	ScanIndexForward          *bool              `json:"ScanIndexForward"`
+	Limit                     int                `json:"Limit"`
+	ExclusiveStartKey         Item               `json:"ExclusiveStartKey"`
+	IndexName                 string             `json:"IndexName"`
+	KeySchema                 []keySchemaElement `json:"KeySchema"`
+	GlobalSecondaryIndexes    []struct {
+		IndexName string             `json:"IndexName"`
+		KeySchema []keySchemaElement `json:"KeySchema"`
+	} `json:"GlobalSecondaryIndexes"`
+	TimeToLiveSpecification struct {
+		AttributeName string `json:"AttributeName"`
+		Enabled       bool   `json:"Enabled"`
+	} `json:"TimeToLiveSpecification"`
+}
+
+type keySchemaElement struct {
+	AttributeName string `json:"AttributeName"`
+	KeyType       string `json:"KeyType"`
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	var req request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, "SerializationException", err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	op := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "DynamoDB_20120810.")
+	if op == "CreateTable" {
+		s.createTable(w, req)
+		return
+	}
+
+	t, ok := s.tables[req.TableName]
+	if !ok {
+		writeError(w, "ResourceNotFoundException", "Requested resource not found: Table: "+req.TableName+" not found")
+		return
+	}
+	switch op {
+	case "UpdateTimeToLive":
+		t.ttlAttribute = req.TimeToLiveSpecification.AttributeName
+		writeJSON(w, map[string]any{"TimeToLiveSpecification": req.TimeToLiveSpecification})
+	case "PutItem":
+		s.putItem(w, t, req)
+	case "Query":
+		s.query(w, t, req)
+	case "Scan":
+		items := append([]Item(nil), t.items...)
+		s.items[op] += len(items)
+		writeJSON(w, map[string]any{"Items": items, "Count": len(items), "ScannedCount": len(items)})
+	default:
+		writeError(w, "UnknownOperationException", "unsupported operation "+op)
+	}
+}
+
+func (s *Server) createTable(w http.ResponseWriter, req request) {
+	if _, ok := s.tables[req.TableName]; ok {
+		writeError(w, "ResourceInUseException", "Table already exists: "+req.TableName)
+		return
+	}
+	t := &table{keys: newKeySchema(req.KeySchema), indexes: map[string]keySchema{}}
+	for _, index := range req.GlobalSecondaryIndexes {
+		t.indexes[index.IndexName] = newKeySchema(index.KeySchema)
+	}
+	s.tables[req.TableName] = t
+	writeJSON(w, map[string]any{"TableDescription": map[string]any{"TableName": req.TableName, "TableStatus": "ACTIVE"}})
+}
+
+func (s *Server) putItem(w http.ResponseWriter, t *table, req request) {
+	for i, existing := range t.items {
+		if !t.keys.sameItem(existing, req.Item) {
+			continue
+		}
+		if strings.HasPrefix(req.ConditionExpression, "attribute_not_exists") {
+			writeError(w, "ConditionalCheckFailedException", "The conditional request failed")
+			return
+		}
+		t.items[i] = req.Item
+		writeJSON(w, map[string]any{})
+		return
+	}
+	t.items = append(t.items, req.Item)
+	writeJSON(w, map[string]any{})
+}
+
+// query supports a "<name> = <value>" condition on the partition key of the table or of
+// req.IndexName, optionally followed by "AND <name> BETWEEN <value> AND <value>",
+// "AND <name> >= <value>" or "AND <name> < <value>" on the sort key.
+func (s *Server) query(w http.ResponseWriter, t *table, req request) {
+	keys := t.keys
+	if req.IndexName != "" {
+		var ok bool
+		if keys, ok = t.indexes[req.IndexName]; !ok {
+			writeError(w, "ValidationException", "The table does not have the specified index: "+req.IndexName)
+			return
+		}
+	}
+	partition, sortCondition, _ := strings.Cut(req.KeyConditionExpression, " AND ")
+	name, value, ok := strings.Cut(partition, " = ")
+	if !ok || req.attributeName(name) != keys.partitionKey {
+		writeError(w, "ValidationException", fmt.Sprintf("query must have an equality condition on partition key %s", keys.partitionKey))
+		return
+	}
+	want := req.ExpressionAttributeValues[strings.TrimSpace(value)]["S"]
+
+	inRange := func(string) bool { return true }
+	if sortCondition != "" {
+		fields := strings.Fields(sortCondition)
+		if len(fields) < 3 || req.attributeName(fields[0]) != keys.sortKey {
+			writeError(w, "ValidationException", "unsupported sort key condition "+sortCondition)
+			return
+		}
+		bound := func(i int) string { return req.ExpressionAttributeValues[fields[i]]["S"] }
+		switch {
+		case fields[1] == "BETWEEN" && len(fields) == 5 && fields[3] == "AND":
+			inRange = func(k string) bool { return k >= bound(2) && k <= bound(4) }
+		case fields[1] == ">=" && len(fields) == 3:
+			inRange = func(k string) bool { return k >= bound(2) }
+		case fields[1] == "<" && len(fields) == 3:
+			inRange = func(k string) bool { return k < bound(2) }
+		default:
+			writeError(w, "ValidationException", "unsupported sort key condition "+sortCondition)
+			return
+		}
+	}
+
+	var items []Item
+	for _, item := range t.items {
+		if item[keys.partitionKey]["S"] == want && inRange(item[keys.sortKey]["S"]) {
+			items = append(items, item)
+		}
+	}
+	descending := req.ScanIndexForward != nil && !*req.ScanIndexForward
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i][keys.sortKey]["S"], items[j][keys.sortKey]["S"]
+		if descending {
+			return a > b
+		}
+		return a < b
+	})
+	if req.ExclusiveStartKey != nil {
+		for i, item := range items {
+			if t.keys.sameItem(item, req.ExclusiveStartKey) {
+				items = items[i+1:]
+				break
+			}
+		}
+	}
+	out := map[string]any{}
+	if req.Limit > 0 && len(items) > req.Limit {
+		items = items[:req.Limit]
+		last := items[len(items)-1]
+		// The table's key identifies the item; an index's key orders the next page
+		out["LastEvaluatedKey"] = Item{
+			t.keys.partitionKey: last[t.keys.partitionKey], t.keys.sortKey: last[t.keys.sortKey],
+			keys.partitionKey: last[keys.partitionKey], keys.sortKey: last[keys.sortKey],
+		}
+	}
+	s.items["Query"] += len(items)
+	out["Items"], out["Count"], out["ScannedCount"] = items, len(items), len(items)
+	writeJSON(w, out)
+}
+
+// sameItem reports whether a and b have the same primary key.
+func (k keySchema) sameItem(a, b Item) bool {
+	return a[k.partitionKey]["S"] == b[k.partitionKey]["S"] && a[k.sortKey]["S"] == b[k.sortKey]["S"]
+}
+
+// attributeName resolves an attribute name placeholder such as "#pk".
+func (r request) attributeName(name string) string {
+	name = strings.TrimSpace(name)
+	if alias, ok := r.ExpressionAttributeNames[name]; ok {
+		return alias
+	}
+	return name
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code, message string) {
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"__type":  "com.amazonaws.dynamodb.v20120810#" + code,
+		"message": message,
+	})
+}
@@ -0,0 +1,206 @@
+// Package fanout writes audit records to several sinks asynchronously, so that a slow sink
+// does not add to decision latency.
+package fanout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/asimihsan/planning_engine/internal/metrics"
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+var (
+	// ErrQueueFull is returned by Log when a sink with OverflowFail cannot take a record.
+	ErrQueueFull = errors.New("audit queue full")
+	// ErrClosed is returned by Log after Close.
+	ErrClosed = errors.New("audit logger closed")
+)
+
+// Overflow decides what Log does when a sink's queue is full, and whether it waits for the
+// sink to write the record.
+type Overflow string
+
+const (
+	// OverflowBlock waits for room in the queue, delaying the decision.
+	OverflowBlock Overflow = "block"
+	// OverflowDrop drops the record for this sink and counts it in metrics.AuditRecordsDropped.
+	OverflowDrop Overflow = "drop"
+	// OverflowFail makes the sink durable: Log waits until the sink has written the record and
+	// fails the decision, so that the caller pauses, if the write fails or, with ErrQueueFull,
+	// if the queue is full.
+	OverflowFail Overflow = "fail"
+)
+
+// DefaultQueueSize is used for sinks that do not set QueueSize.
+const DefaultQueueSize = 1000
+
+// Sink is one destination of a fan-out Logger.
+type Sink struct {
+	Name      string // Labels the sink's metrics and log messages
+	Logger    gate.AuditLogger
+	QueueSize int
+	Overflow  Overflow
+}
+
+// Logger implements gate.AuditLogger by queueing each record for every sink. Each sink is
+// written by its own goroutine in record order.
+//
+// Log returns once every sink has queued the record, and every OverflowFail sink has written
+// it. Errors the other sinks return when writing cannot fail the decision; they are logged and
+// counted in metrics.AuditSinkErrors.
+type Logger struct {
+	sinks []*sinkQueue
+
+	mu     sync.RWMutex // Held for reading while queueing, and for writing by Close
+	closed bool
+	wg     sync.WaitGroup
+}
+
+type sinkQueue struct {
+	Sink
+	queue chan queuedRecord
+}
+
+// queuedRecord carries the values of the Log call's context, but not its cancellation,
+// since the record may be written after Log returns.
+type queuedRecord struct {
+	ctx    context.Context
+	record gate.AuditRecord
+	done   chan error // Receives the write's result, for OverflowFail sinks
+}
+
+var _ gate.AuditLogger = (*Logger)(nil)
+
+// New creates a fan-out logger and starts writing to sinks.
+func New(sinks ...Sink) *Logger {
+	l := &Logger{}
+	for _, s := range sinks {
+		if s.QueueSize <= 0 {
+			s.QueueSize = DefaultQueueSize
+		}
+		if s.Overflow == "" {
+			s.Overflow = OverflowBlock
+		}
+		q := &sinkQueue{Sink: s, queue: make(chan queuedRecord, s.QueueSize)}
+		l.sinks = append(l.sinks, q)
+
+		l.wg.Add(1)
+		go l.run(q)
+	}
+	return l
+}
+
+// Log implements gate.AuditLogger.
+func (l *Logger) Log(ctx context.Context, record gate.AuditRecord) error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if l.closed {
+		return ErrClosed
+	}
+
+	var (
+		errs    []error
+		pending = make(map[*sinkQueue]chan error)
+	)
+	for _, s := range l.sinks {
+		queued := queuedRecord{ctx: context.WithoutCancel(ctx), record: record}
+		if s.Overflow == OverflowFail {
+			queued.done = make(chan error, 1)
+		}
+		if err := s.enqueue(ctx, queued); err != nil {
+			errs = append(errs, fmt.Errorf("audit sink %s: %w", s.Name, err))
+		} else if queued.done != nil {
+			pending[s] = queued.done
+		}
+	}
+
+	// Durable sinks must have written the record before the decision is acted on
+	for s, done := range pending {
+		select {
+		case err := <-done:
+			if err != nil {
+				errs = append(errs, fmt.Errorf("audit sink %s: %w", s.Name, err))
+			}
+		case <-ctx.Done():
+			errs = append(errs, fmt.Errorf("audit sink %s: %w", s.Name, ctx.Err()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *sinkQueue) enqueue(ctx context.Context, queued queuedRecord) error {
+	select {
+	case s.queue <- queued:
+		metrics.AuditQueueDepth.WithLabelValues(s.Name).Inc()
+		return nil
+	default:
+	}
+
+	switch s.Overflow {
+	case OverflowDrop:
+		metrics.AuditRecordsDropped.WithLabelValues(s.Name).Inc()
+		return nil
+	case OverflowFail:
+		metrics.AuditSinkErrors.WithLabelValues(s.Name, "queue_full").Inc()
+		return ErrQueueFull
+	default:
+		select {
+		case s.queue <- queued:
+			metrics.AuditQueueDepth.WithLabelValues(s.Name).Inc()
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (l *Logger) run(s *sinkQueue) {
+	defer l.wg.Done()
+	for queued := range s.queue {
+		metrics.AuditQueueDepth.WithLabelValues(s.Name).Dec()
+		timer := prometheus.NewTimer(metrics.AuditWriteLatency.WithLabelValues(s.Name))
+		err := s.Logger.Log(queued.ctx, queued.record)
+		timer.ObserveDuration()
+		if queued.done != nil {
+			queued.done <- err
+		}
+		if err != nil {
+			metrics.AuditSinkErrors.WithLabelValues(s.Name, "write").Inc()
+			log.Printf("audit: sink %s failed to write decision %s: %v", s.Name, queued.record.DecisionID, err)
+		}
+	}
+}
+
+// Close stops accepting records, waits until every queued record has been written, then
+// closes the sinks that implement io.Closer.
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil
+	}
+	l.closed = true
+	for _, s := range l.sinks {
+		close(s.queue)
+	}
+	l.mu.Unlock()
+
+	l.wg.Wait()
+
+	var errs []error
+	for _, s := range l.sinks {
+		if closer, ok := s.Logger.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("closing audit sink %s: %w", s.Name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
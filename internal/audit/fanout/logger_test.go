@@ -0,0 +1,199 @@
+package fanout
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/asimihsan/planning_engine/internal/metrics"
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+// gatedSink records what it is given, and holds each write until released.
+type gatedSink struct {
+	release chan struct{} // nil writes immediately
+	err     error
+
+	mu      sync.Mutex
+	records []gate.AuditRecord
+	closed  bool
+}
+
+func (s *gatedSink) Log(_ context.Context, record gate.AuditRecord) error {
+	if s.release != nil {
+		<-s.release
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, record)
+	return s.err
+}
+
+func (s *gatedSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *gatedSink) ids() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var ids []string
+	for _, r := range s.records {
+		ids = append(ids, r.DecisionID)
+	}
+	return ids
+}
+
+func record(id string) gate.AuditRecord {
+	return gate.AuditRecord{DecisionID: id, Outcome: gate.OutcomeAllow}
+}
+
+// fillQueue logs records until the slow sink's worker is blocked holding one and its queue is full.
+func fillQueue(t *testing.T, l *Logger, sink string, queueSize int) {
+	t.Helper()
+	require.NoError(t, l.Log(context.Background(), record("held")))
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(metrics.AuditQueueDepth.WithLabelValues(sink)) == 0
+	}, time.Second, time.Millisecond)
+	for i := 0; i < queueSize; i++ {
+		require.NoError(t, l.Log(context.Background(), record("queued")))
+	}
+}
+
+func TestLogger(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Fans out in order and flushes on close", func(t *testing.T) {
+		a, b := &gatedSink{}, &gatedSink{}
+		l := New(Sink{Name: "fanout-a", Logger: a}, Sink{Name: "fanout-b", Logger: b})
+
+		for _, id := range []string{"1", "2", "3"} {
+			require.NoError(t, l.Log(ctx, record(id)))
+		}
+		require.NoError(t, l.Close())
+
+		assert.Equal(t, []string{"1", "2", "3"}, a.ids())
+		assert.Equal(t, []string{"1", "2", "3"}, b.ids())
+		assert.True(t, a.closed)
+		assert.ErrorIs(t, l.Log(ctx, record("4")), ErrClosed)
+	})
+
+	t.Run("Slow sink does not delay the decision", func(t *testing.T) {
+		slow := &gatedSink{release: make(chan struct{})}
+		l := New(Sink{Name: "fanout-slow", Logger: slow, QueueSize: 10})
+
+		done := make(chan error)
+		go func() { done <- l.Log(ctx, record("1")) }()
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("Log waited for a slow sink")
+		}
+
+		close(slow.release)
+		require.NoError(t, l.Close())
+		assert.Equal(t, []string{"1"}, slow.ids())
+	})
+
+	t.Run("Drop when full", func(t *testing.T) {
+		slow := &gatedSink{release: make(chan struct{})}
+		l := New(Sink{Name: "fanout-drop", Logger: slow, QueueSize: 2, Overflow: OverflowDrop})
+		fillQueue(t, l, "fanout-drop", 2)
+		dropped := testutil.ToFloat64(metrics.AuditRecordsDropped.WithLabelValues("fanout-drop"))
+
+		require.NoError(t, l.Log(ctx, record("dropped")))
+		assert.Equal(t, dropped+1, testutil.ToFloat64(metrics.AuditRecordsDropped.WithLabelValues("fanout-drop")))
+		assert.Equal(t, 2.0, testutil.ToFloat64(metrics.AuditQueueDepth.WithLabelValues("fanout-drop")))
+
+		close(slow.release)
+		require.NoError(t, l.Close())
+		assert.Equal(t, []string{"held", "queued", "queued"}, slow.ids())
+	})
+
+	t.Run("Fail when full", func(t *testing.T) {
+		slow := &gatedSink{release: make(chan struct{})}
+		fast := &gatedSink{}
+		l := New(
+			Sink{Name: "fanout-fail", Logger: slow, QueueSize: 1, Overflow: OverflowFail},
+			Sink{Name: "fanout-fast", Logger: fast},
+		)
+		depth := func() float64 { return testutil.ToFloat64(metrics.AuditQueueDepth.WithLabelValues("fanout-fail")) }
+
+		// Log waits for a durable sink, so fill its queue from other goroutines
+		written := make(chan error, 2)
+		go func() { written <- l.Log(ctx, record("held")) }()
+		require.Eventually(t, func() bool { return len(fast.ids()) == 1 && depth() == 0 }, time.Second, time.Millisecond)
+		go func() { written <- l.Log(ctx, record("queued")) }()
+		require.Eventually(t, func() bool { return depth() == 1 }, time.Second, time.Millisecond)
+		refused := testutil.ToFloat64(metrics.AuditSinkErrors.WithLabelValues("fanout-fail", "queue_full"))
+
+		err := l.Log(ctx, record("refused"))
+		assert.ErrorIs(t, err, ErrQueueFull)
+		assert.Contains(t, err.Error(), "fanout-fail")
+		assert.Equal(t, refused+1, testutil.ToFloat64(metrics.AuditSinkErrors.WithLabelValues("fanout-fail", "queue_full")))
+
+		close(slow.release)
+		require.NoError(t, <-written)
+		require.NoError(t, <-written)
+		require.NoError(t, l.Close())
+		assert.Equal(t, []string{"held", "queued"}, slow.ids())
+		assert.Equal(t, []string{"held", "queued", "refused"}, fast.ids())
+	})
+
+	t.Run("Fail waits for the write", func(t *testing.T) {
+		durable := &gatedSink{release: make(chan struct{})}
+		l := New(Sink{Name: "fanout-durable", Logger: durable, Overflow: OverflowFail})
+
+		done := make(chan error)
+		go func() { done <- l.Log(ctx, record("1")) }()
+		select {
+		case <-done:
+			t.Fatal("Log returned before the durable sink wrote the record")
+		case <-time.After(10 * time.Millisecond):
+		}
+		close(durable.release)
+		require.NoError(t, <-done)
+
+		durable.err = errors.New("table unavailable")
+		err := l.Log(ctx, record("2"))
+		assert.ErrorContains(t, err, "table unavailable")
+		assert.Contains(t, err.Error(), "fanout-durable")
+		require.NoError(t, l.Close())
+	})
+
+	t.Run("Block when full", func(t *testing.T) {
+		slow := &gatedSink{release: make(chan struct{})}
+		l := New(Sink{Name: "fanout-block", Logger: slow, QueueSize: 1, Overflow: OverflowBlock})
+		fillQueue(t, l, "fanout-block", 1)
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, l.Log(timeoutCtx, record("timed out")), context.DeadlineExceeded)
+
+		done := make(chan error)
+		go func() { done <- l.Log(ctx, record("waited")) }()
+		close(slow.release)
+		require.NoError(t, <-done)
+		require.NoError(t, l.Close())
+		assert.Equal(t, []string{"held", "queued", "waited"}, slow.ids())
+	})
+
+	t.Run("Write errors are counted", func(t *testing.T) {
+		failing := &gatedSink{err: errors.New("throttled")}
+		l := New(Sink{Name: "fanout-errors", Logger: failing})
+		failed := testutil.ToFloat64(metrics.AuditSinkErrors.WithLabelValues("fanout-errors", "write"))
+
+		require.NoError(t, l.Log(ctx, record("1")))
+		require.NoError(t, l.Close())
+		assert.Equal(t, failed+1, testutil.ToFloat64(metrics.AuditSinkErrors.WithLabelValues("fanout-errors", "write")))
+	})
+}
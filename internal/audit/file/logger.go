@@ -0,0 +1,366 @@
+// Package file appends audit records to a local JSON Lines file, rotating it by size and age.
+package file
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asimihsan/planning_engine/internal/audit/chain"
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+// rotatedLayout timestamps rotated files. It has a fixed width, so names sort chronologically.
+const rotatedLayout = "20060102T150405.000000000Z"
+
+// Logger implements gate.AuditLogger by appending one JSON object per line to a file.
+// Records are hash-chained across rotations, see Verify.
+//
+// When the file would grow past its maximum size, or has been open for longer than its
+// maximum age, it is renamed to <name>-<timestamp><ext> (gzipped if compression is enabled)
+// and a new file is started. Only the newest rotated files are kept.
+type Logger struct {
+	path         string
+	maxSize      int64
+	maxAge       time.Duration
+	maxBackups   int
+	compress     bool
+	syncInterval time.Duration
+	now          func() time.Time
+
+	mu        sync.Mutex
+	f         *os.File
+	size      int64
+	openedAt  time.Time
+	head      string // Hash of the last record written, once loaded
+	hasHead   bool
+	syncTimer *time.Timer // Pending fsync of records appended since the last one
+	syncErr   error       // Failure of a background fsync, returned by the next Log or Close
+}
+
+var _ gate.AuditLogger = (*Logger)(nil)
+
+// New creates a new file audit logger appending to path. The file and its directory are
+// created on the first record. By default the file is never rotated and every record is
+// fsynced before Log returns.
+func New(path string) *Logger {
+	return &Logger{
+		path: path,
+		now:  time.Now,
+	}
+}
+
+// WithMaxSize rotates the file before a record would make it larger than maxSize bytes.
+// Zero disables size-based rotation.
+func (l *Logger) WithMaxSize(maxSize int64) *Logger {
+	l.maxSize = maxSize
+	return l
+}
+
+// WithMaxAge rotates the file once it has been written to for maxAge.
+// Zero disables age-based rotation.
+func (l *Logger) WithMaxAge(maxAge time.Duration) *Logger {
+	l.maxAge = maxAge
+	return l
+}
+
+// WithMaxBackups sets how many rotated files are kept; older ones are deleted.
+// Zero keeps every rotated file.
+func (l *Logger) WithMaxBackups(maxBackups int) *Logger {
+	l.maxBackups = maxBackups
+	return l
+}
+
+// WithCompression gzips rotated files.
+func (l *Logger) WithCompression(compress bool) *Logger {
+	l.compress = compress
+	return l
+}
+
+// WithSyncInterval fsyncs appended records at most syncInterval after they are written,
+// rather than before Log returns. Records written since the last fsync can be lost on a crash,
+// and a failed fsync is returned by the next Log. Zero fsyncs every record.
+func (l *Logger) WithSyncInterval(syncInterval time.Duration) *Logger {
+	l.syncInterval = syncInterval
+	return l
+}
+
+// Log implements gate.AuditLogger.
+func (l *Logger) Log(ctx context.Context, record gate.AuditRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Earlier records may not be durable; the caller must know before acting on another decision
+	if err := l.syncErr; err != nil {
+		l.syncErr = nil
+		return err
+	}
+	if err := l.open(); err != nil {
+		return err
+	}
+	if err := chain.Link(&record, l.head); err != nil {
+		return err
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshaling audit record: %w", err)
+	}
+	line = append(line, '\n')
+
+	if l.shouldRotate(int64(len(line))) {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := l.f.Write(line)
+	l.size += int64(n)
+	if err != nil {
+		// Part of the line may have been written: reopen the file before the next record,
+		// so that repairTail cuts it rather than the record being appended after it
+		_ = l.close()
+		l.hasHead = false
+		return fmt.Errorf("writing audit record to %s: %w", l.path, err)
+	}
+	l.head = record.Hash
+
+	if l.syncInterval <= 0 {
+		if err := l.f.Sync(); err != nil {
+			return fmt.Errorf("syncing %s: %w", l.path, err)
+		}
+	} else if l.syncTimer == nil {
+		l.syncTimer = time.AfterFunc(l.syncInterval, l.syncPending)
+	}
+	return nil
+}
+
+// Close syncs and closes the current file, and returns any failed background fsync not yet
+// returned by Log. A later Log reopens the file.
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	err := errors.Join(l.syncErr, l.close())
+	l.syncErr = nil
+	return err
+}
+
+// open opens the current file for appending if it is not open already, and loads the chain head
+// left by an earlier run.
+func (l *Logger) open() error {
+	if l.f != nil {
+		return nil
+	}
+	if !l.hasHead {
+		if err := repairTail(l.path); err != nil {
+			return fmt.Errorf("repairing audit log: %w", err)
+		}
+		head, err := lastHash(l.path)
+		if err != nil {
+			return fmt.Errorf("reading audit log chain head: %w", err)
+		}
+		l.head, l.hasHead = head, true
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return fmt.Errorf("creating audit log directory: %w", err)
+	}
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return fmt.Errorf("opening audit log: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("opening audit log: %w", err)
+	}
+
+	l.f = f
+	l.size = info.Size()
+	// The creation time is not portable; a file kept from an earlier run ages from its last write
+	l.openedAt = l.now()
+	if l.size > 0 {
+		l.openedAt = info.ModTime()
+	}
+	return nil
+}
+
+// repairTail cuts a partial last line, left by a crash before the file was synced or by a
+// failed write, from the
+// audit log at path, so that its chain continues from the last complete record. The cut bytes
+// are appended to path.torn for inspection.
+func repairTail(path string) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	size := info.Size()
+
+	// Find the end of the last complete line
+	end := size
+	buf := make([]byte, 64*1024)
+	for end > 0 {
+		n := min(int64(len(buf)), end)
+		if _, err := f.ReadAt(buf[:n], end-n); err != nil {
+			return err
+		}
+		if i := bytes.LastIndexByte(buf[:n], '\n'); i >= 0 {
+			end = end - n + int64(i) + 1
+			break
+		}
+		end -= n
+	}
+	if end == size {
+		return nil
+	}
+
+	torn := make([]byte, size-end)
+	if _, err := f.ReadAt(torn, end); err != nil {
+		return err
+	}
+	if err := appendFile(path+".torn", append(torn, '\n')); err != nil {
+		return err
+	}
+	if err := f.Truncate(end); err != nil {
+		return err
+	}
+	log.Printf("audit: cut a partial record of %d bytes from the end of %s, saved to %s.torn", len(torn), path, path)
+	return f.Sync()
+}
+
+// appendFile appends data to the file at path, creating it if needed, and syncs it.
+func appendFile(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return errors.Join(f.Sync(), f.Close())
+}
+
+// close stops any pending fsync, then syncs and closes the current file.
+func (l *Logger) close() error {
+	if l.f == nil {
+		return nil
+	}
+	if l.syncTimer != nil {
+		l.syncTimer.Stop()
+		l.syncTimer = nil
+	}
+	err := errors.Join(l.f.Sync(), l.f.Close())
+	l.f = nil
+	if err != nil {
+		return fmt.Errorf("closing %s: %w", l.path, err)
+	}
+	return nil
+}
+
+func (l *Logger) syncPending() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.syncTimer = nil
+	if l.f == nil {
+		return
+	}
+	// Linux reports a failed fsync once and then clears it, so keep the error for the next Log
+	if err := l.f.Sync(); err != nil {
+		l.syncErr = fmt.Errorf("syncing %s: %w", l.path, err)
+		log.Printf("audit: %v", l.syncErr)
+	}
+}
+
+// shouldRotate reports whether the current file must be rotated before appending n bytes.
+// A record is never split, and an empty file is never rotated.
+func (l *Logger) shouldRotate(n int64) bool {
+	if l.size == 0 {
+		return false
+	}
+	if l.maxSize > 0 && l.size+n > l.maxSize {
+		return true
+	}
+	return l.maxAge > 0 && l.now().Sub(l.openedAt) >= l.maxAge
+}
+
+// rotate moves the current file aside, compresses it if configured, prunes old rotated
+// files and opens a new current file.
+func (l *Logger) rotate() error {
+	if err := l.close(); err != nil {
+		return err
+	}
+
+	ext := filepath.Ext(l.path)
+	rotated := strings.TrimSuffix(l.path, ext) + "-" + l.now().UTC().Format(rotatedLayout) + ext
+	if err := os.Rename(l.path, rotated); err != nil {
+		return fmt.Errorf("rotating audit log: %w", err)
+	}
+	if l.compress {
+		if err := compressFile(rotated); err != nil {
+			return fmt.Errorf("compressing rotated audit log: %w", err)
+		}
+	}
+	if err := l.prune(); err != nil {
+		return err
+	}
+	return l.open()
+}
+
+// prune deletes the oldest rotated files beyond the retention count.
+func (l *Logger) prune() error {
+	if l.maxBackups <= 0 {
+		return nil
+	}
+	backups, err := Backups(l.path)
+	if err != nil {
+		return fmt.Errorf("listing rotated audit logs: %w", err)
+	}
+	for len(backups) > l.maxBackups {
+		if err := os.Remove(backups[0]); err != nil {
+			return fmt.Errorf("removing rotated audit log: %w", err)
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// compressFile replaces path with a gzipped path.gz.
+func compressFile(path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o640)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(out)
+	if _, err := io.Copy(zw, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err := errors.Join(zw.Close(), out.Sync(), out.Close()); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
@@ -0,0 +1,248 @@
+package file
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+// testClock is a manually advanced clock.
+type testClock struct{ t time.Time }
+
+func (c *testClock) now() time.Time { return c.t }
+
+func newTestLogger(t *testing.T) (*Logger, *testClock, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "audit", "audit.jsonl")
+	clock := &testClock{t: time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)}
+	logger := New(path)
+	logger.now = clock.now
+	t.Cleanup(func() { _ = logger.Close() })
+	return logger, clock, path
+}
+
+func testRecord(n int) gate.AuditRecord {
+	return gate.AuditRecord{
+		DecisionID:   "decision-" + strings.Repeat("x", n),
+		Timestamp:    time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC),
+		DeploymentID: "dep",
+		Stage:        "canary",
+		Outcome:      gate.OutcomeAllow,
+		PolicySHA:    "policy-sha",
+		ConfigSHA:    "config-sha",
+		UsedFacts:    map[string]any{"pending_delta": 100},
+	}
+}
+
+func readRecords(t *testing.T, r io.Reader) []gate.AuditRecord {
+	t.Helper()
+	var records []gate.AuditRecord
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		var record gate.AuditRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	require.NoError(t, scanner.Err())
+	return records
+}
+
+func readFile(t *testing.T, path string) []gate.AuditRecord {
+	t.Helper()
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		zr, err := gzip.NewReader(f)
+		require.NoError(t, err)
+		r = zr
+	}
+	return readRecords(t, r)
+}
+
+func TestLogger(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Appends one JSON object per line", func(t *testing.T) {
+		logger, _, path := newTestLogger(t)
+
+		require.NoError(t, logger.Log(ctx, testRecord(1)))
+		record := testRecord(2)
+		record.Outcome = gate.OutcomeError
+		record.ErrorClass = "fact_stale"
+		require.NoError(t, logger.Log(ctx, record))
+
+		records := readFile(t, path)
+		require.Len(t, records, 2)
+		assert.Equal(t, testRecord(1).DecisionID, records[0].DecisionID)
+		assert.Equal(t, gate.OutcomeError, records[1].Outcome)
+		assert.Equal(t, "fact_stale", records[1].ErrorClass)
+	})
+
+	t.Run("Appends to an existing file", func(t *testing.T) {
+		logger, _, path := newTestLogger(t)
+		require.NoError(t, logger.Log(ctx, testRecord(1)))
+		require.NoError(t, logger.Close())
+
+		reopened := New(path)
+		defer reopened.Close()
+		require.NoError(t, reopened.Log(ctx, testRecord(2)))
+
+		assert.Len(t, readFile(t, path), 2)
+	})
+
+	t.Run("Cuts a torn last record", func(t *testing.T) {
+		logger, _, path := newTestLogger(t)
+		require.NoError(t, logger.Log(ctx, testRecord(1)))
+		require.NoError(t, logger.Close())
+
+		// A crash before the file was synced leaves half a record
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+		require.NoError(t, err)
+		_, err = f.WriteString(`{"decision_id":"torn","outc`)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		reopened := New(path)
+		require.NoError(t, reopened.Log(ctx, testRecord(2)))
+		require.NoError(t, reopened.Close())
+
+		records := readFile(t, path)
+		require.Len(t, records, 2)
+		assert.Equal(t, testRecord(2).DecisionID, records[1].DecisionID)
+		_, count, err := Verify(path)
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		torn, err := os.ReadFile(path + ".torn")
+		require.NoError(t, err)
+		assert.Equal(t, "{\"decision_id\":\"torn\",\"outc\n", string(torn))
+	})
+
+	t.Run("Cuts a partially written record before the next one", func(t *testing.T) {
+		logger, _, path := newTestLogger(t)
+		require.NoError(t, logger.Log(ctx, testRecord(1)))
+
+		// A short write, e.g. on a full disk, leaves half a record; fail the write that made it
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+		require.NoError(t, err)
+		_, err = f.WriteString(`{"decision_id":"short","outc`)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+		require.NoError(t, logger.f.Close())
+		logger.f, err = os.Open(path)
+		require.NoError(t, err)
+		assert.ErrorContains(t, logger.Log(ctx, testRecord(2)), "writing audit record")
+
+		require.NoError(t, logger.Log(ctx, testRecord(3)))
+		require.NoError(t, logger.Close())
+		records := readFile(t, path)
+		require.Len(t, records, 2)
+		assert.Equal(t, testRecord(3).DecisionID, records[1].DecisionID)
+		_, count, err := Verify(path)
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("Rotates by size", func(t *testing.T) {
+		logger, clock, path := newTestLogger(t)
+		linked := testRecord(1)
+		linked.PrevHash, linked.Hash = strings.Repeat("0", 64), strings.Repeat("0", 64)
+		line, err := json.Marshal(linked)
+		require.NoError(t, err)
+		// Room for two records per file
+		logger.WithMaxSize(int64(2*(len(line)+1) + 1))
+
+		for i := 0; i < 5; i++ {
+			require.NoError(t, logger.Log(ctx, testRecord(1)))
+			clock.t = clock.t.Add(time.Second)
+		}
+
+		backups, err := Backups(logger.path)
+		require.NoError(t, err)
+		require.Len(t, backups, 2)
+		assert.Len(t, readFile(t, backups[0]), 2)
+		assert.Len(t, readFile(t, backups[1]), 2)
+		assert.Len(t, readFile(t, path), 1)
+	})
+
+	t.Run("Rotates by age", func(t *testing.T) {
+		logger, clock, path := newTestLogger(t)
+		logger.WithMaxAge(time.Hour)
+
+		require.NoError(t, logger.Log(ctx, testRecord(1)))
+		clock.t = clock.t.Add(30 * time.Minute)
+		require.NoError(t, logger.Log(ctx, testRecord(2)))
+		clock.t = clock.t.Add(30 * time.Minute)
+		require.NoError(t, logger.Log(ctx, testRecord(3)))
+
+		backups, err := Backups(logger.path)
+		require.NoError(t, err)
+		require.Len(t, backups, 1)
+		assert.Equal(t, filepath.Join(filepath.Dir(path), "audit-20250401T130000.000000000Z.jsonl"), backups[0])
+		assert.Len(t, readFile(t, backups[0]), 2)
+		assert.Len(t, readFile(t, path), 1)
+	})
+
+	t.Run("Compresses and prunes rotated files", func(t *testing.T) {
+		logger, clock, _ := newTestLogger(t)
+		logger.WithMaxSize(1).WithMaxBackups(2).WithCompression(true)
+
+		for i := 1; i <= 5; i++ {
+			require.NoError(t, logger.Log(ctx, testRecord(i)))
+			clock.t = clock.t.Add(time.Second)
+		}
+
+		backups, err := Backups(logger.path)
+		require.NoError(t, err)
+		require.Len(t, backups, 2)
+		for i, backup := range backups {
+			assert.True(t, strings.HasSuffix(backup, ".jsonl.gz"), backup)
+			// Records 1 and 2 were pruned
+			records := readFile(t, backup)
+			require.Len(t, records, 1)
+			assert.Equal(t, testRecord(i+3).DecisionID, records[0].DecisionID)
+		}
+	})
+
+	t.Run("Deferred sync", func(t *testing.T) {
+		logger, _, path := newTestLogger(t)
+		logger.WithSyncInterval(time.Hour)
+
+		require.NoError(t, logger.Log(ctx, testRecord(1)))
+		require.NotNil(t, logger.syncTimer)
+		// Records are written immediately, even if not yet fsynced
+		assert.Len(t, readFile(t, path), 1)
+
+		require.NoError(t, logger.Close())
+		assert.Nil(t, logger.syncTimer)
+	})
+
+	t.Run("Failed deferred sync fails the next Log", func(t *testing.T) {
+		logger, _, _ := newTestLogger(t)
+		logger.WithSyncInterval(time.Hour)
+		require.NoError(t, logger.Log(ctx, testRecord(1)))
+
+		// Fail the background fsync by closing the file under the logger
+		f := logger.f
+		require.NoError(t, f.Close())
+		logger.syncPending()
+
+		assert.ErrorContains(t, logger.Log(ctx, testRecord(2)), "syncing")
+		logger.f = nil // Already closed
+	})
+}
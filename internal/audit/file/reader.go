@@ -0,0 +1,218 @@
+package file
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/asimihsan/planning_engine/internal/audit/chain"
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+// maxLineSize bounds the length of a single audit record.
+const maxLineSize = 16 * 1024 * 1024
+
+// Backups returns the rotated files of the audit log at path, oldest first.
+func Backups(path string) ([]string, error) {
+	ext := filepath.Ext(path)
+	matches, err := filepath.Glob(strings.TrimSuffix(path, ext) + "-*" + ext + "*")
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []string
+	for _, m := range matches {
+		if strings.HasSuffix(m, ext) || strings.HasSuffix(m, ext+".gz") {
+			backups = append(backups, m)
+		}
+	}
+	sort.Strings(backups)
+	return backups, nil
+}
+
+// Files returns the rotated files of the audit log at path followed by path itself if it
+// exists, i.e. every file holding its records, oldest first.
+func Files(path string) ([]string, error) {
+	files, err := Backups(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); err == nil {
+		files = append(files, path)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	return files, nil
+}
+
+// Open opens an audit log file for reading, decompressing it if it is gzipped.
+func Open(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(path, ".gz") {
+		return f, nil
+	}
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("decompressing %s: %w", path, err)
+	}
+	return gzipFile{Reader: zr, f: f}, nil
+}
+
+type gzipFile struct {
+	*gzip.Reader
+	f *os.File
+}
+
+func (g gzipFile) Close() error {
+	return errors.Join(g.Reader.Close(), g.f.Close())
+}
+
+// scanLines calls fn with each non-empty line of the audit log file at path and its line number.
+func scanLines(path string, fn func(line []byte, n int) error) error {
+	r, err := Open(path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	n := 0
+	for scanner.Scan() {
+		n++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		if err := fn(scanner.Bytes(), n); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	return nil
+}
+
+// lastHash returns the Hash of the last record in the audit log at path, or "" if it has none.
+func lastHash(path string) (string, error) {
+	files, err := Files(path)
+	if err != nil {
+		return "", err
+	}
+	for i := len(files) - 1; i >= 0; i-- {
+		var last []byte
+		err := scanLines(files[i], func(line []byte, _ int) error {
+			last = append(last[:0], line...)
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+		if last == nil {
+			continue
+		}
+
+		var record gate.AuditRecord
+		if err := json.Unmarshal(last, &record); err != nil {
+			return "", fmt.Errorf("decoding last record of %s: %w", files[i], err)
+		}
+		return record.Hash, nil
+	}
+	return "", nil
+}
+
+// Verify walks the hash chain of the audit log at path across its rotated files, and returns
+// the chain head and the number of records checked. The first broken link is reported as an
+// error wrapping chain.ErrBroken, naming the file and line.
+func Verify(path string) (head string, count int, err error) {
+	files, err := Files(path)
+	if err != nil {
+		return "", 0, err
+	}
+	if len(files) == 0 {
+		return "", 0, fmt.Errorf("no audit log at %s: %w", path, os.ErrNotExist)
+	}
+
+	var v chain.Verifier
+	for _, file := range files {
+		err := scanLines(file, func(line []byte, n int) error {
+			if err := v.Add(line); err != nil {
+				return fmt.Errorf("%s:%d: %w", file, n, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return v.Head(), v.Count(), err
+		}
+	}
+	return v.Head(), v.Count(), nil
+}
+
+// Reader implements gate.AuditReader over the audit log at a path and its rotated files.
+type Reader struct {
+	path string
+}
+
+var _ gate.AuditReader = (*Reader)(nil)
+
+// NewReader creates a reader of the audit log at path.
+func NewReader(path string) *Reader {
+	return &Reader{path: path}
+}
+
+// Query implements gate.AuditReader. Rotated files closed before q.Since are not read.
+func (r *Reader) Query(ctx context.Context, q gate.AuditQuery) ([]gate.AuditRecord, error) {
+	files, err := Files(r.path)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []gate.AuditRecord
+	for _, file := range files {
+		if rotated, ok := rotatedAt(r.path, file); ok && !q.Since.IsZero() && rotated.Before(q.Since) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		err := scanLines(file, func(line []byte, n int) error {
+			var record gate.AuditRecord
+			if err := json.Unmarshal(line, &record); err != nil {
+				return fmt.Errorf("%s:%d: decoding audit record: %w", file, n, err)
+			}
+			if q.Matches(record) {
+				records = append(records, record)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return gate.LimitRecords(records, q.Limit), nil
+}
+
+// rotatedAt returns when the rotated file backup of the audit log at path was closed.
+func rotatedAt(path, backup string) (time.Time, bool) {
+	ext := filepath.Ext(path)
+	stamp, ok := strings.CutPrefix(backup, strings.TrimSuffix(path, ext)+"-")
+	if !ok {
+		return time.Time{}, false
+	}
+	stamp = strings.TrimSuffix(strings.TrimSuffix(stamp, ".gz"), ext)
+	t, err := time.Parse(rotatedLayout, stamp)
+	return t, err == nil
+}
@@ -0,0 +1,150 @@
+package file
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/asimihsan/planning_engine/internal/audit/chain"
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+func TestVerify(t *testing.T) {
+	ctx := context.Background()
+
+	// writeLog writes five records across rotated, compressed files and a restart.
+	writeLog := func(t *testing.T) (path, head string) {
+		logger, clock, path := newTestLogger(t)
+		logger.WithMaxSize(1).WithCompression(true)
+		for i := 1; i <= 3; i++ {
+			require.NoError(t, logger.Log(ctx, testRecord(i)))
+			clock.t = clock.t.Add(time.Second)
+		}
+		require.NoError(t, logger.Close())
+
+		restarted := New(path).WithMaxSize(1).WithCompression(true)
+		restarted.now = clock.now
+		defer restarted.Close()
+		for i := 4; i <= 5; i++ {
+			require.NoError(t, restarted.Log(ctx, testRecord(i)))
+			clock.t = clock.t.Add(time.Second)
+		}
+		return path, restarted.head
+	}
+
+	t.Run("Intact chain", func(t *testing.T) {
+		path, want := writeLog(t)
+
+		head, count, err := Verify(path)
+		require.NoError(t, err)
+		assert.Equal(t, 5, count)
+		assert.Equal(t, want, head)
+		assert.Len(t, head, 64)
+	})
+
+	t.Run("Modified record", func(t *testing.T) {
+		path, _ := writeLog(t)
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, []byte(strings.Replace(string(data), `"outcome":"allow"`, `"outcome":"deny"`, 1)), 0o640))
+
+		_, count, err := Verify(path)
+		assert.ErrorIs(t, err, chain.ErrBroken)
+		assert.Contains(t, err.Error(), path+":1:")
+		assert.Contains(t, err.Error(), "modified")
+		assert.Equal(t, 4, count)
+	})
+
+	t.Run("Removed record", func(t *testing.T) {
+		path, _ := writeLog(t)
+		backups, err := Backups(path)
+		require.NoError(t, err)
+		require.NoError(t, os.Remove(backups[1]))
+
+		_, _, err = Verify(path)
+		assert.ErrorIs(t, err, chain.ErrBroken)
+		assert.Contains(t, err.Error(), backups[2]+":1:")
+		assert.Contains(t, err.Error(), "removed or reordered")
+	})
+
+	t.Run("Pruned history", func(t *testing.T) {
+		path, want := writeLog(t)
+		backups, err := Backups(path)
+		require.NoError(t, err)
+		// Retention removes the oldest files; the rest of the chain still verifies
+		require.NoError(t, os.Remove(backups[0]))
+
+		head, count, err := Verify(path)
+		require.NoError(t, err)
+		assert.Equal(t, 4, count)
+		assert.Equal(t, want, head)
+	})
+}
+
+func TestReader_Query(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	// Five records a minute apart, one per file: deny, allow, deny, allow, deny
+	logger, clock, path := newTestLogger(t)
+	logger.WithMaxSize(1)
+	for i := 1; i <= 5; i++ {
+		record := testRecord(i)
+		record.Timestamp = clock.t
+		if i%2 == 1 {
+			record.Outcome = gate.OutcomeDeny
+			record.DenyReasons = []string{"pending_delta exceeds allowed limit"}
+		}
+		require.NoError(t, logger.Log(ctx, record))
+		clock.t = clock.t.Add(time.Minute)
+	}
+	require.NoError(t, logger.Close())
+	reader := NewReader(path)
+
+	ids := func(records []gate.AuditRecord) []int {
+		var n []int
+		for _, r := range records {
+			n = append(n, len(strings.TrimPrefix(r.DecisionID, "decision-")))
+		}
+		return n
+	}
+
+	t.Run("Filters", func(t *testing.T) {
+		records, err := reader.Query(ctx, gate.AuditQuery{DeploymentID: "dep", Stage: "canary", Outcome: gate.OutcomeDeny})
+		require.NoError(t, err)
+		assert.Equal(t, []int{1, 3, 5}, ids(records))
+
+		records, err = reader.Query(ctx, gate.AuditQuery{Stage: "prod"})
+		require.NoError(t, err)
+		assert.Empty(t, records)
+	})
+
+	t.Run("Time range and limit", func(t *testing.T) {
+		records, err := reader.Query(ctx, gate.AuditQuery{Since: start.Add(time.Minute), Until: start.Add(4 * time.Minute)})
+		require.NoError(t, err)
+		assert.Equal(t, []int{2, 3, 4}, ids(records))
+
+		records, err = reader.Query(ctx, gate.AuditQuery{Limit: 2})
+		require.NoError(t, err)
+		assert.Equal(t, []int{4, 5}, ids(records))
+	})
+
+	t.Run("Skips files rotated before the range", func(t *testing.T) {
+		backups, err := Backups(path)
+		require.NoError(t, err)
+		require.NotEmpty(t, backups)
+		require.NoError(t, os.WriteFile(backups[0], []byte("not json\n"), 0o640))
+
+		records, err := reader.Query(ctx, gate.AuditQuery{Since: start.Add(3 * time.Minute)})
+		require.NoError(t, err)
+		assert.Equal(t, []int{4, 5}, ids(records))
+
+		_, err = reader.Query(ctx, gate.AuditQuery{})
+		assert.ErrorContains(t, err, backups[0]+":1:")
+	})
+}
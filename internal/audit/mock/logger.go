@@ -0,0 +1,50 @@
+// Package mock provides an in-memory audit logger for tests.
+package mock
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+// Logger implements gate.AuditLogger by keeping the records it is given in memory.
+type Logger struct {
+	mu        sync.Mutex
+	decisions []gate.AuditRecord
+	errors    []gate.AuditRecord
+}
+
+var _ gate.AuditLogger = (*Logger)(nil)
+
+// NewLogger creates a new, empty mock audit logger.
+func NewLogger() *Logger {
+	return &Logger{}
+}
+
+// Log implements gate.AuditLogger.
+func (l *Logger) Log(_ context.Context, record gate.AuditRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if record.Outcome == gate.OutcomeError {
+		l.errors = append(l.errors, record)
+	} else {
+		l.decisions = append(l.decisions, record)
+	}
+	return nil
+}
+
+// Decisions returns the allow and deny records logged so far.
+func (l *Logger) Decisions() []gate.AuditRecord {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return slices.Clone(l.decisions)
+}
+
+// Errors returns the system error records logged so far.
+func (l *Logger) Errors() []gate.AuditRecord {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return slices.Clone(l.errors)
+}
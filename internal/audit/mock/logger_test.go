@@ -0,0 +1,28 @@
+package mock
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+func TestLogger(t *testing.T) {
+	ctx := context.Background()
+	logger := NewLogger()
+	assert.Empty(t, logger.Decisions())
+	assert.Empty(t, logger.Errors())
+
+	require.NoError(t, logger.Log(ctx, gate.AuditRecord{DecisionID: "allow", Outcome: gate.OutcomeAllow}))
+	require.NoError(t, logger.Log(ctx, gate.AuditRecord{DecisionID: "deny", Outcome: gate.OutcomeDeny}))
+	require.NoError(t, logger.Log(ctx, gate.AuditRecord{DecisionID: "error", Outcome: gate.OutcomeError}))
+
+	require.Len(t, logger.Decisions(), 2)
+	assert.Equal(t, "allow", logger.Decisions()[0].DecisionID)
+	assert.Equal(t, "deny", logger.Decisions()[1].DecisionID)
+	require.Len(t, logger.Errors(), 1)
+	assert.Equal(t, "error", logger.Errors()[0].DecisionID)
+}
@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"time"
 
 	"github.com/asimihsan/planning_engine/pkg/gate"
 )
@@ -20,23 +19,27 @@ func New() *Logger {
 	return &Logger{}
 }
 
-// LogDecision implements gate.AuditLogger.
-func (l *Logger) LogDecision(ctx context.Context, input map[string]any, decision gate.Decision, policyID, configID string, evalDuration time.Duration) error {
-	inputJSON, err := json.Marshal(input)
-	if err != nil {
-		inputJSON = []byte(fmt.Sprintf("error marshaling input: %v", err))
+// Log implements gate.AuditLogger.
+func (l *Logger) Log(ctx context.Context, record gate.AuditRecord) error {
+	if record.Outcome == gate.OutcomeError {
+		log.Printf("[AUDIT SYSTEM ERROR] DecisionID: %s, DeploymentID: %s, Stage: %s, PolicyID: %s, ConfigID: %s, ErrorClass: %s, Error: %s\n",
+			record.DecisionID, record.DeploymentID, record.Stage, record.PolicySHA, record.ConfigSHA, record.ErrorClass, record.ErrorDetails)
+		return nil
 	}
 
-	log.Printf("[AUDIT DECISION] PolicyID: %s, ConfigID: %s, Allow: %v, Reasons: %v, Duration: %s, Input: %s\n",
-		policyID, configID, decision.Allow, decision.DenyReasons, evalDuration, string(inputJSON))
+	factsJSON, err := json.Marshal(record.UsedFacts)
+	if err != nil {
+		factsJSON = []byte(fmt.Sprintf("error marshaling facts: %v", err))
+	}
 
-	return nil
-}
+	metaJSON, err := json.Marshal(record.FactMeta)
+	if err != nil {
+		metaJSON = []byte(fmt.Sprintf("error marshaling fact metadata: %v", err))
+	}
 
-// LogSystemError implements gate.AuditLogger.
-func (l *Logger) LogSystemError(ctx context.Context, systemError error, deploymentID, stage, policyID, configID string) error {
-	log.Printf("[AUDIT SYSTEM ERROR] DeploymentID: %s, Stage: %s, PolicyID: %s, ConfigID: %s, Error: %v\n",
-		deploymentID, stage, policyID, configID, systemError)
+	log.Printf("[AUDIT DECISION] DecisionID: %s, DeploymentID: %s, Stage: %s, RequestedCount: %d, PolicyID: %s, PolicyKeyID: %s, ConfigID: %s, Outcome: %s, Reasons: %v, SnapshotDuration: %s, EvalDuration: %s, UsedFacts: %s, FactMeta: %s, MissingFacts: %v\n",
+		record.DecisionID, record.DeploymentID, record.Stage, record.RequestedCount, record.PolicySHA, record.PolicyKeyID, record.ConfigSHA,
+		record.Outcome, record.DenyReasons, record.SnapshotDuration, record.EvalDuration, string(factsJSON), string(metaJSON), record.MissingFacts)
 
 	return nil
 }
@@ -2,7 +2,6 @@ package stdout
 
 import (
 	"context"
-	"errors"
 	"testing"
 	"time"
 
@@ -14,39 +13,42 @@ func TestLogger(t *testing.T) {
 	logger := New()
 	ctx := context.Background()
 
-	t.Run("LogDecision", func(t *testing.T) {
-		input := map[string]any{
-			"fact1": 42,
-			"fact2": "value",
+	t.Run("Decision", func(t *testing.T) {
+		record := gate.AuditRecord{
+			DecisionID:   "decision-1",
+			Timestamp:    time.Now(),
+			DeploymentID: "test-deployment",
+			Stage:        "test-stage",
+			Outcome:      gate.OutcomeAllow,
+			PolicySHA:    "test-policy",
+			ConfigSHA:    "test-config",
+			EvalDuration: time.Millisecond * 50,
+			UsedFacts:    map[string]any{"fact1": 42, "fact2": "value"},
 		}
-		decision := gate.Decision{
-			Allow:       true,
-			DenyReasons: nil,
-		}
-		err := logger.LogDecision(ctx, input, decision, "test-policy", "test-config", time.Millisecond*50)
+		err := logger.Log(ctx, record)
 		if err != nil {
 			t.Errorf("Expected no error, got: %v", err)
 		}
 
 		// Test with a deny decision
-		decision = gate.Decision{
-			Allow:       false,
-			DenyReasons: []string{"reason1", "reason2"},
-		}
-		err = logger.LogDecision(ctx, input, decision, "test-policy", "test-config", time.Millisecond*50)
+		record.Outcome = gate.OutcomeDeny
+		record.DenyReasons = []string{"reason1", "reason2"}
+		err = logger.Log(ctx, record)
 		if err != nil {
 			t.Errorf("Expected no error, got: %v", err)
 		}
 	})
 
-	t.Run("LogSystemError", func(t *testing.T) {
-		err := logger.LogSystemError(ctx, errors.New("test error"), "test-deployment", "test-stage", "test-policy", "test-config")
-		if err != nil {
-			t.Errorf("Expected no error, got: %v", err)
-		}
-
-		// Test with a standard error type
-		err = logger.LogSystemError(ctx, gate.ErrFactStale, "test-deployment", "test-stage", "test-policy", "test-config")
+	t.Run("System error", func(t *testing.T) {
+		err := logger.Log(ctx, gate.AuditRecord{
+			DecisionID:   "decision-2",
+			DeploymentID: "test-deployment",
+			Stage:        "test-stage",
+			Outcome:      gate.OutcomeError,
+			ErrorClass:   gate.ErrorClass(gate.ErrFactStale),
+			ErrorDetails: gate.ErrFactStale.Error(),
+			ConfigSHA:    "test-config",
+		})
 		if err != nil {
 			t.Errorf("Expected no error, got: %v", err)
 		}
@@ -0,0 +1,156 @@
+package opa
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/opa/v1/ast"
+	"github.com/open-policy-agent/opa/v1/bundle"
+	"github.com/open-policy-agent/opa/v1/rego"
+
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+// IsBundleArchive reports whether name refers to an OPA bundle tarball.
+func IsBundleArchive(name string) bool {
+	return strings.HasSuffix(name, ".tar.gz") || strings.HasSuffix(name, ".tgz")
+}
+
+// CompilePolicy compiles policy content fetched under name: an OPA bundle tarball
+// if name ends in .tar.gz or .tgz, otherwise a single Rego module.
+// If vc is set, only bundles signed by one of its keys are accepted; a bare Rego module
+// cannot carry a signature and is rejected.
+func CompilePolicy(ctx context.Context, name string, data []byte, query string, vc *bundle.VerificationConfig) (*OpaPolicyBundle, error) {
+	if IsBundleArchive(name) {
+		return LoadBundleArchive(ctx, data, query, vc)
+	}
+	if vc != nil {
+		return nil, fmt.Errorf("%w: %s is not a signed bundle; signature verification is enabled", gate.ErrPolicyLoad, name)
+	}
+	return Compile(ctx, name, data, query)
+}
+
+// LoadBundleArchive reads and compiles an OPA bundle tarball, verifying its signature if vc is set.
+// The content SHA is the SHA256 of the archive. An InputSchemaFile at the root of the archive
+// becomes the bundle's input schema. Errors wrap gate.ErrPolicyLoad.
+func LoadBundleArchive(ctx context.Context, data []byte, query string, vc *bundle.VerificationConfig) (*OpaPolicyBundle, error) {
+	b, keyID, err := readBundle(bundle.NewReader(bytes.NewReader(data)), vc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: reading bundle archive: %v", gate.ErrPolicyLoad, err)
+	}
+
+	hash := sha256.Sum256(data)
+	compiled, err := CompileBundle(ctx, &b, hex.EncodeToString(hash[:]), query)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := readArchiveSchema(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: reading %s from bundle archive: %v", gate.ErrPolicyLoad, InputSchemaFile, err)
+	}
+	if err := compiled.attachSchema(ctx, schema); err != nil {
+		return nil, err
+	}
+	compiled.BundleData = data
+	compiled.KeyID = keyID
+	return compiled, nil
+}
+
+// LoadBundleDir reads and compiles an OPA bundle directory, verifying its signature if vc is set.
+// The content SHA is computed by HashDir. An InputSchemaFile at the root of the directory
+// becomes the bundle's input schema. Errors wrap gate.ErrPolicyLoad.
+func LoadBundleDir(ctx context.Context, dir, query string, vc *bundle.VerificationConfig) (*OpaPolicyBundle, error) {
+	contentSHA, err := HashDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	b, keyID, err := readBundle(bundle.NewCustomReader(bundle.NewDirectoryLoader(dir)), vc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: reading bundle directory %s: %v", gate.ErrPolicyLoad, dir, err)
+	}
+	compiled, err := CompileBundle(ctx, &b, contentSHA, query)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := readFileSchema(filepath.Join(dir, InputSchemaFile))
+	if err != nil {
+		return nil, fmt.Errorf("%w: reading %s: %v", gate.ErrPolicyLoad, InputSchemaFile, err)
+	}
+	if err := compiled.attachSchema(ctx, schema); err != nil {
+		return nil, err
+	}
+	compiled.KeyID = keyID
+	return compiled, nil
+}
+
+// CompileBundle prepares query against a bundle's modules and data documents.
+// The bundle ID is contentSHA, prefixed with the manifest revision if the bundle has one,
+// e.g. "2025-04-01.1@9f86d0…". Errors wrap gate.ErrPolicyLoad.
+func CompileBundle(ctx context.Context, b *bundle.Bundle, contentSHA, query string) (*OpaPolicyBundle, error) {
+	pq, err := rego.New(
+		rego.Query(query),
+		rego.ParsedBundle("policy", b),
+	).PrepareForEval(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: preparing policy query '%s': %v", gate.ErrPolicyLoad, query, err)
+	}
+
+	id := contentSHA
+	if b.Manifest.Revision != "" {
+		id = b.Manifest.Revision + "@" + contentSHA
+	}
+
+	modules := make(map[string]*ast.Module, len(b.Modules))
+	for _, m := range b.Modules {
+		modules[m.Path] = m.Parsed
+	}
+	refs, all := analyzeFacts(modules)
+
+	return &OpaPolicyBundle{
+		BundleID:      id,
+		Revision:      b.Manifest.Revision,
+		PreparedQuery: pq,
+		FactRefs:      refs,
+		ReadsAllFacts: all,
+	}, nil
+}
+
+// HashDir returns a SHA256 over the relative paths and contents of every file under dir,
+// so that any edit, addition or removal changes the hash.
+func HashDir(dir string) (string, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("%w: walking bundle directory %s: %v", gate.ErrPolicyLoad, dir, err)
+	}
+	sort.Strings(paths)
+
+	h := sha256.New()
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("%w: reading bundle file %s: %v", gate.ErrPolicyLoad, path, err)
+		}
+		rel, _ := filepath.Rel(dir, path)
+		fmt.Fprintf(h, "%s\x00%d\x00", filepath.ToSlash(rel), len(content))
+		h.Write(content)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
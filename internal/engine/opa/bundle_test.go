@@ -0,0 +1,160 @@
+package opa
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+// testBundleFiles is a bundle with a shared library package, a data.json threshold and a .manifest.
+var testBundleFiles = map[string]string{
+	".manifest": `{"revision": "2025-04-01.1", "roots": ["gate", "limits"]}`,
+	"gate/lib/compare.rego": `
+package gate.lib
+
+within(value, limit) if value <= limit
+`,
+	"gate/main.rego": `
+package gate
+
+import data.gate.lib
+
+default allow := false
+
+allow if lib.within(input.facts.pending_delta, data.limits.max_pending)
+
+response := {"allow": allow, "deny_reasons": []}
+`,
+	"limits/data.json": `{"max_pending": 500}`,
+}
+
+func writeBundleDir(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	return dir
+}
+
+func bundleArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: "/" + name, Mode: 0o644, Size: int64(len(content))}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func evaluatePending(t *testing.T, b *OpaPolicyBundle, pendingDelta int) bool {
+	t.Helper()
+	decision, err := NewEngine().Evaluate(context.Background(), b, map[string]any{
+		"facts": map[string]any{"pending_delta": pendingDelta},
+	})
+	require.NoError(t, err)
+	return decision.Allow
+}
+
+func TestLoadBundleDir(t *testing.T) {
+	dir := writeBundleDir(t, testBundleFiles)
+
+	b, err := LoadBundleDir(context.Background(), dir, "data.gate.response", nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "2025-04-01.1", b.Revision)
+	assert.True(t, strings.HasPrefix(b.ID(), "2025-04-01.1@"), b.ID())
+
+	// data.limits.max_pending comes from data.json, lib.within from a shared package
+	assert.True(t, evaluatePending(t, b, 100))
+	assert.False(t, evaluatePending(t, b, 600))
+}
+
+func TestLoadBundleArchive(t *testing.T) {
+	archive := bundleArchive(t, testBundleFiles)
+
+	b, err := CompilePolicy(context.Background(), "policy-v1.tar.gz", archive, "data.gate.response", nil)
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(b.ID(), "2025-04-01.1@"), b.ID())
+	assert.Equal(t, archive, b.Data())
+	assert.True(t, evaluatePending(t, b, 100))
+	assert.False(t, evaluatePending(t, b, 600))
+}
+
+func TestLoadBundle_NoRevision(t *testing.T) {
+	files := map[string]string{
+		"gate/main.rego": `
+package gate
+
+response := {"allow": true, "deny_reasons": []}
+`,
+	}
+
+	b, err := LoadBundleDir(context.Background(), writeBundleDir(t, files), "data.gate.response", nil)
+	require.NoError(t, err)
+	assert.Len(t, b.ID(), 64)
+	assert.Empty(t, b.Revision)
+}
+
+func TestLoadBundle_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Data outside manifest roots", func(t *testing.T) {
+		files := map[string]string{
+			".manifest":        `{"roots": ["gate"]}`,
+			"gate/main.rego":   "package gate\n\nresponse := {\"allow\": true}\n",
+			"limits/data.json": `{"max_pending": 500}`,
+		}
+		_, err := LoadBundleDir(ctx, writeBundleDir(t, files), "data.gate.response", nil)
+		assert.ErrorIs(t, err, gate.ErrPolicyLoad)
+	})
+
+	t.Run("Invalid module", func(t *testing.T) {
+		files := map[string]string{"gate/main.rego": "this is not valid Rego syntax"}
+		_, err := LoadBundleDir(ctx, writeBundleDir(t, files), "data.gate.response", nil)
+		assert.ErrorIs(t, err, gate.ErrPolicyLoad)
+	})
+
+	t.Run("Corrupt archive", func(t *testing.T) {
+		_, err := LoadBundleArchive(ctx, []byte("not a tarball"), "data.gate.response", nil)
+		assert.ErrorIs(t, err, gate.ErrPolicyLoad)
+	})
+
+	t.Run("Missing directory", func(t *testing.T) {
+		_, err := LoadBundleDir(ctx, filepath.Join(t.TempDir(), "missing"), "data.gate.response", nil)
+		assert.ErrorIs(t, err, gate.ErrPolicyLoad)
+	})
+}
+
+func TestHashDir(t *testing.T) {
+	dir := writeBundleDir(t, testBundleFiles)
+
+	h1, err := HashDir(dir)
+	require.NoError(t, err)
+	h2, err := HashDir(dir)
+	require.NoError(t, err)
+	assert.Equal(t, h1, h2)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "limits", "data.json"), []byte(`{"max_pending": 250}`), 0o644))
+	h3, err := HashDir(dir)
+	require.NoError(t, err)
+	assert.NotEqual(t, h1, h3)
+}
@@ -0,0 +1,42 @@
+package opa
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/open-policy-agent/opa/v1/ast"
+	"github.com/open-policy-agent/opa/v1/rego"
+
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+// Compile compiles a single Rego module and prepares query against it.
+// The bundle ID is the SHA256 of the module source. Errors wrap gate.ErrPolicyLoad.
+func Compile(ctx context.Context, moduleName string, module []byte, query string) (*OpaPolicyBundle, error) {
+	compiler, err := ast.CompileModules(map[string]string{
+		moduleName: string(module),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: compiling policy module %s: %v", gate.ErrPolicyLoad, moduleName, err)
+	}
+
+	pq, err := rego.New(
+		rego.Query(query),
+		rego.Compiler(compiler),
+	).PrepareForEval(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: preparing policy query '%s': %v", gate.ErrPolicyLoad, query, err)
+	}
+
+	hash := sha256.Sum256(module)
+	refs, all := factRefs(compiler.Modules)
+	return &OpaPolicyBundle{
+		BundleID:      hex.EncodeToString(hash[:]),
+		PreparedQuery: pq,
+		BundleData:    module,
+		FactRefs:      refs,
+		ReadsAllFacts: all,
+	}, nil
+}
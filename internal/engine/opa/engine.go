@@ -5,18 +5,28 @@ import (
 	"fmt"
 
 	"github.com/open-policy-agent/opa/v1/rego"
+	"github.com/prometheus/client_golang/prometheus"
 
+	"github.com/asimihsan/planning_engine/internal/metrics"
 	"github.com/asimihsan/planning_engine/pkg/gate"
 )
 
 // OpaPolicyBundle is a concrete implementation of gate.PolicyBundle for OPA policies
 type OpaPolicyBundle struct {
 	BundleID      string
+	Revision      string // From the OPA bundle .manifest, if any
 	PreparedQuery rego.PreparedEvalQuery
 	BundleData    []byte
+	KeyID         string       // Key that signed the bundle, if its signature was verified
+	FactRefs      []string     // input.facts keys the policy references
+	ReadsAllFacts bool         // The policy may read any fact, e.g. by iterating input.facts
+	InputSchema   *InputSchema // Validates inputs before evaluation, if the policy ships one
 }
 
-var _ gate.PolicyBundle = (*OpaPolicyBundle)(nil)
+var (
+	_ gate.SignedPolicyBundle = (*OpaPolicyBundle)(nil)
+	_ gate.SchemaPolicyBundle = (*OpaPolicyBundle)(nil)
+)
 
 // ID implements gate.PolicyBundle
 func (b *OpaPolicyBundle) ID() string {
@@ -28,6 +38,19 @@ func (b *OpaPolicyBundle) Data() []byte {
 	return b.BundleData
 }
 
+// SigningKeyID implements gate.SignedPolicyBundle
+func (b *OpaPolicyBundle) SigningKeyID() string {
+	return b.KeyID
+}
+
+// ValidateInput implements gate.SchemaPolicyBundle
+func (b *OpaPolicyBundle) ValidateInput(ctx context.Context, input map[string]any) error {
+	if b.InputSchema == nil {
+		return nil
+	}
+	return b.InputSchema.Validate(ctx, input)
+}
+
 // Engine implements gate.PolicyEngine using OPA
 type Engine struct{}
 
@@ -38,6 +61,23 @@ func NewEngine() *Engine {
 
 // Evaluate implements gate.PolicyEngine
 func (e *Engine) Evaluate(ctx context.Context, policy gate.PolicyBundle, input map[string]any) (gate.Decision, error) {
+	policySHA := metrics.PolicyLabel(policy.ID())
+	timer := prometheus.NewTimer(metrics.PolicyEvalLatency.WithLabelValues(policySHA))
+	decision, err := e.evaluate(ctx, policy, input)
+	timer.ObserveDuration()
+
+	result := string(gate.OutcomeAllow)
+	switch {
+	case err != nil:
+		result = string(gate.OutcomeError)
+	case !decision.Allow:
+		result = string(gate.OutcomeDeny)
+	}
+	metrics.PolicyEvaluations.WithLabelValues(policySHA, result).Inc()
+	return decision, err
+}
+
+func (e *Engine) evaluate(ctx context.Context, policy gate.PolicyBundle, input map[string]any) (gate.Decision, error) {
 	opaBundle, ok := policy.(*OpaPolicyBundle)
 	if !ok {
 		return gate.Decision{}, fmt.Errorf("%w: invalid policy bundle type: %T", gate.ErrPolicyEvaluation, policy)
@@ -78,5 +118,6 @@ func (e *Engine) Evaluate(ctx context.Context, policy gate.PolicyBundle, input m
 		return gate.Decision{}, fmt.Errorf("%w: policy result set is empty or malformed", gate.ErrPolicyEvaluation)
 	}
 
+	decision.UsedFacts = opaBundle.usedFacts(input)
 	return decision, nil
 }
@@ -0,0 +1,99 @@
+package opa
+
+import (
+	"sort"
+
+	"github.com/open-policy-agent/opa/v1/ast"
+)
+
+// factRefs statically finds the input.facts keys referenced by compiled modules. Reading a
+// fact's metadata under input.meta.facts counts as referencing the fact.
+// all is true if some reference could read any fact, e.g. iterating input.facts,
+// indexing it with a variable, or using input itself as a value.
+func factRefs(modules map[string]*ast.Module) (refs []string, all bool) {
+	seen := map[string]bool{}
+	for _, module := range modules {
+		ast.WalkRefs(module, func(ref ast.Ref) bool {
+			if !ref.HasPrefix(ast.InputRootRef) {
+				return false
+			}
+			if len(ref) < 2 {
+				all = true
+				return false
+			}
+			section, ok := ref[1].Value.(ast.String)
+			if !ok {
+				all = true
+				return false
+			}
+			// The fact ID follows input.facts or input.meta.facts
+			key := 2
+			switch section {
+			case "facts":
+			case "meta":
+				if len(ref) < 3 {
+					all = true
+					return false
+				}
+				sub, ok := ref[2].Value.(ast.String)
+				if !ok {
+					all = true
+					return false
+				}
+				if sub != "facts" {
+					return false
+				}
+				key = 3
+			default:
+				return false
+			}
+			if len(ref) <= key {
+				all = true
+				return false
+			}
+			fact, ok := ref[key].Value.(ast.String)
+			if !ok {
+				all = true
+				return false
+			}
+			seen[string(fact)] = true
+			return false
+		})
+	}
+
+	for fact := range seen {
+		refs = append(refs, fact)
+	}
+	sort.Strings(refs)
+	return refs, all
+}
+
+// analyzeFacts compiles modules on their own to resolve imports and aliases, then
+// records which facts they reference. A module set that fails to compile is treated
+// as reading every fact.
+func analyzeFacts(modules map[string]*ast.Module) (refs []string, all bool) {
+	compiler := ast.NewCompiler()
+	compiler.Compile(modules)
+	if compiler.Failed() {
+		return nil, true
+	}
+	return factRefs(compiler.Modules)
+}
+
+// usedFacts returns the keys of input.facts that the policy can read, sorted.
+func (b *OpaPolicyBundle) usedFacts(input map[string]any) []string {
+	facts, _ := input["facts"].(map[string]any)
+	referenced := make(map[string]bool, len(b.FactRefs))
+	for _, fact := range b.FactRefs {
+		referenced[fact] = true
+	}
+
+	used := []string{}
+	for fact := range facts {
+		if b.ReadsAllFacts || referenced[fact] {
+			used = append(used, fact)
+		}
+	}
+	sort.Strings(used)
+	return used
+}
@@ -0,0 +1,149 @@
+package opa
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsedFacts(t *testing.T) {
+	ctx := context.Background()
+	input := map[string]any{
+		"request": map[string]any{"requested_count": 10},
+		"facts":   map[string]any{"pending_delta": 100, "max_pending_allowed": 500, "error_rate": 0.01},
+	}
+
+	tests := []struct {
+		name     string
+		policy   string
+		wantRefs []string
+		wantAll  bool
+		wantUsed []string
+	}{
+		{
+			name: "Direct references",
+			policy: `package gate
+
+default allow := false
+
+allow if input.facts.pending_delta + input.request.requested_count <= input.facts.max_pending_allowed
+
+response := {"allow": allow, "deny_reasons": []}`,
+			wantRefs: []string{"max_pending_allowed", "pending_delta"},
+			wantUsed: []string{"max_pending_allowed", "pending_delta"},
+		},
+		{
+			name: "Import alias",
+			policy: `package gate
+
+import input.facts as f
+
+default allow := false
+
+allow if f.error_rate < 0.05
+
+response := {"allow": allow, "deny_reasons": []}`,
+			wantRefs: []string{"error_rate"},
+			wantUsed: []string{"error_rate"},
+		},
+		{
+			name: "Referenced fact not collected",
+			policy: `package gate
+
+default allow := false
+
+allow if input.facts.cpu_load < 0.9
+
+response := {"allow": allow, "deny_reasons": []}`,
+			wantRefs: []string{"cpu_load"},
+			wantUsed: []string{},
+		},
+		{
+			name: "Fact metadata",
+			policy: `package gate
+
+default allow := false
+
+allow if {
+    input.meta.facts.pending_delta.age_ns < time.parse_duration_ns("20s")
+    input.meta.timestamp != ""
+}
+
+response := {"allow": allow, "deny_reasons": []}`,
+			wantRefs: []string{"pending_delta"},
+			wantUsed: []string{"pending_delta"},
+		},
+		{
+			name: "Iterating fact metadata",
+			policy: `package gate
+
+default allow := false
+
+allow if every _, m in input.meta.facts { not m.cache_hit }
+
+response := {"allow": allow, "deny_reasons": []}`,
+			wantAll:  true,
+			wantUsed: []string{"error_rate", "max_pending_allowed", "pending_delta"},
+		},
+		{
+			name: "Iterating facts",
+			policy: `package gate
+
+default allow := false
+
+allow if every _, v in input.facts { v != null }
+
+response := {"allow": allow, "deny_reasons": []}`,
+			wantAll:  true,
+			wantUsed: []string{"error_rate", "max_pending_allowed", "pending_delta"},
+		},
+		{
+			name: "Dynamic fact key",
+			policy: `package gate
+
+default allow := false
+
+allow if input.facts[input.request.fact] < 10
+
+response := {"allow": allow, "deny_reasons": []}`,
+			wantAll:  true,
+			wantUsed: []string{"error_rate", "max_pending_allowed", "pending_delta"},
+		},
+		{
+			name: "Whole input",
+			policy: `package gate
+
+default allow := false
+
+allow if count(input) > 0
+
+response := {"allow": allow, "deny_reasons": []}`,
+			wantAll:  true,
+			wantUsed: []string{"error_rate", "max_pending_allowed", "pending_delta"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := Compile(ctx, "main.rego", []byte(tc.policy), "data.gate.response")
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantRefs, b.FactRefs)
+			assert.Equal(t, tc.wantAll, b.ReadsAllFacts)
+
+			decision, err := NewEngine().Evaluate(ctx, b, input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantUsed, decision.UsedFacts)
+		})
+	}
+}
+
+func TestUsedFacts_Bundle(t *testing.T) {
+	b, err := LoadBundleDir(context.Background(), writeBundleDir(t, testBundleFiles), "data.gate.response", nil)
+	require.NoError(t, err)
+
+	// lib.within reads only its arguments; data.limits is not a fact
+	assert.Equal(t, []string{"pending_delta"}, b.FactRefs)
+	assert.False(t, b.ReadsAllFacts)
+}
@@ -0,0 +1,134 @@
+package opa
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+
+	"github.com/open-policy-agent/opa/v1/rego"
+	"github.com/open-policy-agent/opa/v1/storage/inmem"
+	"github.com/open-policy-agent/opa/v1/util"
+
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+// InputSchemaFile is the JSON Schema a policy ships for its input: at the root of an OPA
+// bundle, or beside a single Rego file.
+const InputSchemaFile = "input.json"
+
+// ContentSHA returns the hex SHA-256 of a policy released with the input schema beside it,
+// so that the ID of a bundle built from them changes when either does.
+func ContentSHA(policy, schema []byte) string {
+	h := sha256.New()
+	h.Write(policy)
+	h.Write([]byte{0})
+	h.Write(schema)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// InputSchema validates policy inputs against a JSON Schema, using OPA's json.match_schema.
+type InputSchema struct {
+	Raw   []byte
+	match rego.PreparedEvalQuery
+}
+
+// NewInputSchema parses and checks a JSON Schema. Errors wrap gate.ErrPolicyLoad.
+func NewInputSchema(ctx context.Context, data []byte) (*InputSchema, error) {
+	var schema any
+	if err := util.UnmarshalJSON(data, &schema); err != nil {
+		return nil, fmt.Errorf("%w: parsing input schema: %v", gate.ErrPolicyLoad, err)
+	}
+	store := inmem.NewFromObject(map[string]any{"schema": schema})
+
+	rs, err := rego.New(rego.Query("json.verify_schema(data.schema)"), rego.Store(store)).Eval(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: checking input schema: %v", gate.ErrPolicyLoad, err)
+	}
+	if verified, _ := rs[0].Expressions[0].Value.([]any); len(verified) != 2 || verified[0] != true {
+		return nil, fmt.Errorf("%w: invalid input schema: %v", gate.ErrPolicyLoad, verified[1])
+	}
+
+	match, err := rego.New(rego.Query("json.match_schema(input, data.schema)"), rego.Store(store)).PrepareForEval(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: preparing input schema: %v", gate.ErrPolicyLoad, err)
+	}
+	return &InputSchema{Raw: data, match: match}, nil
+}
+
+// Validate checks input against the schema. It returns a *gate.InputSchemaError listing
+// every violation, or an error wrapping gate.ErrPolicyEvaluation if it could not check.
+func (s *InputSchema) Validate(ctx context.Context, input map[string]any) error {
+	rs, err := s.match.Eval(ctx, rego.EvalInput(input))
+	if err != nil {
+		return fmt.Errorf("%w: validating input: %v", gate.ErrPolicyEvaluation, err)
+	}
+	result, _ := rs[0].Expressions[0].Value.([]any)
+	if len(result) != 2 {
+		return fmt.Errorf("%w: validating input: unexpected result %v", gate.ErrPolicyEvaluation, rs[0].Expressions[0].Value)
+	}
+	if result[0] == true {
+		return nil
+	}
+
+	errs, _ := result[1].([]any)
+	violations := make([]gate.SchemaViolation, 0, len(errs))
+	for _, e := range errs {
+		fields, _ := e.(map[string]any)
+		field, _ := fields["field"].(string)
+		desc, _ := fields["desc"].(string)
+		violations = append(violations, gate.SchemaViolation{Field: field, Message: desc})
+	}
+	return &gate.InputSchemaError{Violations: violations}
+}
+
+// readArchiveSchema returns the InputSchemaFile at the root of a bundle tarball, or nil if
+// it has none.
+func readArchiveSchema(data []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	tr := tar.NewReader(zr)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if path.Clean("/"+hdr.Name) == "/"+InputSchemaFile {
+			return io.ReadAll(tr)
+		}
+	}
+}
+
+// readFileSchema returns the content of the schema file at path, or nil if it does not exist.
+func readFileSchema(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// attachSchema sets the bundle's input schema from data, if any.
+func (b *OpaPolicyBundle) attachSchema(ctx context.Context, data []byte) error {
+	if data == nil {
+		return nil
+	}
+	schema, err := NewInputSchema(ctx, data)
+	if err != nil {
+		return err
+	}
+	b.InputSchema = schema
+	return nil
+}
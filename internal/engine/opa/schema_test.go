@@ -0,0 +1,96 @@
+package opa
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+const testInputSchema = `{
+	"type": "object",
+	"properties": {
+		"facts": {
+			"type": "object",
+			"properties": {
+				"pending_delta": {"type": "integer", "minimum": 0},
+				"max_pending_allowed": {"type": "integer", "minimum": 0, "maximum": 100000}
+			},
+			"required": ["pending_delta", "max_pending_allowed"]
+		}
+	},
+	"required": ["facts"]
+}`
+
+func TestInputSchema(t *testing.T) {
+	ctx := context.Background()
+	schema, err := NewInputSchema(ctx, []byte(testInputSchema))
+	require.NoError(t, err)
+
+	t.Run("Valid input", func(t *testing.T) {
+		assert.NoError(t, schema.Validate(ctx, map[string]any{
+			"facts": map[string]any{"pending_delta": 100, "max_pending_allowed": 500},
+		}))
+	})
+
+	t.Run("Every violation is listed", func(t *testing.T) {
+		err := schema.Validate(ctx, map[string]any{
+			"facts": map[string]any{"pending_delta": -1, "max_pending_allowed": "500"},
+		})
+		var schemaErr *gate.InputSchemaError
+		require.True(t, errors.As(err, &schemaErr), err)
+		assert.ErrorIs(t, err, gate.ErrInputSchema)
+		assert.ElementsMatch(t, []gate.SchemaViolation{
+			{Field: "facts.pending_delta", Message: "Must be greater than or equal to 0"},
+			{Field: "facts.max_pending_allowed", Message: "Invalid type. Expected: integer, given: string"},
+		}, schemaErr.Violations)
+	})
+
+	t.Run("Missing fact", func(t *testing.T) {
+		err := schema.Validate(ctx, map[string]any{"facts": map[string]any{"pending_delta": 100}})
+		var schemaErr *gate.InputSchemaError
+		require.True(t, errors.As(err, &schemaErr), err)
+		assert.Equal(t, []gate.SchemaViolation{{Field: "facts", Message: "max_pending_allowed is required"}}, schemaErr.Violations)
+	})
+
+	t.Run("Invalid schema", func(t *testing.T) {
+		_, err := NewInputSchema(ctx, []byte(`{"type": "no-such-type"}`))
+		assert.ErrorIs(t, err, gate.ErrPolicyLoad)
+
+		_, err = NewInputSchema(ctx, []byte(`not json`))
+		assert.ErrorIs(t, err, gate.ErrPolicyLoad)
+	})
+}
+
+func TestLoadBundle_InputSchema(t *testing.T) {
+	ctx := context.Background()
+	files := map[string]string{InputSchemaFile: testInputSchema}
+	for name, content := range testBundleFiles {
+		files[name] = content
+	}
+	input := map[string]any{"facts": map[string]any{"pending_delta": "100"}}
+
+	dir, err := LoadBundleDir(ctx, writeBundleDir(t, files), "data.gate.response", nil)
+	require.NoError(t, err)
+	require.NotNil(t, dir.InputSchema)
+	assert.ErrorIs(t, dir.ValidateInput(ctx, input), gate.ErrInputSchema)
+
+	archive, err := LoadBundleArchive(ctx, bundleArchive(t, files), "data.gate.response", nil)
+	require.NoError(t, err)
+	require.NotNil(t, archive.InputSchema)
+	assert.ErrorIs(t, archive.ValidateInput(ctx, input), gate.ErrInputSchema)
+
+	// Without a schema, any input is accepted
+	plain, err := LoadBundleDir(ctx, writeBundleDir(t, testBundleFiles), "data.gate.response", nil)
+	require.NoError(t, err)
+	assert.Nil(t, plain.InputSchema)
+	assert.NoError(t, plain.ValidateInput(ctx, input))
+
+	files[InputSchemaFile] = `{"type": 5}`
+	_, err = LoadBundleDir(ctx, writeBundleDir(t, files), "data.gate.response", nil)
+	assert.ErrorIs(t, err, gate.ErrPolicyLoad)
+}
@@ -0,0 +1,261 @@
+package opa
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/open-policy-agent/opa/v1/bundle"
+	"github.com/open-policy-agent/opa/v1/keys"
+
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+// AlgEdDSA is the JWS algorithm for ed25519 signatures.
+const AlgEdDSA = "EdDSA"
+
+// Ed25519Plugin is the .signatures.json "plugin" for bundles signed with ed25519.
+// OPA's built-in verifier handles RSA and ECDSA signatures but not EdDSA, so ed25519
+// bundles are signed and verified by this package under this ID.
+const Ed25519Plugin = "ed25519"
+
+func init() {
+	_ = bundle.RegisterVerifier(Ed25519Plugin, &ed25519Verifier{})
+	_ = bundle.RegisterSigner(Ed25519Plugin, &ed25519Signer{})
+}
+
+// VerificationKey is a public key trusted to sign policy bundles.
+type VerificationKey struct {
+	Algorithm string // AlgEdDSA, or an OPA algorithm such as "RS256" or "PS256"
+	PublicKey string // PEM-encoded
+}
+
+// NewVerificationConfig builds the bundle verification config for a set of trusted keys.
+// If keyID is set, bundles must be signed with that key; if scope is set, signatures must
+// carry that scope claim. Errors wrap gate.ErrConfigLoad.
+func NewVerificationConfig(trusted map[string]VerificationKey, keyID, scope string) (*bundle.VerificationConfig, error) {
+	if len(trusted) == 0 {
+		return nil, fmt.Errorf("%w: bundle verification needs at least one key", gate.ErrConfigLoad)
+	}
+
+	publicKeys := make(map[string]*bundle.KeyConfig, len(trusted))
+	for id, k := range trusted {
+		switch {
+		case k.Algorithm == AlgEdDSA:
+			if _, err := parseEd25519PublicKey(k.PublicKey); err != nil {
+				return nil, fmt.Errorf("%w: verification key %s: %v", gate.ErrConfigLoad, id, err)
+			}
+		case strings.HasPrefix(k.Algorithm, "HS") || !keys.IsSupportedAlgorithm(k.Algorithm):
+			return nil, fmt.Errorf("%w: verification key %s: unsupported algorithm %q", gate.ErrConfigLoad, id, k.Algorithm)
+		}
+		publicKeys[id] = &bundle.KeyConfig{Key: k.PublicKey, Algorithm: k.Algorithm, Scope: scope}
+	}
+
+	vc := bundle.NewVerificationConfig(publicKeys, keyID, scope, nil)
+	if err := vc.ValidateAndInjectDefaults(publicKeys); err != nil {
+		return nil, fmt.Errorf("%w: %v", gate.ErrConfigLoad, err)
+	}
+	return vc, nil
+}
+
+// SignBundle signs every module and data file in b with a PEM-encoded private key,
+// replacing any existing signature. Ed25519 keys are signed under Ed25519Plugin.
+func SignBundle(b *bundle.Bundle, keyID, algorithm, privateKey string) error {
+	sc := bundle.NewSigningConfig(privateKey, algorithm, "")
+	if algorithm == AlgEdDSA {
+		sc = sc.WithPlugin(Ed25519Plugin)
+	}
+	b.Signatures = bundle.SignaturesConfig{}
+	return b.GenerateSignature(sc, keyID, false)
+}
+
+// readBundle reads a bundle, verifying its signature if vc is set.
+// It returns the ID of the key that signed the bundle, or "" if vc is nil.
+func readBundle(r *bundle.Reader, vc *bundle.VerificationConfig) (bundle.Bundle, string, error) {
+	if vc == nil {
+		b, err := r.WithSkipBundleVerification(true).Read()
+		return b, "", err
+	}
+
+	b, err := r.WithBundleVerificationConfig(vc).Read()
+	if err != nil {
+		return b, "", err
+	}
+	// OPA only insists on .signatures.json when a key ID is pinned
+	if len(b.Signatures.Signatures) == 0 {
+		return b, "", errors.New("bundle is not signed")
+	}
+
+	token, err := parseToken(b.Signatures.Signatures[0])
+	if err != nil {
+		return b, "", err
+	}
+	return b, token.keyID(vc), nil
+}
+
+// signedToken is a decoded (but not yet verified) bundle signature JWT.
+type signedToken struct {
+	header struct {
+		Algorithm string `json:"alg"`
+		KeyID     string `json:"kid"`
+	}
+	payload      bundle.DecodedSignature
+	signingInput string
+	signature    []byte
+}
+
+func parseToken(token string) (*signedToken, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed bundle signature")
+	}
+
+	var t signedToken
+	for i, v := range []any{&t.header, &t.payload} {
+		raw, err := base64.RawURLEncoding.DecodeString(parts[i])
+		if err != nil {
+			return nil, fmt.Errorf("decoding bundle signature: %w", err)
+		}
+		if err := json.Unmarshal(raw, v); err != nil {
+			return nil, fmt.Errorf("decoding bundle signature: %w", err)
+		}
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("decoding bundle signature: %w", err)
+	}
+
+	t.signingInput = parts[0] + "." + parts[1]
+	t.signature = signature
+	return &t, nil
+}
+
+// keyID resolves the signing key the same way OPA does: the configured key ID,
+// then the JWT "kid" header, then the deprecated "keyid" claim.
+func (t *signedToken) keyID(vc *bundle.VerificationConfig) string {
+	switch {
+	case vc.KeyID != "":
+		return vc.KeyID
+	case t.header.KeyID != "":
+		return t.header.KeyID
+	default:
+		return t.payload.KeyID
+	}
+}
+
+// ed25519Verifier implements bundle.Verifier for EdDSA signatures.
+type ed25519Verifier struct{}
+
+// VerifyBundleSignature implements bundle.Verifier
+func (*ed25519Verifier) VerifyBundleSignature(sc bundle.SignaturesConfig, vc *bundle.VerificationConfig) (map[string]bundle.FileInfo, error) {
+	files := make(map[string]bundle.FileInfo)
+	if len(sc.Signatures) != 1 {
+		return files, errors.New(".signatures.json: expected exactly one JWT")
+	}
+
+	token, err := parseToken(sc.Signatures[0])
+	if err != nil {
+		return files, err
+	}
+	keyID := token.keyID(vc)
+	if keyID == "" {
+		return files, errors.New("verification key ID is empty")
+	}
+	kc, err := vc.GetPublicKey(keyID)
+	if err != nil {
+		return files, err
+	}
+	// Never let the token choose the algorithm
+	if kc.Algorithm != AlgEdDSA || token.header.Algorithm != AlgEdDSA {
+		return files, fmt.Errorf("key %s does not accept %s signatures", keyID, token.header.Algorithm)
+	}
+
+	pub, err := parseEd25519PublicKey(kc.Key)
+	if err != nil {
+		return files, err
+	}
+	if !ed25519.Verify(pub, []byte(token.signingInput), token.signature) {
+		return files, errors.New("failed to verify bundle signature")
+	}
+
+	scope := vc.Scope
+	if scope == "" {
+		scope = kc.Scope
+	}
+	if token.payload.Scope != scope {
+		return files, errors.New("scope mismatch")
+	}
+
+	for _, f := range token.payload.Files {
+		files[f.Name] = f
+	}
+	return files, nil
+}
+
+// ed25519Signer implements bundle.Signer for EdDSA signatures.
+type ed25519Signer struct{}
+
+// GenerateSignedToken implements bundle.Signer
+func (*ed25519Signer) GenerateSignedToken(files []bundle.FileInfo, sc *bundle.SigningConfig, keyID string) (string, error) {
+	block, _ := pem.Decode([]byte(sc.Key))
+	if block == nil {
+		return "", errors.New("signing key is not PEM-encoded")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("parsing signing key: %w", err)
+	}
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return "", fmt.Errorf("signing key is %T, not ed25519", key)
+	}
+
+	header := map[string]string{"alg": AlgEdDSA, "typ": "JWT"}
+	if keyID != "" {
+		header["kid"] = keyID
+	}
+	claims := map[string]any{"files": files}
+	if sc.ClaimsPath != "" {
+		extra, err := sc.GetClaims()
+		if err != nil {
+			return "", err
+		}
+		for k, v := range extra {
+			claims[k] = v
+		}
+	} else if keyID != "" {
+		claims["keyid"] = keyID
+	}
+
+	var parts []string
+	for _, v := range []any{header, claims} {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, base64.RawURLEncoding.EncodeToString(raw))
+	}
+	signingInput := strings.Join(parts, ".")
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(ed25519.Sign(priv, []byte(signingInput))), nil
+}
+
+func parseEd25519PublicKey(key string) (ed25519.PublicKey, error) {
+	block, _ := pem.Decode([]byte(key))
+	if block == nil {
+		return nil, errors.New("public key is not PEM-encoded")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing public key: %w", err)
+	}
+	edPub, ok := pub.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key is %T, not ed25519", pub)
+	}
+	return edPub, nil
+}
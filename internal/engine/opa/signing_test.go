@@ -0,0 +1,191 @@
+package opa
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/open-policy-agent/opa/v1/bundle"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+type testKey struct {
+	public, private string
+}
+
+func newEd25519Key(t *testing.T) testKey {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return encodeKeys(t, pub, priv)
+}
+
+func newRSAKey(t *testing.T) testKey {
+	t.Helper()
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return encodeKeys(t, &priv.PublicKey, priv)
+}
+
+func encodeKeys(t *testing.T, pub, priv any) testKey {
+	t.Helper()
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+	return testKey{
+		public:  string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})),
+		private: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER})),
+	}
+}
+
+// signedBundle reads files as a bundle and signs it, returning the bundle for re-writing.
+func signedBundle(t *testing.T, files map[string]string, keyID, alg string, key testKey) bundle.Bundle {
+	t.Helper()
+	b, err := bundle.NewReader(bytes.NewReader(bundleArchive(t, files))).Read()
+	require.NoError(t, err)
+	require.NoError(t, SignBundle(&b, keyID, alg, key.private))
+	return b
+}
+
+func writeArchive(t *testing.T, b bundle.Bundle) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, bundle.NewWriter(&buf).Write(b))
+	return buf.Bytes()
+}
+
+func verificationConfig(t *testing.T, keys map[string]VerificationKey) *bundle.VerificationConfig {
+	t.Helper()
+	vc, err := NewVerificationConfig(keys, "", "")
+	require.NoError(t, err)
+	return vc
+}
+
+func TestSignedBundles(t *testing.T) {
+	ctx := context.Background()
+	edKey, rsaKey := newEd25519Key(t), newRSAKey(t)
+	vc := verificationConfig(t, map[string]VerificationKey{
+		"release-ed25519": {Algorithm: AlgEdDSA, PublicKey: edKey.public},
+		"release-rsa":     {Algorithm: "RS256", PublicKey: rsaKey.public},
+	})
+
+	for _, tc := range []struct {
+		name, keyID, alg string
+		key              testKey
+	}{
+		{"ed25519", "release-ed25519", AlgEdDSA, edKey},
+		{"RSA", "release-rsa", "RS256", rsaKey},
+	} {
+		t.Run("Verify "+tc.name+" signature", func(t *testing.T) {
+			archive := writeArchive(t, signedBundle(t, testBundleFiles, tc.keyID, tc.alg, tc.key))
+
+			b, err := CompilePolicy(ctx, "policy.tar.gz", archive, "data.gate.response", vc)
+			require.NoError(t, err)
+			assert.Equal(t, tc.keyID, b.SigningKeyID())
+			assert.True(t, evaluatePending(t, b, 100))
+		})
+	}
+
+	t.Run("Reject unsigned bundle", func(t *testing.T) {
+		_, err := LoadBundleArchive(ctx, bundleArchive(t, testBundleFiles), "data.gate.response", vc)
+		assert.ErrorIs(t, err, gate.ErrPolicyLoad)
+	})
+
+	t.Run("Reject bare Rego module", func(t *testing.T) {
+		_, err := CompilePolicy(ctx, "main.rego", []byte(testBundleFiles["gate/main.rego"]), "data.gate.response", vc)
+		assert.ErrorIs(t, err, gate.ErrPolicyLoad)
+	})
+
+	t.Run("Reject tampered data", func(t *testing.T) {
+		b := signedBundle(t, testBundleFiles, "release-ed25519", AlgEdDSA, edKey)
+		b.Data["limits"] = map[string]any{"max_pending": 100000}
+
+		_, err := LoadBundleArchive(ctx, writeArchive(t, b), "data.gate.response", vc)
+		assert.ErrorIs(t, err, gate.ErrPolicyLoad)
+	})
+
+	t.Run("Reject untrusted key", func(t *testing.T) {
+		archive := writeArchive(t, signedBundle(t, testBundleFiles, "release-ed25519", AlgEdDSA, newEd25519Key(t)))
+
+		_, err := LoadBundleArchive(ctx, archive, "data.gate.response", vc)
+		assert.ErrorIs(t, err, gate.ErrPolicyLoad)
+	})
+
+	t.Run("Reject key ID not pinned", func(t *testing.T) {
+		pinned, err := NewVerificationConfig(map[string]VerificationKey{
+			"release-ed25519": {Algorithm: AlgEdDSA, PublicKey: edKey.public},
+			"release-rsa":     {Algorithm: "RS256", PublicKey: rsaKey.public},
+		}, "release-rsa", "")
+		require.NoError(t, err)
+		archive := writeArchive(t, signedBundle(t, testBundleFiles, "release-ed25519", AlgEdDSA, edKey))
+
+		_, err = LoadBundleArchive(ctx, archive, "data.gate.response", pinned)
+		assert.ErrorIs(t, err, gate.ErrPolicyLoad)
+	})
+
+	t.Run("Verify signed directory", func(t *testing.T) {
+		// SignBundle signs the merged data document, so the directory keeps it in the root data.json
+		files := map[string]string{"data.json": `{"limits": {"max_pending": 500}}`}
+		for name, content := range testBundleFiles {
+			if name != "limits/data.json" {
+				files[name] = content
+			}
+		}
+		b := signedBundle(t, files, "release-ed25519", AlgEdDSA, edKey)
+		signatures, err := json.Marshal(b.Signatures)
+		require.NoError(t, err)
+		dir := writeBundleDir(t, files)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, ".signatures.json"), signatures, 0o644))
+
+		loaded, err := LoadBundleDir(ctx, dir, "data.gate.response", vc)
+		require.NoError(t, err)
+		assert.Equal(t, "release-ed25519", loaded.SigningKeyID())
+		assert.False(t, evaluatePending(t, loaded, 600))
+
+		// Editing a signed file invalidates the signature
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "data.json"), []byte(`{"limits": {"max_pending": 100000}}`), 0o644))
+		_, err = LoadBundleDir(ctx, dir, "data.gate.response", vc)
+		assert.ErrorIs(t, err, gate.ErrPolicyLoad)
+	})
+
+	t.Run("Signed bundle without verification", func(t *testing.T) {
+		archive := writeArchive(t, signedBundle(t, testBundleFiles, "release-ed25519", AlgEdDSA, edKey))
+
+		b, err := LoadBundleArchive(ctx, archive, "data.gate.response", nil)
+		require.NoError(t, err)
+		assert.Empty(t, b.SigningKeyID())
+	})
+}
+
+func TestNewVerificationConfig_Errors(t *testing.T) {
+	edKey := newEd25519Key(t)
+
+	for name, tc := range map[string]struct {
+		keys  map[string]VerificationKey
+		keyID string
+	}{
+		"No keys":             {keys: nil},
+		"Symmetric algorithm": {keys: map[string]VerificationKey{"k": {Algorithm: "HS256", PublicKey: "secret"}}},
+		"Unknown algorithm":   {keys: map[string]VerificationKey{"k": {Algorithm: "none", PublicKey: edKey.public}}},
+		"Malformed key":       {keys: map[string]VerificationKey{"k": {Algorithm: AlgEdDSA, PublicKey: "not a key"}}},
+		"Wrong key type":      {keys: map[string]VerificationKey{"k": {Algorithm: AlgEdDSA, PublicKey: newRSAKey(t).public}}},
+		"Unknown key ID":      {keys: map[string]VerificationKey{"k": {Algorithm: AlgEdDSA, PublicKey: edKey.public}}, keyID: "other"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewVerificationConfig(tc.keys, tc.keyID, "")
+			assert.ErrorIs(t, err, gate.ErrConfigLoad)
+		})
+	}
+}
@@ -15,6 +15,7 @@ import (
 type Provider struct {
 	factID      string
 	description string
+	schema      gate.Schema // declared type, range and ownership
 	config      *config.AppConfig
 	valueFunc   func(*config.AppConfig) any
 }
@@ -24,7 +25,13 @@ func NewMaxPendingAllowedProvider(cfg *config.AppConfig) *Provider {
 	return &Provider{
 		factID:      "max_pending_allowed",
 		description: "Maximum allowed devices in pending state",
-		config:      cfg,
+		schema: gate.Schema{
+			Type:    gate.TypeInteger,
+			Unit:    "devices",
+			Minimum: gate.Bound(0),
+			Owner:   "config",
+		},
+		config: cfg,
 		valueFunc: func(cfg *config.AppConfig) any {
 			return cfg.FactProviders.MaxPendingAllowed
 		},
@@ -36,17 +43,24 @@ func NewProvider(factID, description string, config *config.AppConfig, valueFunc
 	return &Provider{
 		factID:      factID,
 		description: description,
+		schema:      gate.Schema{Owner: "config"},
 		config:      config,
 		valueFunc:   valueFunc,
 	}
 }
 
+// WithSchema declares the type, range, criticality, staleness and owner of the fact. The
+// schema's ID and Description are ignored.
+func (p *Provider) WithSchema(schema gate.Schema) *Provider {
+	p.schema = schema
+	return p
+}
+
 // Describe implements gate.FactProvider.
 func (p *Provider) Describe() gate.Schema {
-	return gate.Schema{
-		ID:          p.factID,
-		Description: p.description,
-	}
+	schema := p.schema
+	schema.ID, schema.Description = p.factID, p.description
+	return schema
 }
 
 // Collect implements gate.FactProvider.
@@ -54,8 +68,17 @@ func (p *Provider) Collect(ctx context.Context, _, _ string) (gate.Fact, error)
 	timer := prometheus.NewTimer(metrics.FactCollectLatency.WithLabelValues(p.factID))
 	defer timer.ObserveDuration()
 
+	// Prefer the configuration paired with the policy being evaluated, so a hot reload
+	// never mixes a new rule with an old limit
+	cfg := p.config
+	if release, ok := gate.ReleaseFromContext(ctx); ok {
+		if releaseCfg, ok := release.Config.(*config.AppConfig); ok {
+			cfg = releaseCfg
+		}
+	}
+
 	// Configuration facts are always fresh (current time)
 	// and we don't need to make external calls
-	value := p.valueFunc(p.config)
+	value := p.valueFunc(cfg)
 	return gate.NewFact(p.factID, value, time.Now()), nil
 }
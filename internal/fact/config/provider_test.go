@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/asimihsan/planning_engine/internal/config"
+	"github.com/asimihsan/planning_engine/pkg/gate"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -24,6 +25,8 @@ func TestConfigProvider(t *testing.T) {
 	schema := provider.Describe()
 	assert.Equal(t, "max_pending_allowed", schema.ID)
 	assert.Equal(t, "Maximum allowed devices in pending state", schema.Description)
+	assert.Equal(t, gate.TypeInteger, schema.Type)
+	assert.NoError(t, schema.Validate(800))
 
 	// Test that the provider returns the correct value
 	fact, err := provider.Collect(context.Background(), "test-deployment", "test-stage")
@@ -72,3 +75,20 @@ func TestCustomConfigProvider(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, ":9100", fact.Value())
 }
+
+func TestConfigProviderPrefersReleaseConfig(t *testing.T) {
+	bootstrap := &config.AppConfig{FactProviders: &config.FactProviders{MaxPendingAllowed: 500}}
+	released := &config.AppConfig{FactProviders: &config.FactProviders{MaxPendingAllowed: 250}}
+	provider := NewMaxPendingAllowedProvider(bootstrap)
+
+	// Without a release on the context, the bootstrap config is used
+	fact, err := provider.Collect(context.Background(), "test-deployment", "test-stage")
+	assert.NoError(t, err)
+	assert.Equal(t, 500, fact.Value())
+
+	// With a release, its config is used so the value matches the policy being evaluated
+	ctx := gate.WithRelease(context.Background(), &gate.Release{Config: released})
+	fact, err = provider.Collect(ctx, "test-deployment", "test-stage")
+	assert.NoError(t, err)
+	assert.Equal(t, 250, fact.Value())
+}
@@ -20,9 +20,10 @@ type Provider struct {
 	httpClient  *http.Client
 	cacheTTL    time.Duration
 	description string
+	schema      gate.Schema
 
 	mu          sync.RWMutex
-	cachedValue gate.Fact
+	cachedValue *gate.BasicFact
 	expiry      time.Time
 }
 
@@ -34,15 +35,23 @@ func NewProvider(factID, baseURL string, cacheTTL time.Duration, description str
 		httpClient:  &http.Client{Timeout: 5 * time.Second},
 		cacheTTL:    cacheTTL,
 		description: description,
+		schema:      gate.Schema{Type: gate.TypeInteger, Owner: "levelsrv"},
 	}
 }
 
+// WithSchema declares the type, range, criticality, staleness and owner of the fact, in
+// place of the default of an integer owned by levelsrv. The schema's ID and Description
+// are ignored.
+func (p *Provider) WithSchema(schema gate.Schema) *Provider {
+	p.schema = schema
+	return p
+}
+
 // Describe implements gate.FactProvider.
 func (p *Provider) Describe() gate.Schema {
-	return gate.Schema{
-		ID:          p.factID,
-		Description: p.description,
-	}
+	schema := p.schema
+	schema.ID, schema.Description = p.factID, p.description
+	return schema
 }
 
 // Collect implements gate.FactProvider.
@@ -53,8 +62,9 @@ func (p *Provider) Collect(ctx context.Context, deploymentID, stage string) (gat
 	// Check cache first
 	p.mu.RLock()
 	if p.cachedValue != nil && time.Now().Before(p.expiry) {
-		cachedValue := p.cachedValue
+		cachedValue := *p.cachedValue
 		p.mu.RUnlock()
+		cachedValue.FactCacheHit = true
 		return cachedValue, nil
 	}
 	p.mu.RUnlock()
@@ -96,10 +106,10 @@ func (p *Provider) Collect(ctx context.Context, deploymentID, stage string) (gat
 
 	// Create fact and update cache
 	now := time.Now()
-	fact := gate.NewFact(p.factID, result.Value, now)
+	fact := gate.BasicFact{FactID: p.factID, FactValue: result.Value, FactTime: now, FactSource: url}
 
 	p.mu.Lock()
-	p.cachedValue = fact
+	p.cachedValue = &fact
 	p.expiry = now.Add(p.cacheTTL)
 	p.mu.Unlock()
 
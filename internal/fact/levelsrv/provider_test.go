@@ -103,6 +103,9 @@ func TestProvider_CacheExpiry(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, 1, fact2.Value()) // Same value from cache
 	assert.Equal(t, fact1.Timestamp(), fact2.Timestamp())
+	assert.False(t, fact1.(gate.SourcedFact).CacheHit())
+	assert.True(t, fact2.(gate.SourcedFact).CacheHit())
+	assert.Equal(t, server.URL+"/api/deployments/test-deployment/stages/test-stage/metrics/test_fact", fact2.(gate.SourcedFact).Source())
 
 	// Wait for cache to expire
 	time.Sleep(100 * time.Millisecond)
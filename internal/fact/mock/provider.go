@@ -14,6 +14,7 @@ type Provider struct {
 	Timestamp   time.Time
 	Err         error
 	Description string
+	Schema      gate.Schema // declared type, range and so on; ID and Description are ignored
 }
 
 var _ gate.FactProvider = (*Provider)(nil)
@@ -44,6 +45,12 @@ func (p *Provider) WithError(err error) *Provider {
 	return p
 }
 
+// WithSchema sets the schema the provider declares.
+func (p *Provider) WithSchema(schema gate.Schema) *Provider {
+	p.Schema = schema
+	return p
+}
+
 // WithTimestamp sets a specific timestamp for the fact.
 func (p *Provider) WithTimestamp(t time.Time) *Provider {
 	p.Timestamp = t
@@ -52,10 +59,9 @@ func (p *Provider) WithTimestamp(t time.Time) *Provider {
 
 // Describe implements gate.FactProvider.
 func (p *Provider) Describe() gate.Schema {
-	return gate.Schema{
-		ID:          p.FactID,
-		Description: p.Description,
-	}
+	schema := p.Schema
+	schema.ID, schema.Description = p.FactID, p.Description
+	return schema
 }
 
 // Collect implements gate.FactProvider.
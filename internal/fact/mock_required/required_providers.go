@@ -18,6 +18,10 @@ func (p *PendingDeltaProvider) Describe() gate.Schema {
 	return gate.Schema{
 		ID:          "pending_delta",
 		Description: "Number of devices newly targeted",
+		Type:        gate.TypeInteger,
+		Unit:        "devices",
+		Minimum:     gate.Bound(0),
+		Owner:       "levelsrv",
 	}
 }
 
@@ -36,6 +40,10 @@ func (p *MaxPendingProvider) Describe() gate.Schema {
 	return gate.Schema{
 		ID:          "max_pending_allowed",
 		Description: "Maximum allowed devices in pending state",
+		Type:        gate.TypeInteger,
+		Unit:        "devices",
+		Minimum:     gate.Bound(0),
+		Owner:       "config",
 	}
 }
 
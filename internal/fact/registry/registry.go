@@ -0,0 +1,51 @@
+// Package registry assembles the fact providers the service is configured with, so that the
+// service and the command line tools collect the same facts.
+package registry
+
+import (
+	"time"
+
+	"github.com/apple/pkl-go/pkl"
+
+	"github.com/asimihsan/planning_engine/internal/config"
+	configfact "github.com/asimihsan/planning_engine/internal/fact/config"
+	"github.com/asimihsan/planning_engine/internal/fact/mock_required"
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+// New creates a registry holding the fact providers configured by cfg.
+func New(cfg *config.AppConfig) *gate.FactRegistry {
+	registry := gate.NewFactRegistry()
+	registry.Register(&mock_required.PendingDeltaProvider{})
+	registry.Register(configfact.NewMaxPendingAllowedProvider(cfg))
+	return registry
+}
+
+// SnapshotOpts returns the staleness and timeout limits configured by cfg.
+func SnapshotOpts(cfg *config.AppConfig) gate.SnapshotOpts {
+	fp := cfg.FactProviders
+	opts := gate.SnapshotOpts{
+		MaxAge:             duration(fp.MaxStaleness),
+		PerProviderTimeout: duration(fp.ProviderTimeout),
+		MaxAgeCap:          duration(fp.MaxStalenessCap),
+		TimeoutCap:         duration(fp.ProviderTimeoutCap),
+	}
+	for id, budget := range fp.Facts {
+		if budget == nil {
+			continue
+		}
+		if opts.Facts == nil {
+			opts.Facts = make(map[string]gate.FactBudget, len(fp.Facts))
+		}
+		opts.Facts[id] = gate.FactBudget{MaxAge: duration(budget.MaxStaleness), Timeout: duration(budget.Timeout)}
+	}
+	return opts
+}
+
+// duration converts an optional PKL duration, returning zero if it is unset.
+func duration(d *pkl.Duration) time.Duration {
+	if d == nil {
+		return 0
+	}
+	return d.GoDuration()
+}
@@ -0,0 +1,74 @@
+package registry
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/apple/pkl-go/pkl"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/asimihsan/planning_engine/internal/config"
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+// TestNew_MatchesInputSchema checks that the facts the providers declare agree with the
+// policy's input schema, so that a fact the registry accepts is never rejected by the policy.
+func TestNew_MatchesInputSchema(t *testing.T) {
+	data, err := os.ReadFile("../../../policy/rego/input.json")
+	require.NoError(t, err)
+	var input struct {
+		Properties struct {
+			Facts struct {
+				Properties map[string]map[string]any `json:"properties"`
+				Required   []string                  `json:"required"`
+			} `json:"facts"`
+		} `json:"properties"`
+	}
+	require.NoError(t, json.Unmarshal(data, &input))
+	facts := input.Properties.Facts
+
+	var required []string
+	for _, schema := range New(&config.AppConfig{FactProviders: &config.FactProviders{}}).Schemas() {
+		if !schema.Optional() {
+			required = append(required, schema.ID)
+		}
+
+		property, ok := facts.Properties[schema.ID]
+		if !assert.True(t, ok, "input.json does not describe fact %s", schema.ID) {
+			continue
+		}
+		for keyword, value := range schema.JSONSchema() {
+			if keyword != "description" {
+				assert.Equal(t, value, property[keyword], "%s: %s", schema.ID, keyword)
+			}
+		}
+	}
+	assert.ElementsMatch(t, facts.Required, required)
+}
+
+func TestSnapshotOpts(t *testing.T) {
+	cfg := &config.AppConfig{FactProviders: &config.FactProviders{
+		MaxStaleness:       &pkl.Duration{Value: 45, Unit: pkl.Second},
+		ProviderTimeout:    &pkl.Duration{Value: 5, Unit: pkl.Second},
+		ProviderTimeoutCap: &pkl.Duration{Value: 10, Unit: pkl.Second},
+		Facts: map[string]*config.FactBudget{
+			"crash_rate":  {MaxStaleness: &pkl.Duration{Value: 5, Unit: pkl.Minute}},
+			"error_rate":  {Timeout: &pkl.Duration{Value: 500, Unit: pkl.Millisecond}},
+			"unspecified": nil,
+		},
+	}}
+
+	assert.Equal(t, gate.SnapshotOpts{
+		MaxAge:             45 * time.Second,
+		PerProviderTimeout: 5 * time.Second,
+		TimeoutCap:         10 * time.Second,
+		Facts: map[string]gate.FactBudget{
+			"crash_rate": {MaxAge: 5 * time.Minute},
+			"error_rate": {Timeout: 500 * time.Millisecond},
+		},
+	}, SnapshotOpts(cfg))
+}
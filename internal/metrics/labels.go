@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// OverflowLabel replaces label values beyond a LabelLimiter's limit.
+const OverflowLabel = "other"
+
+// Label limits. Stages, deny reasons and policy SHAs come from callers and policies, so
+// each distinct value would otherwise create new time series for the life of the process.
+var (
+	factLabels   = NewLabelLimiter(100)
+	stageLabels  = NewLabelLimiter(20)
+	reasonLabels = NewLabelLimiter(50)
+	// Policies are replaced by hot reloads, so only the current and previous ones are kept
+	policyLabels = NewRecentLabels(2, deletePolicySeries)
+)
+
+// LabelLimiter guards a label's cardinality: it passes through the first limit distinct values
+// it sees and reports every later one as OverflowLabel.
+type LabelLimiter struct {
+	limit int
+	mu    sync.Mutex
+	seen  map[string]struct{}
+}
+
+// NewLabelLimiter creates a LabelLimiter allowing limit distinct values.
+func NewLabelLimiter(limit int) *LabelLimiter {
+	return &LabelLimiter{limit: limit, seen: make(map[string]struct{})}
+}
+
+// Value returns v if it is within the limit, and OverflowLabel otherwise.
+func (l *LabelLimiter) Value(v string) string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.seen[v]; ok {
+		return v
+	}
+	if len(l.seen) >= l.limit {
+		return OverflowLabel
+	}
+	l.seen[v] = struct{}{}
+	return v
+}
+
+// RecentLabels guards the cardinality of a label whose values are replaced over time, such as
+// the policy being served: it keeps the limit most recently used values, and forgets the least
+// recently used one to make room for a new value, calling evict so its series can be deleted.
+type RecentLabels struct {
+	limit  int
+	evict  func(string)
+	mu     sync.Mutex
+	values []string // Least recently used first
+}
+
+// NewRecentLabels creates a RecentLabels keeping limit values. evict may be nil.
+func NewRecentLabels(limit int, evict func(string)) *RecentLabels {
+	return &RecentLabels{limit: limit, evict: evict}
+}
+
+// Value records v as the most recently used value and returns it.
+func (r *RecentLabels) Value(v string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if i := slices.Index(r.values, v); i >= 0 {
+		r.values = append(slices.Delete(r.values, i, i+1), v)
+		return v
+	}
+	r.values = append(r.values, v)
+	if len(r.values) > r.limit {
+		evicted := r.values[0]
+		r.values = slices.Delete(r.values, 0, 1)
+		if r.evict != nil {
+			r.evict(evicted)
+		}
+	}
+	return v
+}
+
+// deletePolicySeries deletes the series of a policy that is no longer served.
+func deletePolicySeries(policySHA string) {
+	labels := prometheus.Labels{"policy_sha": policySHA}
+	Decisions.DeletePartialMatch(labels)
+	EvalDuration.DeletePartialMatch(labels)
+	PolicyEvaluations.DeletePartialMatch(labels)
+	PolicyEvalLatency.DeletePartialMatch(labels)
+}
+
+// PolicyLabel returns the policy_sha label for a policy bundle ID. The content hash is cut to
+// its first 12 characters, which are enough to tell builds apart; a bundle revision, as in
+// "<revision>@<sha256>", is kept whole. Only recently used policies keep their series.
+func PolicyLabel(policyID string) string {
+	if policyID == "" {
+		return "" // No policy was loaded
+	}
+	revision, digest := "", policyID
+	if i := strings.LastIndexByte(policyID, '@'); i >= 0 {
+		revision, digest = policyID[:i+1], policyID[i+1:]
+	}
+	if len(digest) > 12 {
+		digest = digest[:12]
+	}
+	return policyLabels.Value(revision + digest)
+}
@@ -37,13 +37,199 @@ var (
 		},
 		[]string{"provider"},
 	)
+
+	// FactsCollected tracks facts the registry collected, by result: ok or a gate.FactErrorKind
+	FactsCollected = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "planning_engine",
+			Subsystem: "fact",
+			Name:      "collected_total",
+			Help:      "Number of facts collected for decisions, by result",
+		},
+		[]string{"fact", "result"},
+	)
+
+	// FactAge tracks how old facts were when they were collected
+	FactAge = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "planning_engine",
+			Subsystem: "fact",
+			Name:      "age_seconds",
+			Help:      "Age of facts when they were collected",
+			Buckets:   []float64{0.1, 0.5, 1, 5, 10, 30, 60, 120, 300, 600},
+		},
+		[]string{"fact"},
+	)
+
+	// Decisions tracks audited decision attempts
+	Decisions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "planning_engine",
+			Subsystem: "gate",
+			Name:      "decisions_total",
+			Help:      "Number of audited decision attempts, by outcome, stage, error class and policy",
+		},
+		[]string{"outcome", "stage", "reason_class", "policy_sha"},
+	)
+
+	// DenyReasons tracks the reasons policies gave for denying
+	DenyReasons = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "planning_engine",
+			Subsystem: "gate",
+			Name:      "deny_reasons_total",
+			Help:      "Number of times each deny reason was given",
+		},
+		[]string{"stage", "reason"},
+	)
+
+	// SnapshotDuration tracks the time decisions spent collecting facts
+	SnapshotDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "planning_engine",
+			Subsystem: "gate",
+			Name:      "snapshot_duration_seconds",
+			Help:      "Time spent collecting facts for a decision",
+		},
+		[]string{"stage"},
+	)
+
+	// EvalDuration tracks the time decisions spent evaluating the policy
+	EvalDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "planning_engine",
+			Subsystem: "gate",
+			Name:      "eval_duration_seconds",
+			Help:      "Time spent evaluating the policy for a decision",
+		},
+		[]string{"stage", "policy_sha"},
+	)
+
+	// PolicyEvaluations tracks policy evaluations by the OPA engine, including dry runs and replays
+	PolicyEvaluations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "planning_engine",
+			Subsystem: "policy",
+			Name:      "evaluations_total",
+			Help:      "Number of policy evaluations, by policy and result",
+		},
+		[]string{"policy_sha", "result"},
+	)
+
+	// PolicyEvalLatency tracks the latency of policy evaluations by the OPA engine
+	PolicyEvalLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "planning_engine",
+			Subsystem: "policy",
+			Name:      "evaluation_latency_seconds",
+			Help:      "Time spent in PolicyEngine.Evaluate()",
+		},
+		[]string{"policy_sha"},
+	)
+
+	// PolicyReloads tracks policy reloads that swapped in a new policy
+	PolicyReloads = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "planning_engine",
+			Subsystem: "policy",
+			Name:      "reloads_total",
+			Help:      "Number of times a new policy was loaded",
+		},
+		[]string{"source"},
+	)
+
+	// PolicyReloadFailures tracks policy reloads that failed and left the last good policy serving
+	PolicyReloadFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "planning_engine",
+			Subsystem: "policy",
+			Name:      "reload_failures_total",
+			Help:      "Number of policy reloads that failed, keeping the previous policy",
+		},
+		[]string{"source"},
+	)
+
+	// AuditQueueDepth tracks audit records waiting to be written, per sink
+	AuditQueueDepth = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "planning_engine",
+			Subsystem: "audit",
+			Name:      "queue_depth",
+			Help:      "Number of audit records queued for a sink",
+		},
+		[]string{"sink"},
+	)
+
+	// AuditLogFailures tracks decision attempts the gate could not audit, so told the caller to pause
+	AuditLogFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "planning_engine",
+			Subsystem: "audit",
+			Name:      "log_failures_total",
+			Help:      "Number of decision attempts that could not be audited, by the outcome they would have had",
+		},
+		[]string{"outcome"},
+	)
+
+	// AuditWriteLatency tracks how long sinks take to write a record
+	AuditWriteLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "planning_engine",
+			Subsystem: "audit",
+			Name:      "write_latency_seconds",
+			Help:      "Time a sink spent writing an audit record",
+		},
+		[]string{"sink"},
+	)
+
+	// AuditRecordsDropped tracks audit records a sink dropped because its queue was full
+	AuditRecordsDropped = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "planning_engine",
+			Subsystem: "audit",
+			Name:      "dropped_records_total",
+			Help:      "Number of audit records dropped because a sink's queue was full",
+		},
+		[]string{"sink"},
+	)
+
+	// AuditSinkErrors tracks audit records a sink failed to write or refused because its queue was full
+	AuditSinkErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "planning_engine",
+			Subsystem: "audit",
+			Name:      "sink_errors_total",
+			Help:      "Number of audit records a sink failed to write or refused",
+		},
+		[]string{"sink", "error_type"},
+	)
 )
 
 // MustRegister registers all metrics with the default Prometheus registry
 func MustRegister() {
-	prometheus.MustRegister(
+	MustRegisterWith(prometheus.DefaultRegisterer)
+}
+
+// MustRegisterWith registers all metrics with reg
+func MustRegisterWith(reg prometheus.Registerer) {
+	reg.MustRegister(
 		FactCollectLatency,
 		FactCollectErrors,
 		FactStaleness,
+		FactsCollected,
+		FactAge,
+		Decisions,
+		DenyReasons,
+		SnapshotDuration,
+		EvalDuration,
+		PolicyEvaluations,
+		PolicyEvalLatency,
+		PolicyReloads,
+		PolicyReloadFailures,
+		AuditQueueDepth,
+		AuditLogFailures,
+		AuditWriteLatency,
+		AuditRecordsDropped,
+		AuditSinkErrors,
 	)
 }
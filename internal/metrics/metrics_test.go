@@ -0,0 +1,150 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/asimihsan/planning_engine/internal/engine/opa"
+	"github.com/asimihsan/planning_engine/internal/fact/mock"
+	"github.com/asimihsan/planning_engine/internal/metrics"
+	"github.com/asimihsan/planning_engine/internal/policy/file"
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+// auditFunc adapts a function to gate.AuditLogger.
+type auditFunc func(gate.AuditRecord) error
+
+func (f auditFunc) Log(_ context.Context, record gate.AuditRecord) error { return f(record) }
+
+// scrape returns the samples served on /metrics, keyed by series, e.g. `name{label="value"}`.
+func scrape(t *testing.T, reg *prometheus.Registry) map[string]float64 {
+	t.Helper()
+	srv := httptest.NewServer(promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	samples := make(map[string]float64)
+	for _, line := range strings.Split(string(body), "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.LastIndexByte(line, ' ')
+		value, err := strconv.ParseFloat(line[i+1:], 64)
+		require.NoError(t, err, line)
+		samples[line[:i]] = value
+	}
+	return samples
+}
+
+func TestMetrics_Scrape(t *testing.T) {
+	// The metrics are process-wide, so assert how much each series grew
+	reg := prometheus.NewRegistry()
+	metrics.MustRegisterWith(reg)
+	before := scrape(t, reg)
+
+	ctx := context.Background()
+	policies := file.New("../../policy/rego/main.rego", "data.gate.response")
+	bundle, err := policies.GetPolicyBundle(ctx)
+	require.NoError(t, err)
+	policySHA := metrics.PolicyLabel(bundle.ID())
+
+	pending := mock.NewProvider("pending_delta", 100, "Pending devices")
+	registry := gate.NewFactRegistry().WithObserver(metrics.Observer{})
+	registry.Register(pending)
+	registry.Register(mock.NewProvider("max_pending_allowed", 500, "Pending device limit"))
+
+	var auditErr error
+	audit := auditFunc(func(gate.AuditRecord) error { return auditErr })
+	g := gate.NewGate(registry, opa.NewEngine(), policies, audit, gate.SnapshotOpts{MaxAge: time.Minute}, "config-sha").
+		WithObserver(metrics.Observer{})
+	req := gate.DecisionRequest{DeploymentID: "dep", Stage: "canary", RequestedCount: 10}
+
+	_, err = g.Decide(ctx, req) // Allow
+	require.NoError(t, err)
+	pending.Value = 600
+	_, err = g.Decide(ctx, req) // Deny
+	require.NoError(t, err)
+	auditErr = errors.New("disk full")
+	_, err = g.Decide(ctx, req) // Unaudited deny
+	require.ErrorIs(t, err, gate.ErrAuditLog)
+	auditErr = nil
+	pending.Timestamp = time.Now().Add(-time.Hour)
+	_, err = g.Decide(ctx, req) // Stale fact
+	require.ErrorIs(t, err, gate.ErrFactStale)
+
+	after := scrape(t, reg)
+	for _, line := range []string{
+		`planning_engine_fact_collected_total{fact="max_pending_allowed",result="ok"} 4`,
+		`planning_engine_fact_collected_total{fact="pending_delta",result="ok"} 3`,
+		`planning_engine_fact_collected_total{fact="pending_delta",result="stale"} 1`,
+		`planning_engine_fact_provider_stale_facts_total{provider="pending_delta"} 1`,
+		`planning_engine_fact_age_seconds_count{fact="pending_delta"} 4`,
+		fmt.Sprintf(`planning_engine_gate_decisions_total{outcome="allow",policy_sha=%q,reason_class="",stage="canary"} 1`, policySHA),
+		fmt.Sprintf(`planning_engine_gate_decisions_total{outcome="deny",policy_sha=%q,reason_class="",stage="canary"} 1`, policySHA),
+		fmt.Sprintf(`planning_engine_gate_decisions_total{outcome="error",policy_sha=%q,reason_class="audit_log",stage="canary"} 1`, policySHA),
+		fmt.Sprintf(`planning_engine_gate_decisions_total{outcome="error",policy_sha=%q,reason_class="fact_stale",stage="canary"} 1`, policySHA),
+		`planning_engine_gate_deny_reasons_total{reason="pending_delta exceeds allowed limit",stage="canary"} 2`,
+		`planning_engine_gate_snapshot_duration_seconds_count{stage="canary"} 4`,
+		fmt.Sprintf(`planning_engine_gate_eval_duration_seconds_count{policy_sha=%q,stage="canary"} 3`, policySHA),
+		fmt.Sprintf(`planning_engine_policy_evaluations_total{policy_sha=%q,result="allow"} 1`, policySHA),
+		fmt.Sprintf(`planning_engine_policy_evaluations_total{policy_sha=%q,result="deny"} 2`, policySHA),
+		fmt.Sprintf(`planning_engine_policy_evaluation_latency_seconds_count{policy_sha=%q} 3`, policySHA),
+		`planning_engine_policy_reloads_total{source="file"} 1`,
+		`planning_engine_audit_log_failures_total{outcome="deny"} 1`,
+	} {
+		i := strings.LastIndexByte(line, ' ')
+		series, want := line[:i], line[i+1:]
+		assert.Contains(t, after, series)
+		assert.Equal(t, want, strconv.FormatFloat(after[series]-before[series], 'f', -1, 64), series)
+	}
+}
+
+func TestLabelLimiter(t *testing.T) {
+	l := metrics.NewLabelLimiter(2)
+	assert.Equal(t, "canary", l.Value("canary"))
+	assert.Equal(t, "prod", l.Value("prod"))
+	assert.Equal(t, metrics.OverflowLabel, l.Value("staging"))
+	assert.Equal(t, "canary", l.Value("canary"))
+}
+
+func TestPolicyLabel(t *testing.T) {
+	digest := strings.Repeat("0123456789abcdef", 4)
+	assert.Equal(t, "", metrics.PolicyLabel(""))
+	assert.Equal(t, "0123456789ab", metrics.PolicyLabel(digest))
+	assert.Equal(t, "v2025.04.01@0123456789ab", metrics.PolicyLabel("v2025.04.01@"+digest))
+	assert.Equal(t, "v1@short", metrics.PolicyLabel("v1@short"))
+}
+
+func TestRecentLabels(t *testing.T) {
+	var evicted []string
+	r := metrics.NewRecentLabels(2, func(v string) { evicted = append(evicted, v) })
+	assert.Equal(t, "v1", r.Value("v1"))
+	assert.Equal(t, "v2", r.Value("v2"))
+	assert.Equal(t, "v1", r.Value("v1"))
+	assert.Empty(t, evicted)
+
+	// v2 is the least recently used
+	assert.Equal(t, "v3", r.Value("v3"))
+	assert.Equal(t, []string{"v2"}, evicted)
+	assert.Equal(t, "v4", r.Value("v4"))
+	assert.Equal(t, []string{"v2", "v1"}, evicted)
+}
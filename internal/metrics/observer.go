@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+// Observer exports fact collection and decisions as metrics. Attach it to a registry with
+// gate.FactRegistry.WithObserver and to a gate with gate.Gate.WithObserver.
+type Observer struct{}
+
+var (
+	_ gate.FactObserver     = Observer{}
+	_ gate.DecisionObserver = Observer{}
+)
+
+// FactCollected implements gate.FactObserver
+func (Observer) FactCollected(id string, meta gate.FactMeta, err *gate.FactError) {
+	fact := factLabels.Value(id)
+	result := "ok"
+	if err != nil {
+		result = string(err.Kind)
+	}
+	FactsCollected.WithLabelValues(fact, result).Inc()
+	if err != nil && err.Kind == gate.FactErrorStale {
+		FactStaleness.WithLabelValues(fact).Inc()
+	}
+	if !meta.CollectedAt.IsZero() {
+		FactAge.WithLabelValues(fact).Observe(meta.Age.Seconds())
+	}
+}
+
+// DecisionAudited implements gate.DecisionObserver
+func (Observer) DecisionAudited(record gate.AuditRecord, auditErr error) {
+	stage := stageLabels.Value(record.Stage)
+	policy := PolicyLabel(record.PolicySHA)
+
+	// Report what the caller was told: an unaudited decision is a pause
+	outcome, reasonClass := string(record.Outcome), record.ErrorClass
+	if auditErr != nil {
+		AuditLogFailures.WithLabelValues(outcome).Inc()
+		outcome, reasonClass = string(gate.OutcomeError), gate.ErrorClass(gate.ErrAuditLog)
+	}
+	Decisions.WithLabelValues(outcome, stage, reasonClass, policy).Inc()
+
+	for _, reason := range record.DenyReasons {
+		DenyReasons.WithLabelValues(stage, reasonLabels.Value(reason)).Inc()
+	}
+	if record.SnapshotDuration > 0 {
+		SnapshotDuration.WithLabelValues(stage).Observe(record.SnapshotDuration.Seconds())
+	}
+	if record.EvalDuration > 0 {
+		EvalDuration.WithLabelValues(stage, policy).Observe(record.EvalDuration.Seconds())
+	}
+}
@@ -5,22 +5,61 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
+	"sync"
+	"time"
 
-	"github.com/open-policy-agent/opa/v1/ast"
-	"github.com/open-policy-agent/opa/v1/rego"
+	"github.com/open-policy-agent/opa/v1/bundle"
 
 	"github.com/asimihsan/planning_engine/internal/engine/opa"
+	"github.com/asimihsan/planning_engine/internal/metrics"
 	"github.com/asimihsan/planning_engine/pkg/gate"
 )
 
-// Provider implements gate.PolicyProvider for file-based policy files
+// DefaultRefreshInterval is how often the policy file is checked for changes unless
+// overridden with WithRefreshInterval. It matches the default Policy.refreshInterval.
+const DefaultRefreshInterval = 30 * time.Second
+
+// Provider implements gate.PolicyProvider for file-based policies. PolicyPath may be a
+// single Rego file, an OPA bundle directory, or an OPA bundle tarball (.tar.gz or .tgz).
+// A single Rego file's input schema is the opa.InputSchemaFile beside it, if there is one;
+// its bundle ID then hashes both files.
+//
+// The policy is re-checked at most once per refresh interval and recompiled only if its
+// content hash changed. For files, an unchanged modification time and size skip even the
+// hash. If the new content fails to load, the last good bundle keeps serving.
 type Provider struct {
 	PolicyPath string
 	Query      string // e.g., "data.gate.response"
+
+	refreshInterval time.Duration
+	verification    *bundle.VerificationConfig
+
+	mu sync.Mutex
 	// caches the loaded bundle to avoid reloading/recompiling every time
 	cachedBundle gate.PolicyBundle
+	checkedAt    time.Time
+	contentSHA   string
+	modTime      time.Time
+	size         int64
+	schemaState  fileState
+}
+
+// fileState identifies a version of a file by its modification time and size; the zero
+// value means the file does not exist.
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+func statFile(path string) fileState {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{modTime: info.ModTime(), size: info.Size()}
 }
 
 var _ gate.PolicyProvider = (*Provider)(nil)
@@ -28,55 +67,129 @@ var _ gate.PolicyProvider = (*Provider)(nil)
 // New creates a new file-based policy provider
 func New(policyPath, query string) *Provider {
 	return &Provider{
-		PolicyPath: policyPath,
-		Query:      query,
+		PolicyPath:      policyPath,
+		Query:           query,
+		refreshInterval: DefaultRefreshInterval,
 	}
 }
 
+// WithRefreshInterval sets how often the policy file is checked for changes.
+// A zero interval checks on every call.
+func (p *Provider) WithRefreshInterval(interval time.Duration) *Provider {
+	p.refreshInterval = interval
+	return p
+}
+
+// WithVerification requires the policy to be an OPA bundle signed by one of vc's keys.
+func (p *Provider) WithVerification(vc *bundle.VerificationConfig) *Provider {
+	p.verification = vc
+	return p
+}
+
 // GetPolicyBundle implements gate.PolicyProvider
 func (p *Provider) GetPolicyBundle(ctx context.Context) (gate.PolicyBundle, error) {
-	// Basic caching to avoid reloading if already loaded
-	if p.cachedBundle != nil {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Basic caching to avoid re-checking the file on every decision
+	if p.cachedBundle != nil && time.Since(p.checkedAt) < p.refreshInterval {
 		return p.cachedBundle, nil
 	}
+	p.checkedAt = time.Now()
 
-	// Read the policy file
-	policyBytes, err := os.ReadFile(p.PolicyPath)
+	bundle, err := p.reload(ctx)
+	if err != nil {
+		if p.cachedBundle == nil {
+			return nil, err
+		}
+		metrics.PolicyReloadFailures.WithLabelValues("file").Inc()
+		log.Printf("file: policy reload failed, keeping bundle %s: %v", p.cachedBundle.ID(), err)
+		return p.cachedBundle, nil
+	}
+
+	if p.cachedBundle == nil || bundle.ID() != p.cachedBundle.ID() {
+		metrics.PolicyReloads.WithLabelValues("file").Inc()
+	}
+	if p.cachedBundle != nil && bundle.ID() != p.cachedBundle.ID() {
+		log.Printf("file: reloaded policy %s, now serving bundle %s", p.PolicyPath, bundle.ID())
+	}
+	p.cachedBundle = bundle
+	return bundle, nil
+}
+
+// reload returns the bundle for the current policy content, reusing the cached bundle
+// when the content has not changed.
+func (p *Provider) reload(ctx context.Context) (gate.PolicyBundle, error) {
+	info, err := os.Stat(p.PolicyPath)
 	if err != nil {
 		return nil, fmt.Errorf("%w: reading policy file %s: %v", gate.ErrPolicyLoad, p.PolicyPath, err)
 	}
+	if info.IsDir() {
+		return p.reloadDir(ctx)
+	}
+	schemaPath := filepath.Join(filepath.Dir(p.PolicyPath), opa.InputSchemaFile)
+	if opa.IsBundleArchive(p.PolicyPath) {
+		schemaPath = "" // A bundle tarball carries its own schema
+	}
+	schemaState := statFile(schemaPath)
+	if p.cachedBundle != nil && info.ModTime().Equal(p.modTime) && info.Size() == p.size && schemaState == p.schemaState {
+		return p.cachedBundle, nil
+	}
 
-	// Compile the policy module
-	moduleName := filepath.Base(p.PolicyPath)
-	compiler, err := ast.CompileModules(map[string]string{
-		moduleName: string(policyBytes),
-	})
+	// Read the policy file and its input schema
+	policyBytes, err := os.ReadFile(p.PolicyPath)
 	if err != nil {
-		return nil, fmt.Errorf("%w: compiling policy module %s: %v", gate.ErrPolicyLoad, moduleName, err)
+		return nil, fmt.Errorf("%w: reading policy file %s: %v", gate.ErrPolicyLoad, p.PolicyPath, err)
+	}
+	var schema []byte
+	if schemaState != (fileState{}) {
+		if schema, err = os.ReadFile(schemaPath); err != nil {
+			return nil, fmt.Errorf("%w: reading input schema %s: %v", gate.ErrPolicyLoad, schemaPath, err)
+		}
 	}
 
-	// Create and prepare the Rego query
-	r := rego.New(
-		rego.Query(p.Query),
-		rego.Compiler(compiler),
-	)
+	// A touched but unchanged file keeps the compiled bundle
+	hash := sha256.Sum256(policyBytes)
+	contentSHA := hex.EncodeToString(hash[:])
+	if schema != nil {
+		contentSHA = opa.ContentSHA(policyBytes, schema)
+	}
+	if p.cachedBundle != nil && contentSHA == p.contentSHA {
+		p.modTime, p.size, p.schemaState = info.ModTime(), info.Size(), schemaState
+		return p.cachedBundle, nil
+	}
 
-	// Prepare the query for evaluation
-	pq, err := r.PrepareForEval(ctx)
+	// Compile the policy module or bundle tarball
+	bundle, err := opa.CompilePolicy(ctx, filepath.Base(p.PolicyPath), policyBytes, p.Query, p.verification)
 	if err != nil {
-		return nil, fmt.Errorf("%w: preparing policy query '%s': %v", gate.ErrPolicyLoad, p.Query, err)
+		return nil, err
+	}
+	if schema != nil {
+		if bundle.InputSchema, err = opa.NewInputSchema(ctx, schema); err != nil {
+			return nil, fmt.Errorf("%s: %w", schemaPath, err)
+		}
+		bundle.BundleID = contentSHA
 	}
 
-	// Calculate SHA256 of the policy file for versioning
-	hash := sha256.Sum256(policyBytes)
-	bundleID := hex.EncodeToString(hash[:])
+	p.contentSHA, p.modTime, p.size, p.schemaState = contentSHA, info.ModTime(), info.Size(), schemaState
+	return bundle, nil
+}
 
-	// Create and cache the policy bundle
-	bundle := &opa.OpaPolicyBundle{
-		BundleID:      bundleID,
-		PreparedQuery: pq,
+// reloadDir reloads an OPA bundle directory if any file in it changed.
+func (p *Provider) reloadDir(ctx context.Context) (gate.PolicyBundle, error) {
+	contentSHA, err := opa.HashDir(p.PolicyPath)
+	if err != nil {
+		return nil, err
+	}
+	if p.cachedBundle != nil && contentSHA == p.contentSHA {
+		return p.cachedBundle, nil
+	}
+
+	bundle, err := opa.LoadBundleDir(ctx, p.PolicyPath, p.Query, p.verification)
+	if err != nil {
+		return nil, err
 	}
-	p.cachedBundle = bundle
 
+	p.contentSHA = contentSHA
 	return bundle, nil
 }
@@ -2,11 +2,21 @@ package file
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
 
 	"github.com/asimihsan/planning_engine/internal/engine/opa"
+	"github.com/asimihsan/planning_engine/internal/metrics"
 	"github.com/asimihsan/planning_engine/pkg/gate"
 )
 
@@ -131,3 +141,276 @@ func TestProvider(t *testing.T) {
 		}
 	})
 }
+
+// writePolicy writes content to path with a distinct modification time so changes are noticed
+// even on filesystems with coarse timestamps.
+func writePolicy(t *testing.T, path, content string, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write policy file: %v", err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("Failed to set policy file times: %v", err)
+	}
+}
+
+func policyAllowing(allow string) string {
+	return `
+	package test
+
+	response := {"allow": ` + allow + `, "deny_reasons": []}
+	`
+}
+
+func TestProviderReload(t *testing.T) {
+	ctx := context.Background()
+	start := time.Now().Add(-time.Hour)
+
+	t.Run("Recompile changed policy", func(t *testing.T) {
+		policyFile := filepath.Join(t.TempDir(), "policy.rego")
+		writePolicy(t, policyFile, policyAllowing("true"), start)
+		provider := New(policyFile, "data.test.response").WithRefreshInterval(0)
+
+		bundle1, err := provider.GetPolicyBundle(ctx)
+		if err != nil {
+			t.Fatalf("Failed to get policy bundle: %v", err)
+		}
+
+		writePolicy(t, policyFile, policyAllowing("false"), start.Add(time.Second))
+		bundle2, err := provider.GetPolicyBundle(ctx)
+		if err != nil {
+			t.Fatalf("Failed to get reloaded policy bundle: %v", err)
+		}
+		if bundle1.ID() == bundle2.ID() {
+			t.Fatalf("Expected a new bundle after the policy changed")
+		}
+
+		decision, err := opa.NewEngine().Evaluate(ctx, bundle2, map[string]any{})
+		if err != nil {
+			t.Fatalf("Failed to evaluate reloaded policy: %v", err)
+		}
+		if decision.Allow {
+			t.Errorf("Expected reloaded policy to deny")
+		}
+	})
+
+	t.Run("Touched file keeps compiled bundle", func(t *testing.T) {
+		policyFile := filepath.Join(t.TempDir(), "policy.rego")
+		writePolicy(t, policyFile, policyAllowing("true"), start)
+		provider := New(policyFile, "data.test.response").WithRefreshInterval(0)
+
+		bundle1, _ := provider.GetPolicyBundle(ctx)
+		writePolicy(t, policyFile, policyAllowing("true"), start.Add(time.Second))
+		bundle2, _ := provider.GetPolicyBundle(ctx)
+
+		if bundle1 != bundle2 {
+			t.Errorf("Expected unchanged content to keep the cached bundle")
+		}
+	})
+
+	t.Run("Input schema beside the policy", func(t *testing.T) {
+		dir := t.TempDir()
+		policyFile := filepath.Join(dir, "policy.rego")
+		writePolicy(t, policyFile, policyAllowing("true"), start)
+		provider := New(policyFile, "data.test.response").WithRefreshInterval(0)
+
+		plain, err := provider.GetPolicyBundle(ctx)
+		if err != nil {
+			t.Fatalf("Failed to get policy bundle: %v", err)
+		}
+
+		// Adding a schema is a new bundle, even though the policy did not change
+		schemaFile := filepath.Join(dir, opa.InputSchemaFile)
+		writePolicy(t, schemaFile, `{"required": ["facts"]}`, start)
+		withSchema, err := provider.GetPolicyBundle(ctx)
+		if err != nil {
+			t.Fatalf("Failed to get policy bundle with schema: %v", err)
+		}
+		if withSchema.ID() == plain.ID() {
+			t.Errorf("Expected the bundle ID to cover the input schema")
+		}
+		if err := withSchema.(gate.SchemaPolicyBundle).ValidateInput(ctx, map[string]any{}); !errors.Is(err, gate.ErrInputSchema) {
+			t.Errorf("Expected the input schema to be enforced, got: %v", err)
+		}
+
+		writePolicy(t, schemaFile, `{"required": ["request"]}`, start.Add(time.Second))
+		changed, _ := provider.GetPolicyBundle(ctx)
+		if changed.ID() == withSchema.ID() {
+			t.Errorf("Expected a changed schema to reload the bundle")
+		}
+	})
+
+	t.Run("Respect refresh interval", func(t *testing.T) {
+		policyFile := filepath.Join(t.TempDir(), "policy.rego")
+		writePolicy(t, policyFile, policyAllowing("true"), start)
+		provider := New(policyFile, "data.test.response").WithRefreshInterval(time.Hour)
+
+		bundle1, _ := provider.GetPolicyBundle(ctx)
+		writePolicy(t, policyFile, policyAllowing("false"), start.Add(time.Second))
+		bundle2, _ := provider.GetPolicyBundle(ctx)
+
+		if bundle1 != bundle2 {
+			t.Errorf("Expected the file not to be re-checked within the refresh interval")
+		}
+	})
+
+	t.Run("Keep last good bundle on failure", func(t *testing.T) {
+		policyFile := filepath.Join(t.TempDir(), "policy.rego")
+		writePolicy(t, policyFile, policyAllowing("true"), start)
+		provider := New(policyFile, "data.test.response").WithRefreshInterval(0)
+
+		good, err := provider.GetPolicyBundle(ctx)
+		if err != nil {
+			t.Fatalf("Failed to get policy bundle: %v", err)
+		}
+
+		failures := testutil.ToFloat64(metrics.PolicyReloadFailures.WithLabelValues("file"))
+		writePolicy(t, policyFile, "this is not valid Rego syntax", start.Add(time.Second))
+
+		bundle, err := provider.GetPolicyBundle(ctx)
+		if err != nil {
+			t.Fatalf("Expected last good bundle, got error: %v", err)
+		}
+		if bundle != good {
+			t.Errorf("Expected last good bundle to keep serving")
+		}
+		if got := testutil.ToFloat64(metrics.PolicyReloadFailures.WithLabelValues("file")); got != failures+1 {
+			t.Errorf("Expected reload failure to be counted, got %v want %v", got, failures+1)
+		}
+
+		// Fixing the file recovers
+		writePolicy(t, policyFile, policyAllowing("false"), start.Add(2*time.Second))
+		bundle, err = provider.GetPolicyBundle(ctx)
+		if err != nil {
+			t.Fatalf("Failed to get recovered policy bundle: %v", err)
+		}
+		if bundle.ID() == good.ID() {
+			t.Errorf("Expected the fixed policy to be loaded")
+		}
+	})
+
+	t.Run("Concurrent callers", func(t *testing.T) {
+		policyFile := filepath.Join(t.TempDir(), "policy.rego")
+		writePolicy(t, policyFile, policyAllowing("true"), start)
+		provider := New(policyFile, "data.test.response").WithRefreshInterval(0)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 20; j++ {
+					if i == 0 && j%5 == 0 {
+						allow := "true"
+						if j%10 == 0 {
+							allow = "false"
+						}
+						// Not writePolicy: t.Fatalf must not be called from other goroutines
+						modTime := start.Add(time.Duration(j) * time.Second)
+						if err := os.WriteFile(policyFile, []byte(policyAllowing(allow)), 0o644); err != nil {
+							t.Errorf("Failed to write policy file: %v", err)
+						}
+						_ = os.Chtimes(policyFile, modTime, modTime)
+					}
+					if _, err := provider.GetPolicyBundle(ctx); err != nil {
+						t.Errorf("Failed to get policy bundle: %v", err)
+						return
+					}
+				}
+			}(i)
+		}
+		wg.Wait()
+	})
+}
+
+func TestProviderBundleDir(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	files := map[string]string{
+		".manifest": `{"revision": "r1", "roots": ["test", "limits"]}`,
+		"test/main.rego": `
+package test
+
+default allow := false
+
+allow if input.value < data.limits.max_value
+
+response := {"allow": allow, "deny_reasons": []}
+`,
+		"limits/data.json": `{"max_value": 10}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	provider := New(dir, "data.test.response").WithRefreshInterval(0)
+	bundle1, err := provider.GetPolicyBundle(ctx)
+	if err != nil {
+		t.Fatalf("Failed to load bundle directory: %v", err)
+	}
+	if rev := bundle1.(*opa.OpaPolicyBundle).Revision; rev != "r1" {
+		t.Errorf("Expected revision r1, got %q", rev)
+	}
+
+	decision, err := opa.NewEngine().Evaluate(ctx, bundle1, map[string]any{"value": 5})
+	if err != nil {
+		t.Fatalf("Failed to evaluate bundle: %v", err)
+	}
+	if !decision.Allow {
+		t.Errorf("Expected value below data.limits.max_value to be allowed")
+	}
+
+	// Unchanged directory keeps the compiled bundle
+	bundle2, _ := provider.GetPolicyBundle(ctx)
+	if bundle1 != bundle2 {
+		t.Errorf("Expected the cached bundle for an unchanged directory")
+	}
+
+	// Changing only a data document is picked up
+	if err := os.WriteFile(filepath.Join(dir, "limits", "data.json"), []byte(`{"max_value": 3}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	bundle3, err := provider.GetPolicyBundle(ctx)
+	if err != nil {
+		t.Fatalf("Failed to reload bundle directory: %v", err)
+	}
+	decision, err = opa.NewEngine().Evaluate(ctx, bundle3, map[string]any{"value": 5})
+	if err != nil {
+		t.Fatalf("Failed to evaluate reloaded bundle: %v", err)
+	}
+	if decision.Allow {
+		t.Errorf("Expected reloaded data.json to lower the limit")
+	}
+}
+
+func TestProviderVerification(t *testing.T) {
+	policyFile := filepath.Join(t.TempDir(), "policy.rego")
+	writePolicy(t, policyFile, policyAllowing("true"), time.Now())
+
+	// A bare Rego file carries no signature, so it is refused once verification is on
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vc, err := opa.NewVerificationConfig(map[string]opa.VerificationKey{
+		"release": {Algorithm: opa.AlgEdDSA, PublicKey: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))},
+	}, "", "")
+	if err != nil {
+		t.Fatalf("Failed to build verification config: %v", err)
+	}
+
+	_, err = New(policyFile, "data.test.response").WithVerification(vc).GetPolicyBundle(context.Background())
+	if !errors.Is(err, gate.ErrPolicyLoad) {
+		t.Errorf("Expected ErrPolicyLoad for an unsigned policy, got %v", err)
+	}
+}
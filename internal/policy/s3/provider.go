@@ -0,0 +1,258 @@
+// Package s3 loads policy releases from a manifest stored in S3 or an S3-compatible store.
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/open-policy-agent/opa/v1/bundle"
+
+	"github.com/asimihsan/planning_engine/internal/metrics"
+	"github.com/asimihsan/planning_engine/internal/release"
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+// API is the subset of the S3 client used by Store and Provider.
+type API interface {
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+}
+
+// Store reads a manifest and the objects it references from S3.
+type Store struct {
+	client      API
+	bucket      string
+	manifestKey string
+}
+
+// NewStore creates a Store for a manifest at manifestURI (s3://bucket/key).
+func NewStore(client API, manifestURI string) (*Store, error) {
+	bucket, key, err := ParseURI(manifestURI)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{client: client, bucket: bucket, manifestKey: key}, nil
+}
+
+// Manifest fetches the raw manifest.
+func (s *Store) Manifest(ctx context.Context) ([]byte, error) {
+	data, _, err := s.getObject(ctx, s.bucket, s.manifestKey, "")
+	return data, err
+}
+
+// Fetch fetches an object referenced by the manifest.
+// References are either s3:// URIs or keys relative to the manifest's directory.
+func (s *Store) Fetch(ctx context.Context, ref string) ([]byte, error) {
+	bucket, key := s.bucket, path.Join(path.Dir(s.manifestKey), ref)
+	if strings.HasPrefix(ref, "s3://") {
+		var err error
+		if bucket, key, err = ParseURI(ref); err != nil {
+			return nil, err
+		}
+	}
+	data, _, err := s.getObject(ctx, bucket, key, "")
+	return data, err
+}
+
+// getObject fetches an object. If etag is set and still matches, it returns errNotModified.
+func (s *Store) getObject(ctx context.Context, bucket, key, etag string) ([]byte, string, error) {
+	in := &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	if etag != "" {
+		in.IfNoneMatch = aws.String(etag)
+	}
+
+	out, err := s.client.GetObject(ctx, in)
+	if isNotModified(err) {
+		return nil, etag, errNotModified
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: fetching s3://%s/%s: %v", gate.ErrPolicyLoad, bucket, key, err)
+	}
+	defer func() { _ = out.Body.Close() }()
+
+	data, err := io.ReadAll(out.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: reading s3://%s/%s: %v", gate.ErrPolicyLoad, bucket, key, err)
+	}
+	return data, aws.ToString(out.ETag), nil
+}
+
+var errNotModified = errors.New("s3: not modified")
+
+// isNotModified reports whether err is the 304 returned for a matching If-None-Match.
+func isNotModified(err error) bool {
+	var respErr interface{ HTTPStatusCode() int }
+	return errors.As(err, &respErr) && respErr.HTTPStatusCode() == http.StatusNotModified
+}
+
+// ParseURI splits an s3://bucket/key URI into its bucket and key.
+func ParseURI(uri string) (bucket, key string, err error) {
+	rest, ok := strings.CutPrefix(uri, "s3://")
+	if !ok {
+		return "", "", fmt.Errorf("%w: not an s3:// URI: %s", gate.ErrPolicyLoad, uri)
+	}
+	bucket, key, _ = strings.Cut(rest, "/")
+	if bucket == "" || key == "" {
+		return "", "", fmt.Errorf("%w: s3 URI must name a bucket and key: %s", gate.ErrPolicyLoad, uri)
+	}
+	return bucket, key, nil
+}
+
+// RefreshTimeout bounds a manifest refresh. Refreshes do not use the deadline of the
+// decision that triggered them, so one impatient caller cannot fail a reload for everyone.
+const RefreshTimeout = 10 * time.Second
+
+// Provider implements gate.PolicyProvider for a gate.Manifest stored in S3.
+//
+// The manifest is re-checked with a conditional GET (If-None-Match on its ETag) at most
+// once per refresh interval. The release it references is only loaded, with a
+// release.Loader, when the manifest changes. If a refresh fails, the last good release
+// keeps serving until the next interval, and calls made during a refresh are served the
+// current release instead of waiting for it.
+type Provider struct {
+	store           *Store
+	loader          *release.Loader
+	refreshInterval time.Duration
+
+	refreshMu sync.Mutex // serializes refreshes
+
+	mu        sync.Mutex
+	etag      string
+	checkedAt time.Time
+	release   *gate.Release // Config holds the raw config bytes
+}
+
+var _ gate.PolicyProvider = (*Provider)(nil)
+
+// New creates a new S3 policy provider for a manifest at manifestURI (s3://bucket/key).
+func New(client API, manifestURI, query string, refreshInterval time.Duration) (*Provider, error) {
+	store, err := NewStore(client, manifestURI)
+	if err != nil {
+		return nil, err
+	}
+	return &Provider{
+		store:           store,
+		loader:          release.NewLoader(store, query, rawConfig),
+		refreshInterval: refreshInterval,
+	}, nil
+}
+
+// rawConfig keeps a release's config as the bytes fetched from S3.
+func rawConfig(_ context.Context, data []byte) (any, error) {
+	return data, nil
+}
+
+// WithVerification requires the policy to be an OPA bundle signed by one of vc's keys.
+func (p *Provider) WithVerification(vc *bundle.VerificationConfig) *Provider {
+	p.loader.WithVerification(vc)
+	return p
+}
+
+// GetPolicyBundle implements gate.PolicyProvider
+func (p *Provider) GetPolicyBundle(ctx context.Context) (gate.PolicyBundle, error) {
+	rel, due := p.current()
+	if rel != nil && (!due || !p.refreshMu.TryLock()) {
+		// Fresh, or another call is refreshing it
+		return rel.Bundle, nil
+	}
+	if rel == nil {
+		p.refreshMu.Lock() // Nothing to serve until a release loads
+	}
+	defer p.refreshMu.Unlock()
+
+	if rel, due = p.current(); rel != nil && !due {
+		return rel.Bundle, nil // Loaded while this call waited
+	}
+	p.mu.Lock()
+	p.checkedAt = time.Now()
+	p.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), RefreshTimeout)
+	defer cancel()
+	if err := p.refresh(ctx); err != nil {
+		if rel == nil {
+			return nil, err
+		}
+		metrics.PolicyReloadFailures.WithLabelValues("s3").Inc()
+		log.Printf("s3: policy reload failed, keeping bundle %s: %v", rel.Bundle.ID(), err)
+		return rel.Bundle, nil
+	}
+	rel, _ = p.current()
+	return rel.Bundle, nil
+}
+
+// current returns the current release, if any, and whether its manifest is due a re-check.
+func (p *Provider) current() (*gate.Release, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.release, time.Since(p.checkedAt) >= p.refreshInterval
+}
+
+// Manifest returns the manifest the current bundle was loaded from.
+func (p *Provider) Manifest() gate.Manifest {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.release == nil {
+		return gate.Manifest{}
+	}
+	return p.release.Manifest
+}
+
+// Config returns the raw configuration referenced by the current manifest.
+func (p *Provider) Config() []byte {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.release == nil {
+		return nil
+	}
+	return p.release.Config.([]byte)
+}
+
+// refresh re-reads the manifest and, if it changed, loads the release it references.
+// The provider's state is only replaced once the whole release has loaded. Errors wrap
+// gate.ErrPolicyLoad.
+func (p *Provider) refresh(ctx context.Context) error {
+	p.mu.Lock()
+	etag := p.etag
+	p.mu.Unlock()
+
+	data, etag, err := p.store.getObject(ctx, p.store.bucket, p.store.manifestKey, etag)
+	if errors.Is(err, errNotModified) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	manifest, err := gate.ParseManifest(data)
+	if err != nil {
+		return err
+	}
+	rel, err := p.loader.LoadRelease(ctx, manifest)
+	if err != nil {
+		if !errors.Is(err, gate.ErrPolicyLoad) {
+			err = fmt.Errorf("%w: %w", gate.ErrPolicyLoad, err)
+		}
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.release == nil || rel.Bundle.ID() != p.release.Bundle.ID() {
+		metrics.PolicyReloads.WithLabelValues("s3").Inc()
+	}
+	p.etag = etag
+	p.release = rel
+	return nil
+}
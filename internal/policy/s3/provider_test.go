@@ -0,0 +1,321 @@
+package s3
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/asimihsan/planning_engine/internal/engine/opa"
+	"github.com/asimihsan/planning_engine/internal/metrics"
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+// fakeS3 is a minimal path-style S3 stand-in serving GetObject with ETags and If-None-Match.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte // "bucket/key" -> body
+	gets    map[string]int
+	down    bool // Fail every request, as in an outage
+}
+
+func newFakeS3() *fakeS3 {
+	return &fakeS3{objects: map[string][]byte{}, gets: map[string]int{}}
+}
+
+func (f *fakeS3) put(bucketKey, body string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.objects[bucketKey] = []byte(body)
+}
+
+func (f *fakeS3) setDown(down bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.down = down
+}
+
+func (f *fakeS3) getCount(bucketKey string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.gets[bucketKey]
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	bucketKey := strings.TrimPrefix(r.URL.Path, "/")
+	f.gets[bucketKey]++
+	if f.down {
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`))
+		return
+	}
+
+	body, ok := f.objects[bucketKey]
+	if !ok {
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`<Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`))
+		return
+	}
+
+	sum := md5.Sum(body)
+	etag := `"` + hex.EncodeToString(sum[:]) + `"`
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	_, _ = w.Write(body)
+}
+
+func newClient(t *testing.T, fake *fakeS3) *s3.Client {
+	t.Helper()
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	return s3.New(s3.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(srv.URL),
+		UsePathStyle: true,
+		Credentials:  credentials.NewStaticCredentialsProvider("test", "test", ""),
+	})
+}
+
+// manifest renders a manifest whose digests match the given policy and config bodies.
+func manifest(version, policyRef, policy, configRef, config string) string {
+	return fmt.Sprintf(`{"version": %q, "policy": %q, "policy_digest": %q, "config": %q, "config_digest": %q}`,
+		version, policyRef, gate.Digest([]byte(policy)), configRef, gate.Digest([]byte(config)))
+}
+
+const policyV1 = `
+package gate
+
+response := {"allow": true, "deny_reasons": []}
+`
+
+const policyV2 = `
+package gate
+
+response := {"allow": false, "deny_reasons": ["frozen"]}
+`
+
+func TestProvider(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Load release from manifest", func(t *testing.T) {
+		fake := newFakeS3()
+		fake.put("policies/prod/manifest.json", manifest("v1", "policy-v1.rego", policyV1, "config-v1.pkl", `maxPendingAllowed = 500`))
+		fake.put("policies/prod/policy-v1.rego", policyV1)
+		fake.put("policies/prod/config-v1.pkl", `maxPendingAllowed = 500`)
+
+		provider, err := New(newClient(t, fake), "s3://policies/prod/manifest.json", "data.gate.response", time.Minute)
+		require.NoError(t, err)
+
+		bundle, err := provider.GetPolicyBundle(ctx)
+		require.NoError(t, err)
+		assert.IsType(t, &opa.OpaPolicyBundle{}, bundle)
+		assert.Len(t, bundle.ID(), 64)
+		assert.Equal(t, "v1", provider.Manifest().Version)
+		assert.Equal(t, "maxPendingAllowed = 500", string(provider.Config()))
+
+		decision, err := opa.NewEngine().Evaluate(ctx, bundle, map[string]any{})
+		require.NoError(t, err)
+		assert.True(t, decision.Allow)
+	})
+
+	t.Run("Cache within refresh interval", func(t *testing.T) {
+		fake := newFakeS3()
+		fake.put("policies/manifest.json", manifest("v1", "policy-v1.rego", policyV1, "config-v1.pkl", ``))
+		fake.put("policies/policy-v1.rego", policyV1)
+		fake.put("policies/config-v1.pkl", ``)
+
+		provider, err := New(newClient(t, fake), "s3://policies/manifest.json", "data.gate.response", time.Hour)
+		require.NoError(t, err)
+
+		bundle1, err := provider.GetPolicyBundle(ctx)
+		require.NoError(t, err)
+		bundle2, err := provider.GetPolicyBundle(ctx)
+		require.NoError(t, err)
+
+		assert.Same(t, bundle1, bundle2)
+		assert.Equal(t, 1, fake.getCount("policies/manifest.json"))
+	})
+
+	t.Run("Poll by ETag", func(t *testing.T) {
+		fake := newFakeS3()
+		fake.put("policies/manifest.json", manifest("v1", "policy-v1.rego", policyV1, "config-v1.pkl", ``))
+		fake.put("policies/policy-v1.rego", policyV1)
+		fake.put("policies/policy-v2.rego", policyV2)
+		fake.put("policies/config-v1.pkl", ``)
+
+		// A zero refresh interval re-checks the manifest on every call
+		provider, err := New(newClient(t, fake), "s3://policies/manifest.json", "data.gate.response", 0)
+		require.NoError(t, err)
+
+		bundle1, err := provider.GetPolicyBundle(ctx)
+		require.NoError(t, err)
+
+		// Unchanged manifest: 304, nothing else is fetched
+		bundle2, err := provider.GetPolicyBundle(ctx)
+		require.NoError(t, err)
+		assert.Same(t, bundle1, bundle2)
+		assert.Equal(t, 2, fake.getCount("policies/manifest.json"))
+		assert.Equal(t, 1, fake.getCount("policies/policy-v1.rego"))
+
+		// Publishing a new manifest switches to the new release
+		fake.put("policies/manifest.json", manifest("v2", "policy-v2.rego", policyV2, "config-v1.pkl", ``))
+		bundle3, err := provider.GetPolicyBundle(ctx)
+		require.NoError(t, err)
+		assert.NotEqual(t, bundle1.ID(), bundle3.ID())
+		assert.Equal(t, "v2", provider.Manifest().Version)
+
+		decision, err := opa.NewEngine().Evaluate(ctx, bundle3, map[string]any{})
+		require.NoError(t, err)
+		assert.False(t, decision.Allow)
+		assert.Equal(t, []string{"frozen"}, decision.DenyReasons)
+	})
+
+	t.Run("Keep the last good release when S3 fails", func(t *testing.T) {
+		fake := newFakeS3()
+		fake.put("policies/manifest.json", manifest("v1", "policy-v1.rego", policyV1, "config-v1.pkl", ``))
+		fake.put("policies/policy-v1.rego", policyV1)
+		fake.put("policies/config-v1.pkl", ``)
+
+		provider, err := New(newClient(t, fake), "s3://policies/manifest.json", "data.gate.response", time.Hour)
+		require.NoError(t, err)
+		bundle1, err := provider.GetPolicyBundle(ctx)
+		require.NoError(t, err)
+
+		fake.setDown(true)
+		provider.checkedAt = time.Time{} // The refresh interval has passed
+		failures := testutil.ToFloat64(metrics.PolicyReloadFailures.WithLabelValues("s3"))
+		bundle2, err := provider.GetPolicyBundle(ctx)
+		require.NoError(t, err)
+		assert.Same(t, bundle1, bundle2)
+		assert.Equal(t, "v1", provider.Manifest().Version)
+		assert.Equal(t, failures+1, testutil.ToFloat64(metrics.PolicyReloadFailures.WithLabelValues("s3")))
+		assert.Equal(t, 2, fake.getCount("policies/manifest.json"))
+
+		// The failed refresh waits for the next interval before S3 is asked again
+		_, err = provider.GetPolicyBundle(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, fake.getCount("policies/manifest.json"))
+	})
+
+	t.Run("Absolute references", func(t *testing.T) {
+		fake := newFakeS3()
+		fake.put("manifests/manifest.json", manifest("v1", "s3://bundles/policy-v1.rego", policyV1, "s3://configs/config-v1.pkl", `host = "prod"`))
+		fake.put("bundles/policy-v1.rego", policyV1)
+		fake.put("configs/config-v1.pkl", `host = "prod"`)
+
+		provider, err := New(newClient(t, fake), "s3://manifests/manifest.json", "data.gate.response", time.Minute)
+		require.NoError(t, err)
+
+		_, err = provider.GetPolicyBundle(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, `host = "prod"`, string(provider.Config()))
+	})
+
+	failures := []struct {
+		name    string
+		objects map[string]string
+	}{
+		{
+			name:    "missing manifest",
+			objects: map[string]string{},
+		},
+		{
+			name:    "malformed manifest",
+			objects: map[string]string{"policies/manifest.json": `{"version": `},
+		},
+		{
+			name:    "incomplete manifest",
+			objects: map[string]string{"policies/manifest.json": `{"version": "v1"}`},
+		},
+		{
+			name: "missing policy",
+			objects: map[string]string{
+				"policies/manifest.json": manifest("v1", "policy-v1.rego", policyV1, "config-v1.pkl", ``),
+				"policies/config-v1.pkl": ``,
+			},
+		},
+		{
+			name: "invalid policy",
+			objects: map[string]string{
+				"policies/manifest.json":  manifest("v1", "policy-v1.rego", `this is not valid Rego syntax`, "config-v1.pkl", ``),
+				"policies/policy-v1.rego": `this is not valid Rego syntax`,
+				"policies/config-v1.pkl":  ``,
+			},
+		},
+		{
+			name: "policy digest mismatch",
+			objects: map[string]string{
+				"policies/manifest.json":  manifest("v1", "policy-v1.rego", policyV1, "config-v1.pkl", ``),
+				"policies/policy-v1.rego": policyV2,
+				"policies/config-v1.pkl":  ``,
+			},
+		},
+		{
+			name: "missing config",
+			objects: map[string]string{
+				"policies/manifest.json":  manifest("v1", "policy-v1.rego", policyV1, "config-v1.pkl", ``),
+				"policies/policy-v1.rego": policyV1,
+			},
+		},
+		{
+			name: "config digest mismatch",
+			objects: map[string]string{
+				"policies/manifest.json":  manifest("v1", "policy-v1.rego", policyV1, "config-v1.pkl", `maxPendingAllowed = 500`),
+				"policies/policy-v1.rego": policyV1,
+				"policies/config-v1.pkl":  `maxPendingAllowed = 5000`,
+			},
+		},
+	}
+	for _, tt := range failures {
+		t.Run("Fail on "+tt.name, func(t *testing.T) {
+			fake := newFakeS3()
+			for k, v := range tt.objects {
+				fake.put(k, v)
+			}
+
+			provider, err := New(newClient(t, fake), "s3://policies/manifest.json", "data.gate.response", time.Minute)
+			require.NoError(t, err)
+
+			_, err = provider.GetPolicyBundle(ctx)
+			assert.ErrorIs(t, err, gate.ErrPolicyLoad)
+		})
+	}
+}
+
+func TestParseURI(t *testing.T) {
+	bucket, key, err := ParseURI("s3://policies/prod/manifest.json")
+	require.NoError(t, err)
+	assert.Equal(t, "policies", bucket)
+	assert.Equal(t, "prod/manifest.json", key)
+
+	for _, uri := range []string{"file:///policy.rego", "s3://", "s3://bucket", "s3://bucket/", "s3:///key"} {
+		_, _, err := ParseURI(uri)
+		assert.ErrorIs(t, err, gate.ErrPolicyLoad, uri)
+	}
+}
@@ -0,0 +1,138 @@
+// Package release loads policy/config releases described by a gate.Manifest and hot-swaps them.
+package release
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/open-policy-agent/opa/v1/bundle"
+
+	"github.com/asimihsan/planning_engine/internal/engine/opa"
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+// Store reads a manifest and the objects it references.
+type Store interface {
+	// Manifest fetches the raw manifest.
+	Manifest(ctx context.Context) ([]byte, error)
+	// Fetch fetches an object referenced by the manifest.
+	Fetch(ctx context.Context, ref string) ([]byte, error)
+}
+
+// ConfigDecoder decodes raw configuration content into the value stored in gate.Release.Config.
+type ConfigDecoder func(ctx context.Context, data []byte) (any, error)
+
+// Loader builds gate.Releases from the manifest in a Store.
+type Loader struct {
+	store  Store
+	query  string // e.g., "data.gate.response"
+	decode ConfigDecoder
+
+	verification *bundle.VerificationConfig
+}
+
+// NewLoader creates a Loader for the manifest in store.
+func NewLoader(store Store, query string, decode ConfigDecoder) *Loader {
+	return &Loader{store: store, query: query, decode: decode}
+}
+
+// WithVerification requires release policies to be OPA bundles signed by one of vc's keys.
+func (l *Loader) WithVerification(vc *bundle.VerificationConfig) *Loader {
+	l.verification = vc
+	return l
+}
+
+// LoadManifest fetches and parses the current manifest.
+func (l *Loader) LoadManifest(ctx context.Context) (gate.Manifest, error) {
+	data, err := l.store.Manifest(ctx)
+	if err != nil {
+		return gate.Manifest{}, err
+	}
+	return gate.ParseManifest(data)
+}
+
+// LoadRelease fetches, verifies and compiles the policy, input schema and config named by m.
+// A pinned input schema is hashed into the bundle ID along with the policy.
+// Policy and schema errors wrap gate.ErrPolicyLoad and config errors wrap gate.ErrConfigLoad.
+func (l *Loader) LoadRelease(ctx context.Context, m gate.Manifest) (*gate.Release, error) {
+	policy, err := l.store.Fetch(ctx, m.Policy)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.VerifyPolicy(policy); err != nil {
+		return nil, err
+	}
+	bundle, err := opa.CompilePolicy(ctx, path.Base(m.Policy), policy, l.query, l.verification)
+	if err != nil {
+		return nil, err
+	}
+	if m.InputSchema != "" {
+		data, err := l.store.Fetch(ctx, m.InputSchema)
+		if err != nil {
+			return nil, err
+		}
+		if err := m.VerifyInputSchema(data); err != nil {
+			return nil, err
+		}
+		if bundle.InputSchema, err = opa.NewInputSchema(ctx, data); err != nil {
+			return nil, err
+		}
+		// A schema change is a new release of the policy
+		bundle.BundleID = opa.ContentSHA(policy, data)
+		if bundle.Revision != "" {
+			bundle.BundleID = bundle.Revision + "@" + bundle.BundleID
+		}
+	}
+
+	data, err := l.store.Fetch(ctx, m.Config)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", gate.ErrConfigLoad, err)
+	}
+	if err := m.VerifyConfig(data); err != nil {
+		return nil, err
+	}
+	config, err := l.decode(ctx, data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: decoding config %s: %v", gate.ErrConfigLoad, m.Config, err)
+	}
+
+	return gate.NewRelease(m, bundle, config), nil
+}
+
+// DirStore is a Store backed by the local filesystem.
+// References are resolved relative to the manifest's directory.
+type DirStore struct {
+	manifestPath string
+}
+
+var _ Store = (*DirStore)(nil)
+
+// NewDirStore creates a DirStore for the manifest at manifestPath.
+func NewDirStore(manifestPath string) *DirStore {
+	return &DirStore{manifestPath: manifestPath}
+}
+
+// Manifest implements Store.
+func (s *DirStore) Manifest(context.Context) ([]byte, error) {
+	data, err := os.ReadFile(s.manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: reading manifest %s: %v", gate.ErrPolicyLoad, s.manifestPath, err)
+	}
+	return data, nil
+}
+
+// Fetch implements Store.
+func (s *DirStore) Fetch(_ context.Context, ref string) ([]byte, error) {
+	p := ref
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(filepath.Dir(s.manifestPath), ref)
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil, fmt.Errorf("%w: reading %s: %v", gate.ErrPolicyLoad, p, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,234 @@
+package release
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+// memStore is an in-memory Store whose manifest can be republished concurrently.
+type memStore struct {
+	mu       sync.Mutex
+	manifest []byte
+	objects  map[string][]byte
+}
+
+func newMemStore() *memStore {
+	return &memStore{objects: map[string][]byte{}}
+}
+
+func (s *memStore) Manifest(context.Context) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.manifest == nil {
+		return nil, fmt.Errorf("%w: no manifest", gate.ErrPolicyLoad)
+	}
+	return s.manifest, nil
+}
+
+func (s *memStore) Fetch(_ context.Context, ref string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.objects[ref]
+	if !ok {
+		return nil, fmt.Errorf("%w: no object %s", gate.ErrPolicyLoad, ref)
+	}
+	return data, nil
+}
+
+func (s *memStore) put(ref, data string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[ref] = []byte(data)
+}
+
+// publish stores a policy and config and points the manifest at them.
+func (s *memStore) publish(version, policy, config string) {
+	policyRef, configRef := "policy-"+version+".rego", "config-"+version
+	s.put(policyRef, policy)
+	s.put(configRef, config)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.manifest = []byte(manifestJSON(version, policyRef, policy, configRef, config))
+}
+
+func manifestJSON(version, policyRef, policy, configRef, config string) string {
+	return fmt.Sprintf(`{"version": %q, "policy": %q, "policy_digest": %q, "config": %q, "config_digest": %q}`,
+		version, policyRef, gate.Digest([]byte(policy)), configRef, gate.Digest([]byte(config)))
+}
+
+// publishSchema adds an input schema to the published manifest.
+func (s *memStore) publishSchema(schema string) {
+	s.put("input.json", schema)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.manifest = append(s.manifest[:len(s.manifest)-1],
+		fmt.Sprintf(`, "input_schema": "input.json", "input_schema_digest": %q}`, gate.Digest([]byte(schema)))...)
+}
+
+// limitSchema requires an integer limit fact.
+const limitSchema = `{"type": "object", "properties": {"facts": {"type": "object", "properties": {"limit": {"type": "integer"}}, "required": ["limit"]}}}`
+
+// decodeInt decodes test configs, which are a single integer limit.
+func decodeInt(_ context.Context, data []byte) (any, error) {
+	return strconv.Atoi(string(data))
+}
+
+// limitPolicy allows only when the limit fact equals the limit the policy was written for.
+func limitPolicy(limit int) string {
+	return fmt.Sprintf(`
+package gate
+
+default allow := false
+
+allow if input.facts.limit == %d
+
+response := {"allow": allow, "deny_reasons": []}
+`, limit)
+}
+
+func TestLoader(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Load release", func(t *testing.T) {
+		store := newMemStore()
+		store.publish("v1", limitPolicy(100), "100")
+		loader := NewLoader(store, "data.gate.response", decodeInt)
+
+		m, err := loader.LoadManifest(ctx)
+		require.NoError(t, err)
+		rel, err := loader.LoadRelease(ctx, m)
+		require.NoError(t, err)
+
+		assert.Equal(t, "v1", rel.Manifest.Version)
+		assert.Equal(t, 100, rel.Config)
+		assert.Len(t, rel.Bundle.ID(), 64)
+		assert.Equal(t, m.ConfigDigest, "sha256:"+rel.ConfigSHA)
+	})
+
+	t.Run("Load release with input schema", func(t *testing.T) {
+		store := newMemStore()
+		store.publish("v1", limitPolicy(100), "100")
+		loader := NewLoader(store, "data.gate.response", decodeInt)
+		load := func() *gate.Release {
+			t.Helper()
+			m, err := loader.LoadManifest(ctx)
+			require.NoError(t, err)
+			rel, err := loader.LoadRelease(ctx, m)
+			require.NoError(t, err)
+			return rel
+		}
+		unvalidated := load()
+
+		store.publishSchema(limitSchema)
+		rel := load()
+		assert.Equal(t, "input.json", rel.Manifest.InputSchema)
+		validated, ok := rel.Bundle.(gate.SchemaPolicyBundle)
+		require.True(t, ok)
+		assert.NoError(t, validated.ValidateInput(ctx, map[string]any{"facts": map[string]any{"limit": 100}}))
+		assert.ErrorIs(t, validated.ValidateInput(ctx, map[string]any{"facts": map[string]any{"limit": "100"}}), gate.ErrInputSchema)
+
+		// The schema is part of the bundle ID, so changing only the schema changes it
+		assert.Len(t, rel.Bundle.ID(), 64)
+		assert.NotEqual(t, unvalidated.Bundle.ID(), rel.Bundle.ID())
+		store.publish("v1", limitPolicy(100), "100")
+		store.publishSchema(`{"type": "object"}`)
+		assert.NotEqual(t, rel.Bundle.ID(), load().Bundle.ID())
+	})
+
+	failures := []struct {
+		name    string
+		setup   func(*memStore)
+		wantErr error
+	}{
+		{
+			name:    "missing manifest",
+			setup:   func(*memStore) {},
+			wantErr: gate.ErrPolicyLoad,
+		},
+		{
+			name: "policy digest mismatch",
+			setup: func(s *memStore) {
+				s.publish("v1", limitPolicy(100), "100")
+				s.put("policy-v1.rego", limitPolicy(200))
+			},
+			wantErr: gate.ErrPolicyLoad,
+		},
+		{
+			name: "config digest mismatch",
+			setup: func(s *memStore) {
+				s.publish("v1", limitPolicy(100), "100")
+				s.put("config-v1", "200")
+			},
+			wantErr: gate.ErrConfigLoad,
+		},
+		{
+			name: "input schema digest mismatch",
+			setup: func(s *memStore) {
+				s.publish("v1", limitPolicy(100), "100")
+				s.publishSchema(limitSchema)
+				s.put("input.json", `{}`)
+			},
+			wantErr: gate.ErrPolicyLoad,
+		},
+		{
+			name: "invalid input schema",
+			setup: func(s *memStore) {
+				s.publish("v1", limitPolicy(100), "100")
+				s.publishSchema(`{"type": 5}`)
+			},
+			wantErr: gate.ErrPolicyLoad,
+		},
+		{
+			name: "undecodable config",
+			setup: func(s *memStore) {
+				s.publish("v1", limitPolicy(100), "one hundred")
+			},
+			wantErr: gate.ErrConfigLoad,
+		},
+	}
+	for _, tt := range failures {
+		t.Run("Fail on "+tt.name, func(t *testing.T) {
+			store := newMemStore()
+			tt.setup(store)
+			loader := NewLoader(store, "data.gate.response", decodeInt)
+
+			m, err := loader.LoadManifest(ctx)
+			if err == nil {
+				_, err = loader.LoadRelease(ctx, m)
+			}
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestDirStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	policy, config := limitPolicy(100), "100"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "policy.rego"), []byte(policy), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config"), []byte(config), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "manifest.json"),
+		[]byte(manifestJSON("v1", "policy.rego", policy, "config", config)), 0o644))
+
+	loader := NewLoader(NewDirStore(filepath.Join(dir, "manifest.json")), "data.gate.response", decodeInt)
+	m, err := loader.LoadManifest(ctx)
+	require.NoError(t, err)
+	rel, err := loader.LoadRelease(ctx, m)
+	require.NoError(t, err)
+	assert.Equal(t, 100, rel.Config)
+
+	_, err = NewDirStore(filepath.Join(dir, "missing.json")).Manifest(ctx)
+	assert.ErrorIs(t, err, gate.ErrPolicyLoad)
+}
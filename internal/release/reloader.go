@@ -0,0 +1,89 @@
+package release
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/asimihsan/planning_engine/internal/metrics"
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+// Reloader implements gate.ReleaseProvider by polling a manifest and atomically swapping
+// in the release it describes. A decision that has read the current release keeps using
+// it even if a reload completes mid-flight, so it always sees a consistent (bundle, config) pair.
+//
+// A release that fails to load is never swapped in; the last good release keeps serving.
+type Reloader struct {
+	loader   *Loader
+	interval time.Duration
+
+	current atomic.Pointer[gate.Release]
+	mu      sync.Mutex // serializes reloads
+}
+
+var _ gate.ReleaseProvider = (*Reloader)(nil)
+
+// NewReloader creates a Reloader polling loader every interval.
+func NewReloader(loader *Loader, interval time.Duration) *Reloader {
+	return &Reloader{loader: loader, interval: interval}
+}
+
+// CurrentRelease implements gate.ReleaseProvider.
+func (r *Reloader) CurrentRelease(context.Context) (*gate.Release, error) {
+	if rel := r.current.Load(); rel != nil {
+		return rel, nil
+	}
+	return nil, fmt.Errorf("%w: no release loaded", gate.ErrPolicyLoad)
+}
+
+// Reload loads the manifest and, if it names a different release, swaps it in.
+// It reports whether the current release changed.
+func (r *Reloader) Reload(ctx context.Context) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, err := r.loader.LoadManifest(ctx)
+	if err != nil {
+		return false, err
+	}
+	if cur := r.current.Load(); cur != nil && cur.Manifest == m {
+		return false, nil
+	}
+
+	rel, err := r.loader.LoadRelease(ctx, m)
+	if err != nil {
+		return false, err
+	}
+	r.current.Store(rel)
+	metrics.PolicyReloads.WithLabelValues("manifest").Inc()
+	return true, nil
+}
+
+// Run reloads every interval until ctx is cancelled. Failures are logged and the
+// last good release keeps serving.
+func (r *Reloader) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changed, err := r.Reload(ctx)
+			if err != nil {
+				metrics.PolicyReloadFailures.WithLabelValues("manifest").Inc()
+				log.Printf("release: reload failed, keeping current release: %v", err)
+				continue
+			}
+			if changed {
+				rel := r.current.Load()
+				log.Printf("release: now serving %s (policy %s, config %s)", rel.Manifest.Version, rel.Bundle.ID(), rel.ConfigSHA)
+			}
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package release
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/asimihsan/planning_engine/internal/engine/opa"
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+// limitFact serves the limit from the configuration of the release being evaluated.
+type limitFact struct{}
+
+func (limitFact) Describe() gate.Schema {
+	return gate.Schema{ID: "limit", Description: "Limit from the release configuration"}
+}
+
+func (limitFact) Collect(ctx context.Context, _, _ string) (gate.Fact, error) {
+	rel, ok := gate.ReleaseFromContext(ctx)
+	if !ok {
+		return nil, gate.ErrFactSourceUnavailable
+	}
+	return gate.NewFact("limit", rel.Config, time.Now()), nil
+}
+
+type nopAuditLogger struct{}
+
+func (nopAuditLogger) Log(context.Context, gate.AuditRecord) error {
+	return nil
+}
+
+func TestReloader(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("No release before first load", func(t *testing.T) {
+		r := NewReloader(NewLoader(newMemStore(), "data.gate.response", decodeInt), time.Minute)
+
+		_, err := r.CurrentRelease(ctx)
+		assert.ErrorIs(t, err, gate.ErrPolicyLoad)
+	})
+
+	t.Run("Swap on new manifest", func(t *testing.T) {
+		store := newMemStore()
+		store.publish("v1", limitPolicy(100), "100")
+		r := NewReloader(NewLoader(store, "data.gate.response", decodeInt), time.Minute)
+
+		changed, err := r.Reload(ctx)
+		require.NoError(t, err)
+		assert.True(t, changed)
+		v1, err := r.CurrentRelease(ctx)
+		require.NoError(t, err)
+
+		// Unchanged manifest keeps the same release
+		changed, err = r.Reload(ctx)
+		require.NoError(t, err)
+		assert.False(t, changed)
+		same, _ := r.CurrentRelease(ctx)
+		assert.Same(t, v1, same)
+
+		store.publish("v2", limitPolicy(200), "200")
+		changed, err = r.Reload(ctx)
+		require.NoError(t, err)
+		assert.True(t, changed)
+		v2, _ := r.CurrentRelease(ctx)
+		assert.Equal(t, "v2", v2.Manifest.Version)
+		assert.Equal(t, 200, v2.Config)
+	})
+
+	t.Run("Keep last good release on failure", func(t *testing.T) {
+		store := newMemStore()
+		store.publish("v1", limitPolicy(100), "100")
+		r := NewReloader(NewLoader(store, "data.gate.response", decodeInt), time.Minute)
+		_, err := r.Reload(ctx)
+		require.NoError(t, err)
+
+		store.publish("v2", `this is not valid Rego syntax`, "200")
+		_, err = r.Reload(ctx)
+		assert.ErrorIs(t, err, gate.ErrPolicyLoad)
+
+		cur, err := r.CurrentRelease(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "v1", cur.Manifest.Version)
+	})
+
+	t.Run("Run polls until cancelled", func(t *testing.T) {
+		store := newMemStore()
+		store.publish("v1", limitPolicy(100), "100")
+		r := NewReloader(NewLoader(store, "data.gate.response", decodeInt), time.Millisecond)
+		_, err := r.Reload(ctx)
+		require.NoError(t, err)
+
+		runCtx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			r.Run(runCtx)
+			close(done)
+		}()
+
+		store.publish("v2", limitPolicy(200), "200")
+		assert.Eventually(t, func() bool {
+			cur, _ := r.CurrentRelease(ctx)
+			return cur.Manifest.Version == "v2"
+		}, 5*time.Second, time.Millisecond)
+
+		cancel()
+		<-done
+	})
+}
+
+// TestReloader_ConsistentPairs flips between two releases while decisions are in flight.
+// Each policy only allows the limit from its own config, so any decision that saw a
+// mixed (bundle, config) pair would deny. Run with -race.
+func TestReloader_ConsistentPairs(t *testing.T) {
+	ctx := context.Background()
+	store := newMemStore()
+	store.publish("v1", limitPolicy(100), "100")
+	reloader := NewReloader(NewLoader(store, "data.gate.response", decodeInt), time.Minute)
+	_, err := reloader.Reload(ctx)
+	require.NoError(t, err)
+
+	registry := gate.NewFactRegistry()
+	registry.Register(limitFact{})
+	g := gate.NewReleaseGate(registry, opa.NewEngine(), reloader, nopAuditLogger{}, gate.SnapshotOpts{MaxAge: time.Minute})
+
+	var stop atomic.Bool
+	var reloads atomic.Int64
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; !stop.Load(); i++ {
+			if i%2 == 0 {
+				store.publish("v2", limitPolicy(200), "200")
+			} else {
+				store.publish("v1", limitPolicy(100), "100")
+			}
+			if changed, err := reloader.Reload(ctx); err == nil && changed {
+				reloads.Add(1)
+			}
+		}
+	}()
+
+	const workers, decisionsPerWorker = 8, 200
+	var decisions sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		decisions.Add(1)
+		go func() {
+			defer decisions.Done()
+			for i := 0; i < decisionsPerWorker; i++ {
+				decision, err := g.Decide(ctx, gate.DecisionRequest{DeploymentID: "dep", Stage: "canary"})
+				if !assert.NoError(t, err) || !assert.True(t, decision.Allow, "decision saw a mixed release: %+v", decision) {
+					return
+				}
+			}
+		}()
+	}
+
+	decisions.Wait()
+	stop.Store(true)
+	wg.Wait()
+
+	assert.Positive(t, reloads.Load(), "expected releases to be swapped during the test")
+}
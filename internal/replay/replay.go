@@ -0,0 +1,201 @@
+// Package replay re-evaluates audited decisions against a candidate policy, to show which
+// decisions it would have changed before it is promoted.
+package replay
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/asimihsan/planning_engine/internal/engine/opa"
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+// ChangeKind describes how the candidate's decision differs from the audited one.
+type ChangeKind string
+
+const (
+	AllowToDeny    ChangeKind = "allow_to_deny"
+	DenyToAllow    ChangeKind = "deny_to_allow"
+	ReasonsChanged ChangeKind = "reasons_changed" // Still denied, for different reasons
+	Failed         ChangeKind = "failed"          // The candidate could not evaluate the record
+)
+
+// Counts tallies replayed records.
+type Counts struct {
+	Replayed       int `json:"replayed"`
+	Unchanged      int `json:"unchanged"`
+	AllowToDeny    int `json:"allow_to_deny"`
+	DenyToAllow    int `json:"deny_to_allow"`
+	ReasonsChanged int `json:"reasons_changed"`
+	Failed         int `json:"failed"`
+	Skipped        int `json:"skipped"`       // Error records, which have no decision to compare
+	MissingFacts   int `json:"missing_facts"` // Replayed without some fact the candidate reads
+}
+
+func (c *Counts) add(kind ChangeKind, missingFacts bool) {
+	c.Replayed++
+	switch kind {
+	case AllowToDeny:
+		c.AllowToDeny++
+	case DenyToAllow:
+		c.DenyToAllow++
+	case ReasonsChanged:
+		c.ReasonsChanged++
+	case Failed:
+		c.Failed++
+	default:
+		c.Unchanged++
+	}
+	if missingFacts {
+		c.MissingFacts++
+	}
+}
+
+// DeploymentCounts tallies the replayed records of one deployment.
+type DeploymentCounts struct {
+	DeploymentID string `json:"deployment_id"`
+	Counts
+}
+
+// Change is an audited decision the candidate would have made differently.
+type Change struct {
+	DecisionID     string     `json:"decision_id"`
+	DeploymentID   string     `json:"deployment_id"`
+	Stage          string     `json:"stage"`
+	Kind           ChangeKind `json:"kind"`
+	DenyReasons    []string   `json:"deny_reasons"`            // As audited
+	NewDenyReasons []string   `json:"new_deny_reasons"`        // As the candidate decided
+	MissingFacts   []string   `json:"missing_facts,omitempty"` // Facts the candidate reads that the record lacks
+	Error          string     `json:"error,omitempty"`
+}
+
+// Report is the difference between audited decisions and a candidate policy's.
+type Report struct {
+	PolicySHA      string             `json:"policy_sha"` // The candidate's
+	Total          Counts             `json:"total"`
+	Deployments    []DeploymentCounts `json:"deployments"` // Sorted by deployment ID
+	ReasonsAdded   []gate.ReasonCount `json:"reasons_added"`
+	ReasonsRemoved []gate.ReasonCount `json:"reasons_removed"`
+	Changes        []Change           `json:"changes"`
+}
+
+// Replay evaluates each record's request and audited facts against candidate, and reports the
+// decisions that change. The input is rebuilt as the gate built it, with the candidate's SHA
+// and the record's timestamp, config SHA, missing optional facts and fact metadata in input.meta.
+//
+// Records only hold the facts the audited policy read. A candidate that reads other facts is
+// evaluated without them, and the records affected are counted in Counts.MissingFacts, since
+// their replayed decisions may not be what the candidate would have decided.
+func Replay(ctx context.Context, engine gate.PolicyEngine, candidate gate.PolicyBundle, records []gate.AuditRecord) (Report, error) {
+	report := Report{PolicySHA: candidate.ID(), Changes: []Change{}}
+	meta := gate.InputMeta{PolicySHA: candidate.ID()}
+	if signed, ok := candidate.(gate.SignedPolicyBundle); ok {
+		meta.PolicyKeyID = signed.SigningKeyID()
+	}
+
+	deployments := map[string]*DeploymentCounts{}
+	added, removed := map[string]int{}, map[string]int{}
+	for _, record := range records {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		deployment, ok := deployments[record.DeploymentID]
+		if !ok {
+			deployment = &DeploymentCounts{DeploymentID: record.DeploymentID}
+			deployments[record.DeploymentID] = deployment
+		}
+		if record.Outcome != gate.OutcomeAllow && record.Outcome != gate.OutcomeDeny {
+			report.Total.Skipped++
+			deployment.Skipped++
+			continue
+		}
+
+		req := gate.DecisionRequest{
+			DeploymentID:   record.DeploymentID,
+			Stage:          record.Stage,
+			RequestedCount: record.RequestedCount,
+			Caller:         record.Caller,
+		}
+		meta.Timestamp, meta.ConfigSHA = record.Timestamp, record.ConfigSHA
+		meta.Missing, meta.Facts = record.MissingFacts, record.FactMeta
+		input := gate.NewPolicyInput(req, record.UsedFacts, meta).Map()
+
+		change := Change{
+			DecisionID:   record.DecisionID,
+			DeploymentID: record.DeploymentID,
+			Stage:        record.Stage,
+			DenyReasons:  record.DenyReasons,
+			MissingFacts: missingFacts(candidate, record.UsedFacts),
+		}
+		decision, err := engine.Evaluate(ctx, candidate, input)
+		if err != nil {
+			change.Kind, change.Error = Failed, err.Error()
+		} else {
+			change.NewDenyReasons = decision.DenyReasons
+			change.Kind = compare(record, decision)
+			for _, reason := range decision.DenyReasons {
+				if !slices.Contains(record.DenyReasons, reason) {
+					added[reason]++
+				}
+			}
+			for _, reason := range record.DenyReasons {
+				if !slices.Contains(decision.DenyReasons, reason) {
+					removed[reason]++
+				}
+			}
+		}
+
+		report.Total.add(change.Kind, len(change.MissingFacts) > 0)
+		deployment.add(change.Kind, len(change.MissingFacts) > 0)
+		if change.Kind != "" {
+			report.Changes = append(report.Changes, change)
+		}
+	}
+
+	report.Deployments = make([]DeploymentCounts, 0, len(deployments))
+	for _, d := range deployments {
+		report.Deployments = append(report.Deployments, *d)
+	}
+	sort.Slice(report.Deployments, func(i, j int) bool {
+		return report.Deployments[i].DeploymentID < report.Deployments[j].DeploymentID
+	})
+	report.ReasonsAdded, report.ReasonsRemoved = gate.ReasonCounts(added), gate.ReasonCounts(removed)
+	return report, nil
+}
+
+// compare returns how decision differs from the audited record, or "" if it does not.
+func compare(record gate.AuditRecord, decision gate.Decision) ChangeKind {
+	switch {
+	case record.Outcome == gate.OutcomeAllow && !decision.Allow:
+		return AllowToDeny
+	case record.Outcome == gate.OutcomeDeny && decision.Allow:
+		return DenyToAllow
+	case !decision.Allow && !sameReasons(record.DenyReasons, decision.DenyReasons):
+		return ReasonsChanged
+	}
+	return ""
+}
+
+func sameReasons(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
+
+// missingFacts returns the facts an OPA candidate references that are not in facts, sorted.
+// Other engines, and policies that may read any fact, are not checked.
+func missingFacts(candidate gate.PolicyBundle, facts map[string]any) []string {
+	b, ok := candidate.(*opa.OpaPolicyBundle)
+	if !ok || b.ReadsAllFacts {
+		return nil
+	}
+	var missing []string
+	for _, fact := range b.FactRefs {
+		if _, ok := facts[fact]; !ok {
+			missing = append(missing, fact)
+		}
+	}
+	return missing
+}
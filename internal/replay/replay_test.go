@@ -0,0 +1,87 @@
+package replay
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/asimihsan/planning_engine/internal/engine/opa"
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+// candidate denies above a tighter limit than main.rego, and also reads error_rate.
+const candidate = `package gate
+
+default allow := false
+
+projected_pending := input.facts.pending_delta + input.request.requested_count
+
+deny_reasons contains "pending_delta exceeds allowed limit" if projected_pending > input.facts.max_pending_allowed
+deny_reasons contains "pending_delta exceeds canary limit" if {
+    input.request.stage == "canary"
+    projected_pending > 200
+}
+deny_reasons contains "error_rate too high" if input.facts.error_rate > 0.05
+
+allow if count(deny_reasons) == 0
+
+response := {"allow": allow, "deny_reasons": deny_reasons}
+`
+
+func record(id, deployment, stage string, outcome gate.Outcome, pending int, reasons ...string) gate.AuditRecord {
+	return gate.AuditRecord{
+		DecisionID:     id,
+		Timestamp:      time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC),
+		DeploymentID:   deployment,
+		Stage:          stage,
+		RequestedCount: 10,
+		Outcome:        outcome,
+		DenyReasons:    reasons,
+		UsedFacts:      map[string]any{"pending_delta": pending, "max_pending_allowed": 500, "error_rate": 0.01},
+	}
+}
+
+func TestReplay(t *testing.T) {
+	ctx := context.Background()
+	bundle, err := opa.Compile(ctx, "candidate.rego", []byte(candidate), "data.gate.response")
+	require.NoError(t, err)
+
+	withoutErrorRate := record("no-error-rate", "dep-b", "prod", gate.OutcomeAllow, 100)
+	delete(withoutErrorRate.UsedFacts, "error_rate")
+
+	records := []gate.AuditRecord{
+		record("unchanged", "dep-a", "prod", gate.OutcomeAllow, 100),
+		record("flipped", "dep-a", "canary", gate.OutcomeAllow, 300),
+		record("more-reasons", "dep-a", "canary", gate.OutcomeDeny, 600, "pending_delta exceeds allowed limit"),
+		record("was-denied", "dep-b", "prod", gate.OutcomeDeny, 100, "pending_delta exceeds allowed limit"),
+		{DecisionID: "error", DeploymentID: "dep-b", Outcome: gate.OutcomeError, ErrorClass: "fact_stale"},
+		withoutErrorRate,
+	}
+
+	report, err := Replay(ctx, opa.NewEngine(), bundle, records)
+	require.NoError(t, err)
+
+	assert.Equal(t, bundle.ID(), report.PolicySHA)
+	assert.Equal(t, Counts{Replayed: 5, Unchanged: 2, AllowToDeny: 1, DenyToAllow: 1, ReasonsChanged: 1, Skipped: 1, MissingFacts: 1}, report.Total)
+	assert.Equal(t, []DeploymentCounts{
+		{DeploymentID: "dep-a", Counts: Counts{Replayed: 3, Unchanged: 1, AllowToDeny: 1, ReasonsChanged: 1}},
+		{DeploymentID: "dep-b", Counts: Counts{Replayed: 2, Unchanged: 1, DenyToAllow: 1, Skipped: 1, MissingFacts: 1}},
+	}, report.Deployments)
+	assert.Equal(t, []gate.ReasonCount{{Reason: "pending_delta exceeds canary limit", Count: 2}}, report.ReasonsAdded)
+	assert.Equal(t, []gate.ReasonCount{{Reason: "pending_delta exceeds allowed limit", Count: 1}}, report.ReasonsRemoved)
+
+	require.Len(t, report.Changes, 3)
+	assert.Equal(t, Change{
+		DecisionID:     "flipped",
+		DeploymentID:   "dep-a",
+		Stage:          "canary",
+		Kind:           AllowToDeny,
+		NewDenyReasons: []string{"pending_delta exceeds canary limit"},
+	}, report.Changes[0])
+	assert.Equal(t, ReasonsChanged, report.Changes[1].Kind)
+	assert.ElementsMatch(t, []string{"pending_delta exceeds allowed limit", "pending_delta exceeds canary limit"}, report.Changes[1].NewDenyReasons)
+	assert.Equal(t, DenyToAllow, report.Changes[2].Kind)
+}
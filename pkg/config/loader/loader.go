@@ -71,3 +71,28 @@ func LoadFromPathWithSHA(ctx context.Context, path string) (*config.AppConfig, s
 
 	return cfg, hashStr, nil
 }
+
+// LoadFromBytes evaluates PKL configuration content, e.g. a config fetched for a release.
+// The content is evaluated from a temporary file, so it must not use relative imports;
+// amend AppConfig.pkl by an absolute or package URI instead.
+func LoadFromBytes(ctx context.Context, content []byte) (*config.AppConfig, error) {
+	f, err := os.CreateTemp("", "planning-engine-config-*.pkl")
+	if err != nil {
+		return nil, fmt.Errorf("%w: creating temporary config file: %v", gate.ErrConfigLoad, err)
+	}
+	defer func() { _ = os.Remove(f.Name()) }()
+
+	if _, err := f.Write(content); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("%w: writing temporary config file: %v", gate.ErrConfigLoad, err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("%w: writing temporary config file: %v", gate.ErrConfigLoad, err)
+	}
+
+	cfg, err := config.LoadFromPath(ctx, f.Name())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", gate.ErrConfigLoad, err)
+	}
+	return cfg, nil
+}
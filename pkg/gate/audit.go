@@ -2,21 +2,155 @@ package gate
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"time"
 )
 
+// Outcome classifies an audited decision attempt.
+type Outcome string
+
+const (
+	OutcomeAllow Outcome = "allow"
+	OutcomeDeny  Outcome = "deny"
+	OutcomeError Outcome = "error" // No trustworthy decision could be made; the caller paused
+)
+
+// AuditRecord is everything recorded about one decision attempt, whether it ended in a
+// decision or an error. Records of one rollout step share DeploymentID and Stage.
+type AuditRecord struct {
+	DecisionID     string    `json:"decision_id"`
+	Timestamp      time.Time `json:"timestamp"`
+	DeploymentID   string    `json:"deployment_id"`
+	Stage          string    `json:"stage"`
+	RequestedCount int       `json:"requested_count"`
+	Caller         string    `json:"caller,omitempty"`
+
+	Outcome      Outcome  `json:"outcome"`
+	DenyReasons  []string `json:"deny_reasons,omitempty"`
+	ErrorClass   string   `json:"error_class,omitempty"`   // See ErrorClass; set for OutcomeError
+	ErrorDetails string   `json:"error_details,omitempty"` // The full error message; set for OutcomeError
+
+	PolicySHA   string `json:"policy_sha,omitempty"` // Empty if the policy bundle could not be loaded
+	PolicyKeyID string `json:"policy_key_id,omitempty"`
+	ConfigSHA   string `json:"config_sha"`
+
+	SnapshotDuration time.Duration `json:"snapshot_duration_ns"` // Time spent collecting facts
+	EvalDuration     time.Duration `json:"eval_duration_ns"`     // Time spent in PolicyEngine.Evaluate
+
+	// UsedFacts holds the facts the policy used, see UsedFactValues. Empty if the attempt failed
+	// before the policy was evaluated. DynamoDB stores it as usedFactsJSON.
+	UsedFacts map[string]any `json:"used_facts,omitempty"`
+	// FactMeta describes how each fact in UsedFacts was collected.
+	FactMeta map[string]FactMeta `json:"fact_meta,omitempty"`
+	// MissingFacts lists the optional facts that could not be collected, see InputMeta.Missing.
+	MissingFacts []string `json:"missing_facts,omitempty"`
+
+	// PrevHash and Hash chain the records of an audit log so that edits can be detected.
+	// They are set by audit loggers that keep such a chain, and are empty otherwise.
+	PrevHash string `json:"prev_hash,omitempty"`
+	Hash     string `json:"hash,omitempty"`
+}
+
 // AuditLogger persists decision and error information.
 type AuditLogger interface {
-	// LogDecision records the outcome of a successful evaluation.
-	// input: the facts map given to Evaluate.
-	// decision: the Decision struct returned by Evaluate.
-	// policyID, configID: identifiers for traceability.
-	// evalDuration: time taken for the Evaluate call.
-	LogDecision(ctx context.Context, input map[string]any, decision Decision, policyID, configID string, evalDuration time.Duration) error
-
-	// LogSystemError records failures occurring outside successful policy evaluation.
-	// systemError: The specific error (e.g., ErrFactStale, ErrPolicyLoad).
-	// deploymentID, stage: Context for the operation attempt.
-	// policyID, configID: Identifiers if available at the time of error.
-	LogSystemError(ctx context.Context, systemError error, deploymentID, stage, policyID, configID string) error
+	// Log records one decision attempt. The Gate acts on a decision only once it has been logged.
+	Log(ctx context.Context, record AuditRecord) error
+}
+
+// AuditQuery selects audit records. Zero fields match every record.
+type AuditQuery struct {
+	DeploymentID string
+	Stage        string
+	Outcome      Outcome
+	PolicySHA    string
+	Since        time.Time // Inclusive
+	Until        time.Time // Exclusive
+	Limit        int       // If positive, only the newest Limit matching records are returned
+}
+
+// Matches reports whether record is selected by q.
+func (q AuditQuery) Matches(record AuditRecord) bool {
+	switch {
+	case q.DeploymentID != "" && record.DeploymentID != q.DeploymentID:
+		return false
+	case q.Stage != "" && record.Stage != q.Stage:
+		return false
+	case q.Outcome != "" && record.Outcome != q.Outcome:
+		return false
+	case q.PolicySHA != "" && record.PolicySHA != q.PolicySHA:
+		return false
+	case !q.Since.IsZero() && record.Timestamp.Before(q.Since):
+		return false
+	case !q.Until.IsZero() && !record.Timestamp.Before(q.Until):
+		return false
+	}
+	return true
+}
+
+// AuditReader reads back the records written by an AuditLogger.
+type AuditReader interface {
+	// Query returns the records matching q, oldest first.
+	Query(ctx context.Context, q AuditQuery) ([]AuditRecord, error)
+}
+
+// ReasonCount is the number of audit records giving one reason.
+type ReasonCount struct {
+	Reason string `json:"reason"`
+	Count  int    `json:"count"`
+}
+
+// CountReasons groups records by why they did not allow: each deny reason of a deny, and the
+// error class of an error. The most frequent reasons come first.
+func CountReasons(records []AuditRecord) []ReasonCount {
+	counts := map[string]int{}
+	for _, record := range records {
+		switch record.Outcome {
+		case OutcomeDeny:
+			for _, reason := range record.DenyReasons {
+				counts[reason]++
+			}
+		case OutcomeError:
+			counts["error: "+record.ErrorClass]++
+		}
+	}
+
+	return ReasonCounts(counts)
+}
+
+// ReasonCounts converts counts keyed by reason to ReasonCounts, most frequent first.
+func ReasonCounts(counts map[string]int) []ReasonCount {
+	reasons := make([]ReasonCount, 0, len(counts))
+	for reason, count := range counts {
+		reasons = append(reasons, ReasonCount{Reason: reason, Count: count})
+	}
+	sort.Slice(reasons, func(i, j int) bool {
+		if reasons[i].Count != reasons[j].Count {
+			return reasons[i].Count > reasons[j].Count
+		}
+		return reasons[i].Reason < reasons[j].Reason
+	})
+	return reasons
+}
+
+// ParseAuditTime parses a time bound of an AuditQuery: an RFC 3339 timestamp, or a duration
+// such as "6h" meaning that long before now.
+func ParseAuditTime(s string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %q is neither an RFC 3339 time nor a duration", ErrInvalidRequest, s)
+	}
+	return t, nil
+}
+
+// LimitRecords returns the newest limit of records ordered oldest first, or all of them if
+// limit is not positive.
+func LimitRecords(records []AuditRecord, limit int) []AuditRecord {
+	if limit > 0 && len(records) > limit {
+		return records[len(records)-limit:]
+	}
+	return records
 }
@@ -0,0 +1,64 @@
+package gate
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAuditQuery_Matches(t *testing.T) {
+	at := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+	record := AuditRecord{DeploymentID: "dep", Stage: "canary", Outcome: OutcomeDeny, PolicySHA: "sha", Timestamp: at}
+
+	tests := []struct {
+		name  string
+		query AuditQuery
+		want  bool
+	}{
+		{"Empty query", AuditQuery{}, true},
+		{"All fields", AuditQuery{DeploymentID: "dep", Stage: "canary", Outcome: OutcomeDeny, PolicySHA: "sha", Since: at, Until: at.Add(time.Second)}, true},
+		{"Other deployment", AuditQuery{DeploymentID: "other"}, false},
+		{"Other stage", AuditQuery{Stage: "prod"}, false},
+		{"Other outcome", AuditQuery{Outcome: OutcomeAllow}, false},
+		{"Other policy", AuditQuery{PolicySHA: "other"}, false},
+		{"Before range", AuditQuery{Since: at.Add(time.Nanosecond)}, false},
+		{"Until is exclusive", AuditQuery{Until: at}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.query.Matches(record); got != tt.want {
+			t.Errorf("%s: Matches() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCountReasons(t *testing.T) {
+	records := []AuditRecord{
+		{Outcome: OutcomeDeny, DenyReasons: []string{"pending_delta exceeds allowed limit", "error_rate too high"}},
+		{Outcome: OutcomeDeny, DenyReasons: []string{"pending_delta exceeds allowed limit"}},
+		{Outcome: OutcomeError, ErrorClass: "fact_stale"},
+		{Outcome: OutcomeAllow},
+	}
+	want := []ReasonCount{
+		{Reason: "pending_delta exceeds allowed limit", Count: 2},
+		{Reason: "error: fact_stale", Count: 1},
+		{Reason: "error_rate too high", Count: 1},
+	}
+	if got := CountReasons(records); !reflect.DeepEqual(got, want) {
+		t.Errorf("CountReasons() = %v, want %v", got, want)
+	}
+}
+
+func TestParseAuditTime(t *testing.T) {
+	now := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	if got, err := ParseAuditTime("6h", now); err != nil || !got.Equal(now.Add(-6*time.Hour)) {
+		t.Errorf(`ParseAuditTime("6h") = %v, %v`, got, err)
+	}
+	if got, err := ParseAuditTime("2025-03-31T08:30:00Z", now); err != nil || !got.Equal(time.Date(2025, 3, 31, 8, 30, 0, 0, time.UTC)) {
+		t.Errorf("ParseAuditTime(RFC 3339) = %v, %v", got, err)
+	}
+	if _, err := ParseAuditTime("yesterday", now); !errors.Is(err, ErrInvalidRequest) {
+		t.Errorf(`ParseAuditTime("yesterday") error = %v, want ErrInvalidRequest`, err)
+	}
+}
@@ -6,9 +6,44 @@ import (
 
 // Decision represents the outcome of a policy evaluation.
 type Decision struct {
-	Allow        bool          // Whether the operation is allowed
-	DenyReasons  []string      // Machine-readable explanations if Allow is false
-	PolicySHA    string        // Identifier for the policy version used
-	ConfigSHA    string        // Identifier for the configuration version used
-	EvalDuration time.Duration // How long the evaluation took
+	DecisionID   string        `json:"decision_id"`             // Identifies the decision's audit record
+	Allow        bool          `json:"allow"`                   // Whether the operation is allowed
+	DenyReasons  []string      `json:"deny_reasons"`            // Machine-readable explanations if Allow is false
+	PolicySHA    string        `json:"policy_sha"`              // Identifier for the policy version used
+	PolicyKeyID  string        `json:"policy_key_id,omitempty"` // Key that signed the policy bundle, if verified
+	ConfigSHA    string        `json:"config_sha"`              // Identifier for the configuration version used
+	UsedFacts    []string      `json:"used_facts,omitempty"`    // Facts the policy read; nil if the engine does not track them
+	EvalDuration time.Duration `json:"eval_duration_ns"`        // How long the evaluation took
+}
+
+// UsedFactValues returns the subset of a policy input's facts that decision's evaluation read,
+// for audit records. If the engine did not report UsedFacts, every fact is returned.
+func UsedFactValues(input map[string]any, decision Decision) map[string]any {
+	facts, _ := input["facts"].(map[string]any)
+	if decision.UsedFacts == nil {
+		return facts
+	}
+
+	used := make(map[string]any, len(decision.UsedFacts))
+	for _, id := range decision.UsedFacts {
+		if value, ok := facts[id]; ok {
+			used[id] = value
+		}
+	}
+	return used
+}
+
+// UsedFactMeta returns the metadata of the facts in used, as returned by UsedFactValues, for
+// audit records. It returns nil if there is none.
+func UsedFactMeta(meta map[string]FactMeta, used map[string]any) map[string]FactMeta {
+	var out map[string]FactMeta
+	for id := range used {
+		if m, ok := meta[id]; ok {
+			if out == nil {
+				out = make(map[string]FactMeta, len(used))
+			}
+			out[id] = m
+		}
+	}
+	return out
 }
@@ -0,0 +1,39 @@
+package gate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUsedFactValues(t *testing.T) {
+	input := NewPolicyInput(DecisionRequest{}, map[string]any{"pending_delta": 100, "error_rate": 0.01}, InputMeta{}).Map()
+
+	got := UsedFactValues(input, Decision{UsedFacts: []string{"pending_delta"}})
+	if want := map[string]any{"pending_delta": 100}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected only used facts, got %v", got)
+	}
+
+	got = UsedFactValues(input, Decision{UsedFacts: []string{}})
+	if len(got) != 0 {
+		t.Errorf("Expected no facts when none were used, got %v", got)
+	}
+
+	// Engines that do not track usage audit every fact
+	got = UsedFactValues(input, Decision{})
+	if len(got) != 2 {
+		t.Errorf("Expected all facts when usage is unknown, got %v", got)
+	}
+}
+
+func TestUsedFactMeta(t *testing.T) {
+	meta := map[string]FactMeta{"pending_delta": {Source: "levelsrv"}, "error_rate": {CacheHit: true}}
+
+	got := UsedFactMeta(meta, map[string]any{"pending_delta": 100})
+	if want := map[string]FactMeta{"pending_delta": {Source: "levelsrv"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected only the metadata of used facts, got %v", got)
+	}
+
+	if got := UsedFactMeta(meta, map[string]any{}); got != nil {
+		t.Errorf("Expected nil when no facts were used, got %v", got)
+	}
+}
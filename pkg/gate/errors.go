@@ -1,16 +1,166 @@
 package gate
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
 // Standard error types for gate operations
 var (
 	ErrFactSourceUnavailable = errors.New("gate: fact source unavailable")
 	ErrFactStale             = errors.New("gate: fact data is stale")
+	ErrFactInvalid           = errors.New("gate: fact value does not match its schema")
 	ErrPolicyEvaluation      = errors.New("gate: policy evaluation failed")
 	ErrPolicyLoad            = errors.New("gate: policy bundle could not be loaded")
 	ErrConfigLoad            = errors.New("gate: configuration could not be loaded")
+	ErrAuditLog              = errors.New("gate: decision could not be audited")
+	ErrInvalidRequest        = errors.New("gate: invalid decision request")
+	ErrInputSchema           = errors.New("gate: policy input does not match its schema")
 )
 
+// errorClasses maps each sentinel to the class reported by ErrorClass, in match order.
+var errorClasses = []struct {
+	err   error
+	class string
+}{
+	{ErrInvalidRequest, "invalid_request"},
+	{ErrFactStale, "fact_stale"},
+	{ErrFactSourceUnavailable, "fact_source_unavailable"},
+	{ErrFactInvalid, "fact_invalid"},
+	{ErrPolicyLoad, "policy_load"},
+	{ErrPolicyEvaluation, "policy_evaluation"},
+	{ErrConfigLoad, "config_load"},
+	{ErrAuditLog, "audit_log"},
+	{ErrInputSchema, "input_schema"},
+}
+
+// ErrorClass returns a short, stable name for the sentinel err wraps, e.g. "fact_stale",
+// or "other" if it wraps none. It is suitable for grouping audit records and metric labels.
+func ErrorClass(err error) string {
+	for _, c := range errorClasses {
+		if errors.Is(err, c.err) {
+			return c.class
+		}
+	}
+	return "other"
+}
+
+// PauseError is returned by Gate.Decide when no trustworthy decision could be made,
+// e.g. facts were stale or unavailable, or the policy could not be loaded or evaluated.
+// Callers must pause the affected deployment stage and alert rather than treat it as a deny.
+type PauseError struct {
+	DecisionID   string // Identifies the audit record of the failed attempt
+	DeploymentID string
+	Stage        string
+	PolicySHA    string // Empty if the policy bundle could not be loaded
+	ConfigSHA    string
+	Err          error // The underlying cause, e.g. wrapping ErrFactStale
+}
+
+func (e *PauseError) Error() string {
+	return fmt.Sprintf("gate: pause %s/%s: %v", e.DeploymentID, e.Stage, e.Err)
+}
+
+func (e *PauseError) Unwrap() error { return e.Err }
+
+// SchemaViolation is one way a policy input fails its policy's input schema.
+type SchemaViolation struct {
+	Field   string // Path of the offending field, e.g. "facts.pending_delta"
+	Message string // e.g. "Invalid type. Expected: integer, given: string"
+}
+
+// InputSchemaError is returned when a policy input does not match the input schema of a
+// SchemaPolicyBundle. It lists every violation and wraps ErrInputSchema.
+type InputSchemaError struct {
+	Violations []SchemaViolation
+}
+
+func (e *InputSchemaError) Error() string {
+	var b strings.Builder
+	b.WriteString(ErrInputSchema.Error())
+	for i, v := range e.Violations {
+		if i == 0 {
+			b.WriteString(": ")
+		} else {
+			b.WriteString("; ")
+		}
+		fmt.Fprintf(&b, "%s: %s", v.Field, v.Message)
+	}
+	return b.String()
+}
+
+func (e *InputSchemaError) Unwrap() error { return ErrInputSchema }
+
+// FactErrorKind classifies why a fact could not be collected.
+type FactErrorKind string
+
+// Fact error kinds.
+const (
+	FactErrorStale       FactErrorKind = "stale"
+	FactErrorUnavailable FactErrorKind = "unavailable"
+	FactErrorTimeout     FactErrorKind = "timeout"
+	FactErrorOther       FactErrorKind = "other"
+)
+
+// FactError is the failure to collect one fact.
+type FactError struct {
+	FactID   string
+	Kind     FactErrorKind
+	Optional bool  // The fact's schema marks it optional, so it did not fail the snapshot
+	Err      error // e.g. wrapping ErrFactStale
+}
+
+func (e *FactError) Error() string {
+	return fmt.Sprintf("collecting fact %s: %v", e.FactID, e.Err)
+}
+
+func (e *FactError) Unwrap() error { return e.Err }
+
+// Is reports a timeout as ErrFactSourceUnavailable, whether or not the provider wrapped it.
+func (e *FactError) Is(target error) bool {
+	return target == ErrFactSourceUnavailable && e.Kind == FactErrorTimeout
+}
+
+// SnapshotError is returned when a required fact could not be collected. It lists every
+// fact that failed, required or optional, sorted by fact ID. errors.Is matches the errors
+// of the required facts, e.g. ErrFactStale.
+type SnapshotError struct {
+	Errors []*FactError
+}
+
+func (e *SnapshotError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "collecting %d facts failed", len(e.Errors))
+	for i, fe := range e.Errors {
+		if i == 0 {
+			b.WriteString(": ")
+		} else {
+			b.WriteString("; ")
+		}
+		fmt.Fprintf(&b, "%s (%s", fe.FactID, fe.Kind)
+		if fe.Optional {
+			b.WriteString(", optional")
+		}
+		fmt.Fprintf(&b, "): %v", fe.Err)
+	}
+	return b.String()
+}
+
+// Unwrap returns the errors of the required facts.
+func (e *SnapshotError) Unwrap() []error {
+	var errs []error
+	for _, fe := range e.Errors {
+		if !fe.Optional {
+			errs = append(errs, fe)
+		}
+	}
+	return errs
+}
+
 // IsWrappingError checks if err is wrapping the target error using errors.Is.
 // This is a helper for testing error wrapping.
 func IsWrappingError(err, target error) bool {
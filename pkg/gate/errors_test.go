@@ -0,0 +1,66 @@
+package gate
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestErrorClass(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{ErrFactStale, "fact_stale"},
+		{fmt.Errorf("%w: pending_delta: timeout", ErrFactSourceUnavailable), "fact_source_unavailable"},
+		{fmt.Errorf("collecting fact pending_delta: %w", ErrFactInvalid), "fact_invalid"},
+		{&PauseError{Err: ErrPolicyLoad}, "policy_load"},
+		{errors.Join(ErrPolicyEvaluation, ErrAuditLog), "policy_evaluation"},
+		{&InputSchemaError{}, "input_schema"},
+		{errors.New("boom"), "other"},
+	}
+	for _, tt := range tests {
+		if got := ErrorClass(tt.err); got != tt.want {
+			t.Errorf("ErrorClass(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestSnapshotError(t *testing.T) {
+	err := &SnapshotError{Errors: []*FactError{
+		{FactID: "error_rate", Kind: FactErrorTimeout, Err: errors.New("request canceled")},
+		{FactID: "pending_delta", Kind: FactErrorStale, Err: ErrFactStale},
+		{FactID: "canary_health", Kind: FactErrorOther, Optional: true, Err: ErrFactInvalid},
+	}}
+	want := "collecting 3 facts failed: error_rate (timeout): request canceled; " +
+		"pending_delta (stale): gate: fact data is stale; " +
+		"canary_health (other, optional): gate: fact value does not match its schema"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+
+	wrapped := fmt.Errorf("wrapped: %w", err)
+	if !errors.Is(wrapped, ErrFactStale) || !errors.Is(wrapped, ErrFactSourceUnavailable) {
+		t.Error("Expected SnapshotError to match the errors of its required facts, with timeouts as unavailable")
+	}
+	if errors.Is(wrapped, ErrFactInvalid) {
+		t.Error("Expected SnapshotError not to match the errors of optional facts")
+	}
+	if got := ErrorClass(wrapped); got != "fact_stale" {
+		t.Errorf("ErrorClass = %q, want fact_stale", got)
+	}
+}
+
+func TestInputSchemaError(t *testing.T) {
+	err := &InputSchemaError{Violations: []SchemaViolation{
+		{Field: "facts.pending_delta", Message: "Must be greater than or equal to 0"},
+		{Field: "facts", Message: "max_pending_allowed is required"},
+	}}
+	want := "gate: policy input does not match its schema: facts.pending_delta: Must be greater than or equal to 0; facts: max_pending_allowed is required"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+	if !errors.Is(fmt.Errorf("wrapped: %w", err), ErrInputSchema) {
+		t.Error("Expected InputSchemaError to wrap ErrInputSchema")
+	}
+}
@@ -2,6 +2,10 @@ package gate
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
 	"time"
 )
 
@@ -12,10 +16,129 @@ type Fact interface {
 	Timestamp() time.Time // When the fact data was considered current
 }
 
+// ValueType is the JSON type of a fact's value.
+type ValueType string
+
+// Value types. The zero value accepts any value.
+const (
+	TypeAny     ValueType = ""
+	TypeInteger ValueType = "integer"
+	TypeNumber  ValueType = "number"
+	TypeString  ValueType = "string"
+	TypeBoolean ValueType = "boolean"
+	TypeObject  ValueType = "object"
+	TypeArray   ValueType = "array"
+)
+
+// Criticality says whether a decision can be made without a fact.
+type Criticality string
+
+// Criticalities. The zero value is CriticalityRequired.
+const (
+	CriticalityRequired Criticality = "required"
+	CriticalityOptional Criticality = "optional"
+)
+
 // Schema provides metadata about a Fact or input structure.
 type Schema struct {
 	ID          string
 	Description string
+	Type        ValueType   // JSON type of the value; TypeAny if unchecked
+	Unit        string      // e.g. "devices" or "ratio"
+	Minimum     *float64    // inclusive lower bound of a numeric value, if any
+	Maximum     *float64    // inclusive upper bound of a numeric value, if any
+	Criticality Criticality // empty means CriticalityRequired
+	// MaxStaleness and Timeout are the fact's own limits; zero => SnapshotOpts' defaults apply
+	MaxStaleness time.Duration
+	Timeout      time.Duration
+	Owner        string // team or system answerable for the fact, e.g. "levelsrv"
+}
+
+// Bound returns a pointer to v, for Schema.Minimum and Schema.Maximum.
+func Bound(v float64) *float64 { return &v }
+
+// Optional reports whether a decision can be made without the fact.
+func (s Schema) Optional() bool { return s.Criticality == CriticalityOptional }
+
+// JSONSchema returns the JSON Schema fragment describing the fact's value, as it appears
+// under input.facts in a policy's input schema.
+func (s Schema) JSONSchema() map[string]any {
+	fragment := map[string]any{}
+	if s.Type != TypeAny {
+		fragment["type"] = string(s.Type)
+	}
+	if s.Minimum != nil {
+		fragment["minimum"] = *s.Minimum
+	}
+	if s.Maximum != nil {
+		fragment["maximum"] = *s.Maximum
+	}
+	if s.Description != "" {
+		fragment["description"] = s.Description
+	}
+	return fragment
+}
+
+// Validate checks value against the schema's type and range. Errors wrap ErrFactInvalid.
+func (s Schema) Validate(value any) error {
+	if s.Type == TypeAny {
+		return nil
+	}
+	if value == nil {
+		return fmt.Errorf("%w: %s: expected %s, got null", ErrFactInvalid, s.ID, s.Type)
+	}
+
+	num, isNum := numericValue(value)
+	var ok bool
+	switch s.Type {
+	case TypeInteger:
+		ok = isNum && num == math.Trunc(num)
+	case TypeNumber:
+		ok = isNum
+	case TypeString:
+		_, ok = value.(string)
+	case TypeBoolean:
+		_, ok = value.(bool)
+	case TypeObject:
+		kind := reflect.TypeOf(value).Kind()
+		ok = kind == reflect.Map || kind == reflect.Struct
+	case TypeArray:
+		kind := reflect.TypeOf(value).Kind()
+		ok = kind == reflect.Slice || kind == reflect.Array
+	default:
+		return fmt.Errorf("%w: %s: unknown type %q", ErrFactInvalid, s.ID, s.Type)
+	}
+	if !ok {
+		return fmt.Errorf("%w: %s: expected %s, got %T %v", ErrFactInvalid, s.ID, s.Type, value, value)
+	}
+
+	if isNum && s.Minimum != nil && num < *s.Minimum {
+		return fmt.Errorf("%w: %s: %v is below the minimum %v", ErrFactInvalid, s.ID, value, *s.Minimum)
+	}
+	if isNum && s.Maximum != nil && num > *s.Maximum {
+		return fmt.Errorf("%w: %s: %v is above the maximum %v", ErrFactInvalid, s.ID, value, *s.Maximum)
+	}
+	return nil
+}
+
+// numericValue returns value as a float64 if it is a Go or JSON number.
+func numericValue(value any) (float64, bool) {
+	if n, ok := value.(json.Number); ok {
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		return f, !math.IsNaN(f) && !math.IsInf(f, 0)
+	default:
+		return 0, false
+	}
 }
 
 // FactProvider fetches or calculates a specific Fact.
@@ -26,16 +149,49 @@ type FactProvider interface {
 	Collect(ctx context.Context, deploymentID, stage string) (Fact, error)
 }
 
+// SourcedFact is implemented by facts that report where their value came from.
+type SourcedFact interface {
+	Fact
+	Source() string // e.g. the URL the value was fetched from; empty if unknown
+	CacheHit() bool // The value was served from the provider's cache
+}
+
+// FactMeta describes how a fact was collected for one snapshot.
+type FactMeta struct {
+	CollectedAt time.Time     `json:"collected_at"` // The fact's Timestamp
+	Age         time.Duration `json:"age_ns"`       // How old the fact was when the registry received it
+	Latency     time.Duration `json:"latency_ns"`   // Time spent in FactProvider.Collect
+	CacheHit    bool          `json:"cache_hit"`
+	Source      string        `json:"source,omitempty"`
+}
+
+// Map renders the metadata as it appears under input.meta.facts.
+func (m FactMeta) Map() map[string]any {
+	return map[string]any{
+		"collected_at": m.CollectedAt.UTC().Format(time.RFC3339Nano),
+		"age_ns":       m.Age.Nanoseconds(),
+		"latency_ns":   m.Latency.Nanoseconds(),
+		"cache_hit":    m.CacheHit,
+		"source":       m.Source,
+	}
+}
+
 // BasicFact is a concrete implementation of the Fact interface
 type BasicFact struct {
-	FactID    string
-	FactValue any
-	FactTime  time.Time
+	FactID       string
+	FactValue    any
+	FactTime     time.Time
+	FactSource   string // Optional, see SourcedFact
+	FactCacheHit bool
 }
 
+var _ SourcedFact = BasicFact{}
+
 func (f BasicFact) ID() string           { return f.FactID }
 func (f BasicFact) Value() any           { return f.FactValue }
 func (f BasicFact) Timestamp() time.Time { return f.FactTime }
+func (f BasicFact) Source() string       { return f.FactSource }
+func (f BasicFact) CacheHit() bool       { return f.FactCacheHit }
 
 // NewFact creates a new Fact with the given ID, value, and timestamp
 func NewFact(id string, value any, timestamp time.Time) Fact {
@@ -1,6 +1,10 @@
 package gate
 
 import (
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -27,3 +31,52 @@ func TestBasicFact(t *testing.T) {
 		t.Errorf("Expected timestamp %v, got %v", now, fact.Timestamp())
 	}
 }
+
+func TestSchema_Validate(t *testing.T) {
+	count := Schema{ID: "pending_delta", Type: TypeInteger, Minimum: Bound(0), Maximum: Bound(1000)}
+
+	tests := []struct {
+		name   string
+		schema Schema
+		value  any
+		valid  bool
+	}{
+		{"integer", count, 100, true},
+		{"integral float", count, 100.0, true},
+		{"JSON number", count, json.Number("100"), true},
+		{"fraction", count, 1.5, false},
+		{"string", count, "100", false},
+		{"null", count, nil, false},
+		{"below minimum", count, -1, false},
+		{"above maximum", count, uint(1001), false},
+		{"number", Schema{Type: TypeNumber, Maximum: Bound(1)}, 0.05, true},
+		{"NaN", Schema{Type: TypeNumber}, math.NaN(), false},
+		{"string type", Schema{Type: TypeString}, "canary", true},
+		{"boolean type", Schema{Type: TypeBoolean}, 1, false},
+		{"object type", Schema{Type: TypeObject}, map[string]any{}, true},
+		{"array type", Schema{Type: TypeArray}, []string{"a"}, true},
+		{"any type", Schema{}, struct{}{}, true},
+	}
+
+	for _, tt := range tests {
+		err := tt.schema.Validate(tt.value)
+		if tt.valid && err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+		if !tt.valid && !errors.Is(err, ErrFactInvalid) {
+			t.Errorf("%s: expected ErrFactInvalid, got %v", tt.name, err)
+		}
+	}
+}
+
+func TestSchema_JSONSchema(t *testing.T) {
+	schema := Schema{ID: "pending_delta", Description: "Devices pending", Type: TypeInteger, Unit: "devices", Minimum: Bound(0)}
+	want := map[string]any{"type": "integer", "minimum": 0.0, "description": "Devices pending"}
+	if got := schema.JSONSchema(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	if got := (Schema{ID: "anything"}).JSONSchema(); len(got) != 0 {
+		t.Errorf("Expected an empty fragment, got %v", got)
+	}
+}
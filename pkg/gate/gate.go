@@ -0,0 +1,252 @@
+package gate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DecisionRequest identifies the rollout step asking "is it safe to target N more devices?".
+type DecisionRequest struct {
+	DeploymentID   string
+	Stage          string
+	RequestedCount int    // Number of additional devices the caller wants to target
+	Caller         string // Optional identity of the requesting worker, for policy and audit
+}
+
+// Validate reports whether the request is well-formed. Errors wrap ErrInvalidRequest.
+func (r DecisionRequest) Validate() error {
+	if r.DeploymentID == "" || r.Stage == "" {
+		return fmt.Errorf("%w: deployment ID and stage are required", ErrInvalidRequest)
+	}
+	if r.RequestedCount < 0 {
+		return fmt.Errorf("%w: requested count must not be negative", ErrInvalidRequest)
+	}
+	return nil
+}
+
+// BatchResult is the outcome of a single request within Gate.DecideBatch.
+type BatchResult struct {
+	Decision Decision
+	Err      error
+}
+
+// DecisionObserver is notified of every audited decision attempt, e.g. to export metrics.
+// It may be called from concurrent requests, so it must be safe for concurrent use.
+type DecisionObserver interface {
+	// DecisionAudited reports an attempt once the Gate has tried to audit it. auditErr is the
+	// error AuditLogger.Log returned, if any; the caller was then told to pause.
+	DecisionAudited(record AuditRecord, auditErr error)
+}
+
+// Gate owns the snapshot → evaluate → audit pipeline so callers cannot forget a step.
+// Every well-formed request is audited, whether it ends in a decision or a pause.
+type Gate struct {
+	registry  *FactRegistry
+	engine    PolicyEngine
+	releases  ReleaseProvider
+	audit     AuditLogger
+	observer  DecisionObserver
+	opts      SnapshotOpts
+	configSHA string // Reported when no release could be loaded
+}
+
+// NewGate creates a Gate from its components.
+// configSHA identifies the configuration the registry's providers were built from.
+func NewGate(
+	registry *FactRegistry,
+	engine PolicyEngine,
+	policies PolicyProvider,
+	audit AuditLogger,
+	opts SnapshotOpts,
+	configSHA string,
+) *Gate {
+	g := NewReleaseGate(registry, engine, policyRelease{policies: policies, configSHA: configSHA}, audit, opts)
+	g.configSHA = configSHA
+	return g
+}
+
+// NewReleaseGate creates a Gate whose policy and configuration are swapped together.
+// Each decision reads the current Release once, so it sees a consistent (bundle, config) pair.
+func NewReleaseGate(
+	registry *FactRegistry,
+	engine PolicyEngine,
+	releases ReleaseProvider,
+	audit AuditLogger,
+	opts SnapshotOpts,
+) *Gate {
+	return &Gate{
+		registry: registry,
+		engine:   engine,
+		releases: releases,
+		audit:    audit,
+		opts:     opts,
+	}
+}
+
+// WithObserver sets the observer notified of every audited decision attempt.
+func (g *Gate) WithObserver(observer DecisionObserver) *Gate {
+	g.observer = observer
+	return g
+}
+
+// Decide collects facts, evaluates the current policy and audits the outcome.
+//
+// Invalid requests are rejected with an error wrapping ErrInvalidRequest and are not audited.
+// Fact, policy and audit failures are returned as a *PauseError; the returned Decision then
+// denies and carries whatever versions were known, so ignoring the error still fails safe.
+func (g *Gate) Decide(ctx context.Context, req DecisionRequest) (Decision, error) {
+	if err := req.Validate(); err != nil {
+		return Decision{ConfigSHA: g.configSHA}, err
+	}
+
+	release, err := g.releases.CurrentRelease(ctx)
+	if err != nil {
+		record := newAuditRecord(req)
+		record.ConfigSHA = g.configSHA
+		return Decision{DecisionID: record.DecisionID, ConfigSHA: g.configSHA}, g.pause(ctx, record, err)
+	}
+
+	return g.decide(ctx, release, req)
+}
+
+// DecideBatch evaluates several requests against the same release.
+// Results are returned in request order; each carries the error Decide would have returned.
+func (g *Gate) DecideBatch(ctx context.Context, reqs []DecisionRequest) []BatchResult {
+	results := make([]BatchResult, len(reqs))
+
+	release, releaseErr := g.releases.CurrentRelease(ctx)
+	for i, req := range reqs {
+		if err := req.Validate(); err != nil {
+			results[i] = BatchResult{Decision: Decision{ConfigSHA: g.configSHA}, Err: err}
+			continue
+		}
+		if releaseErr != nil {
+			record := newAuditRecord(req)
+			record.ConfigSHA = g.configSHA
+			results[i] = BatchResult{
+				Decision: Decision{DecisionID: record.DecisionID, ConfigSHA: g.configSHA},
+				Err:      g.pause(ctx, record, releaseErr),
+			}
+			continue
+		}
+		decision, err := g.decide(ctx, release, req)
+		results[i] = BatchResult{Decision: decision, Err: err}
+	}
+	return results
+}
+
+// PolicyVersion reports the policy and configuration versions decisions are currently stamped with.
+func (g *Gate) PolicyVersion(ctx context.Context) (policySHA, configSHA string, err error) {
+	release, err := g.releases.CurrentRelease(ctx)
+	if err != nil {
+		return "", g.configSHA, err
+	}
+	return release.Bundle.ID(), release.ConfigSHA, nil
+}
+
+// newAuditRecord starts the audit record of a decision attempt for req.
+func newAuditRecord(req DecisionRequest) AuditRecord {
+	return AuditRecord{
+		DecisionID:     uuid.NewString(),
+		Timestamp:      time.Now(),
+		DeploymentID:   req.DeploymentID,
+		Stage:          req.Stage,
+		RequestedCount: req.RequestedCount,
+		Caller:         req.Caller,
+	}
+}
+
+func (g *Gate) decide(ctx context.Context, release *Release, req DecisionRequest) (Decision, error) {
+	record := newAuditRecord(req)
+	record.PolicySHA = release.Bundle.ID()
+	record.PolicyKeyID = signingKeyID(release.Bundle)
+	record.ConfigSHA = release.ConfigSHA
+	failed := Decision{DecisionID: record.DecisionID, PolicySHA: record.PolicySHA, ConfigSHA: record.ConfigSHA}
+
+	start := time.Now()
+	snapshot, err := g.registry.Collect(WithRelease(ctx, release), req.DeploymentID, req.Stage, g.opts)
+	record.SnapshotDuration = time.Since(start)
+	if err != nil {
+		return failed, g.pause(ctx, record, err)
+	}
+	record.MissingFacts = snapshot.Missing
+
+	input := NewPolicyInput(req, snapshot.Facts, InputMeta{
+		Timestamp:   record.Timestamp,
+		PolicySHA:   record.PolicySHA,
+		PolicyKeyID: record.PolicyKeyID,
+		ConfigSHA:   record.ConfigSHA,
+		Missing:     snapshot.Missing,
+		Facts:       snapshot.Meta,
+	}).Map()
+	if err := validateInput(ctx, release.Bundle, input); err != nil {
+		// Facts the policy was not written for cannot be trusted to produce a safe decision
+		return failed, g.pause(ctx, record, err)
+	}
+
+	start = time.Now()
+	decision, err := g.engine.Evaluate(ctx, release.Bundle, input)
+	record.EvalDuration = time.Since(start)
+	if err != nil {
+		return failed, g.pause(ctx, record, err)
+	}
+
+	decision.DecisionID = record.DecisionID
+	decision.PolicySHA = record.PolicySHA
+	decision.PolicyKeyID = record.PolicyKeyID
+	decision.ConfigSHA = record.ConfigSHA
+	decision.EvalDuration = record.EvalDuration
+
+	record.Outcome = OutcomeAllow
+	if !decision.Allow {
+		record.Outcome = OutcomeDeny
+		record.DenyReasons = decision.DenyReasons
+	}
+	record.UsedFacts = UsedFactValues(input, decision)
+	record.FactMeta = UsedFactMeta(snapshot.Meta, record.UsedFacts)
+
+	if err := g.log(ctx, record); err != nil {
+		// An unaudited decision must not be acted on
+		return failed, &PauseError{
+			DecisionID:   record.DecisionID,
+			DeploymentID: record.DeploymentID,
+			Stage:        record.Stage,
+			PolicySHA:    record.PolicySHA,
+			ConfigSHA:    record.ConfigSHA,
+			Err:          fmt.Errorf("%w: %v", ErrAuditLog, err),
+		}
+	}
+
+	return decision, nil
+}
+
+// pause audits a system error and wraps it in a PauseError.
+func (g *Gate) pause(ctx context.Context, record AuditRecord, cause error) error {
+	record.Outcome = OutcomeError
+	record.ErrorClass = ErrorClass(cause)
+	record.ErrorDetails = cause.Error()
+	if err := g.log(ctx, record); err != nil {
+		cause = errors.Join(cause, fmt.Errorf("%w: %v", ErrAuditLog, err))
+	}
+	return &PauseError{
+		DecisionID:   record.DecisionID,
+		DeploymentID: record.DeploymentID,
+		Stage:        record.Stage,
+		PolicySHA:    record.PolicySHA,
+		ConfigSHA:    record.ConfigSHA,
+		Err:          cause,
+	}
+}
+
+// log audits record and notifies the observer, if any.
+func (g *Gate) log(ctx context.Context, record AuditRecord) error {
+	err := g.audit.Log(ctx, record)
+	if g.observer != nil {
+		g.observer.DecisionAudited(record, err)
+	}
+	return err
+}
@@ -0,0 +1,483 @@
+package gate
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type stubBundle struct{ id string }
+
+func (b stubBundle) ID() string   { return b.id }
+func (b stubBundle) Data() []byte { return nil }
+
+type signedStubBundle struct {
+	stubBundle
+	keyID string
+}
+
+func (b signedStubBundle) SigningKeyID() string { return b.keyID }
+
+// schemaStubBundle rejects every input with its violations.
+type schemaStubBundle struct {
+	stubBundle
+	violations []SchemaViolation
+}
+
+func (b schemaStubBundle) ValidateInput(context.Context, map[string]any) error {
+	if len(b.violations) == 0 {
+		return nil
+	}
+	return &InputSchemaError{Violations: b.violations}
+}
+
+type stubPolicyProvider struct {
+	bundle PolicyBundle
+	err    error
+}
+
+func (p *stubPolicyProvider) GetPolicyBundle(context.Context) (PolicyBundle, error) {
+	return p.bundle, p.err
+}
+
+// stubEngine allows when pending_delta + requested_count <= max_pending_allowed, mirroring policy/rego/main.rego.
+type stubEngine struct {
+	err   error
+	input map[string]any // The last input evaluated
+}
+
+func (e *stubEngine) Evaluate(_ context.Context, _ PolicyBundle, input map[string]any) (Decision, error) {
+	e.input = input
+	if e.err != nil {
+		return Decision{}, e.err
+	}
+	facts := input["facts"].(map[string]any)
+	requested := input["request"].(map[string]any)["requested_count"].(int)
+	if facts["pending_delta"].(int)+requested <= facts["max_pending_allowed"].(int) {
+		return Decision{Allow: true}, nil
+	}
+	return Decision{Allow: false, DenyReasons: []string{"pending_delta exceeds allowed limit"}}, nil
+}
+
+type recordingAuditLogger struct {
+	decisions    []AuditRecord
+	systemErrors []AuditRecord
+	err          error
+}
+
+func (l *recordingAuditLogger) Log(_ context.Context, record AuditRecord) error {
+	if record.Outcome == OutcomeError {
+		l.systemErrors = append(l.systemErrors, record)
+	} else {
+		l.decisions = append(l.decisions, record)
+	}
+	return l.err
+}
+
+func newTestGate(pendingDelta any, factErr error) (*Gate, *stubPolicyProvider, *stubEngine, *recordingAuditLogger) {
+	registry := NewFactRegistry()
+	registry.Register(&mockFactProvider{id: "pending_delta", value: pendingDelta, err: factErr})
+	registry.Register(&mockFactProvider{id: "max_pending_allowed", value: 500})
+
+	policies := &stubPolicyProvider{bundle: stubBundle{id: "policy-sha"}}
+	engine := &stubEngine{}
+	audit := &recordingAuditLogger{}
+	return NewGate(registry, engine, policies, audit, SnapshotOpts{}, "config-sha"), policies, engine, audit
+}
+
+func TestGate_Decide(t *testing.T) {
+	ctx := context.Background()
+	req := DecisionRequest{DeploymentID: "dep", Stage: "canary", RequestedCount: 10}
+
+	t.Run("Allow stamps versions and audits", func(t *testing.T) {
+		g, _, _, audit := newTestGate(100, nil)
+
+		decision, err := g.Decide(ctx, req)
+		if err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if !decision.Allow {
+			t.Errorf("Expected allow, got deny: %v", decision.DenyReasons)
+		}
+		if decision.PolicySHA != "policy-sha" || decision.ConfigSHA != "config-sha" {
+			t.Errorf("Expected versions to be stamped, got policy=%q config=%q", decision.PolicySHA, decision.ConfigSHA)
+		}
+		if len(audit.decisions) != 1 {
+			t.Fatalf("Expected one audited decision, got %d", len(audit.decisions))
+		}
+		record := audit.decisions[0]
+		if record.DecisionID == "" || record.DecisionID != decision.DecisionID {
+			t.Errorf("Expected the audit record to carry the decision ID %q, got %q", decision.DecisionID, record.DecisionID)
+		}
+		if record.DeploymentID != "dep" || record.Stage != "canary" || record.RequestedCount != 10 {
+			t.Errorf("Expected the request to be audited, got %+v", record)
+		}
+		if record.Outcome != OutcomeAllow || record.PolicySHA != "policy-sha" || record.ConfigSHA != "config-sha" {
+			t.Errorf("Expected an allow with versions to be audited, got %+v", record)
+		}
+		if record.UsedFacts["pending_delta"] != 100 {
+			t.Errorf("Expected facts to be audited, got %v", record.UsedFacts)
+		}
+	})
+
+	t.Run("Deny is not an error", func(t *testing.T) {
+		g, _, _, audit := newTestGate(600, nil)
+
+		decision, err := g.Decide(ctx, req)
+		if err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if decision.Allow {
+			t.Errorf("Expected deny, got allow")
+		}
+		if len(audit.decisions) != 1 || audit.decisions[0].Outcome != OutcomeDeny {
+			t.Fatalf("Expected one audited deny, got %+v", audit.decisions)
+		}
+		if reasons := audit.decisions[0].DenyReasons; len(reasons) != 1 {
+			t.Errorf("Expected deny reasons to be audited, got %v", reasons)
+		}
+	})
+
+	t.Run("Requested count is part of the question", func(t *testing.T) {
+		g, _, _, _ := newTestGate(450, nil)
+
+		decision, err := g.Decide(ctx, DecisionRequest{DeploymentID: "dep", Stage: "canary", RequestedCount: 100})
+		if err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if decision.Allow {
+			t.Errorf("Expected deny when requested count exceeds headroom, got allow")
+		}
+	})
+
+	pauses := []struct {
+		name      string
+		setup     func(*stubPolicyProvider, *stubEngine, *recordingAuditLogger)
+		factErr   error
+		wantCause error
+		wantSHA   string
+	}{
+		{
+			name:      "fact unavailable",
+			factErr:   ErrFactSourceUnavailable,
+			wantCause: ErrFactSourceUnavailable,
+			wantSHA:   "policy-sha",
+		},
+		{
+			name:      "policy load",
+			setup:     func(p *stubPolicyProvider, _ *stubEngine, _ *recordingAuditLogger) { p.err = ErrPolicyLoad },
+			wantCause: ErrPolicyLoad,
+			wantSHA:   "",
+		},
+		{
+			name:      "policy evaluation",
+			setup:     func(_ *stubPolicyProvider, e *stubEngine, _ *recordingAuditLogger) { e.err = ErrPolicyEvaluation },
+			wantCause: ErrPolicyEvaluation,
+			wantSHA:   "policy-sha",
+		},
+		{
+			name:      "audit failure",
+			setup:     func(_ *stubPolicyProvider, _ *stubEngine, a *recordingAuditLogger) { a.err = errors.New("disk full") },
+			wantCause: ErrAuditLog,
+			wantSHA:   "policy-sha",
+		},
+	}
+	for _, tt := range pauses {
+		t.Run("Pause on "+tt.name, func(t *testing.T) {
+			g, policies, engine, audit := newTestGate(100, tt.factErr)
+			if tt.setup != nil {
+				tt.setup(policies, engine, audit)
+			}
+
+			decision, err := g.Decide(ctx, req)
+
+			var pauseErr *PauseError
+			if !errors.As(err, &pauseErr) {
+				t.Fatalf("Expected *PauseError, got: %v", err)
+			}
+			if !errors.Is(err, tt.wantCause) {
+				t.Errorf("Expected error to wrap %v, got: %v", tt.wantCause, err)
+			}
+			if pauseErr.PolicySHA != tt.wantSHA || pauseErr.ConfigSHA != "config-sha" {
+				t.Errorf("Unexpected versions on pause error: policy=%q config=%q", pauseErr.PolicySHA, pauseErr.ConfigSHA)
+			}
+			if decision.Allow {
+				t.Errorf("Expected fail-safe decision to deny")
+			}
+		})
+	}
+
+	t.Run("System errors are audited", func(t *testing.T) {
+		g, _, _, audit := newTestGate(100, ErrFactStale)
+
+		_, err := g.Decide(ctx, req)
+		if len(audit.systemErrors) != 1 {
+			t.Fatalf("Expected one audited system error, got %d", len(audit.systemErrors))
+		}
+		record := audit.systemErrors[0]
+		if record.ErrorClass != "fact_stale" || record.DeploymentID != "dep" || record.Stage != "canary" {
+			t.Errorf("Expected ErrFactStale to be audited for dep/canary, got %+v", record)
+		}
+		var pauseErr *PauseError
+		if !errors.As(err, &pauseErr) || pauseErr.DecisionID != record.DecisionID {
+			t.Errorf("Expected the pause error to carry the audited decision ID %q, got %v", record.DecisionID, err)
+		}
+	})
+
+	t.Run("Invalid request", func(t *testing.T) {
+		g, _, _, audit := newTestGate(100, nil)
+
+		_, err := g.Decide(ctx, DecisionRequest{Stage: "canary"})
+		if !errors.Is(err, ErrInvalidRequest) {
+			t.Errorf("Expected ErrInvalidRequest, got: %v", err)
+		}
+		if len(audit.decisions)+len(audit.systemErrors) != 0 {
+			t.Errorf("Expected invalid request not to be audited")
+		}
+	})
+}
+
+// releaseFactProvider reports the config of the release it is collected under.
+type releaseFactProvider struct{}
+
+func (releaseFactProvider) Describe() Schema { return Schema{ID: "max_pending_allowed"} }
+
+func (releaseFactProvider) Collect(ctx context.Context, _, _ string) (Fact, error) {
+	release, ok := ReleaseFromContext(ctx)
+	if !ok {
+		return nil, ErrFactSourceUnavailable
+	}
+	return NewFact("max_pending_allowed", release.Config, time.Now()), nil
+}
+
+type stubReleaseProvider struct{ release *Release }
+
+func (p stubReleaseProvider) CurrentRelease(context.Context) (*Release, error) { return p.release, nil }
+
+func TestGate_DecideWithRelease(t *testing.T) {
+	registry := NewFactRegistry()
+	registry.Register(&mockFactProvider{id: "pending_delta", value: 100})
+	registry.Register(releaseFactProvider{})
+	release := &Release{Bundle: stubBundle{id: "release-policy"}, ConfigSHA: "release-config", Config: 50}
+	audit := &recordingAuditLogger{}
+	g := NewReleaseGate(registry, &stubEngine{}, stubReleaseProvider{release}, audit, SnapshotOpts{})
+
+	decision, err := g.Decide(context.Background(), DecisionRequest{DeploymentID: "dep", Stage: "canary"})
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	// The release's config limit of 50 is below pending_delta, so the policy must deny
+	if decision.Allow {
+		t.Errorf("Expected deny using the release config, got allow")
+	}
+	if decision.PolicySHA != "release-policy" || decision.ConfigSHA != "release-config" {
+		t.Errorf("Expected release versions to be stamped, got policy=%q config=%q", decision.PolicySHA, decision.ConfigSHA)
+	}
+	if audit.decisions[0].ConfigSHA != "release-config" {
+		t.Errorf("Expected release config to be audited, got %q", audit.decisions[0].ConfigSHA)
+	}
+}
+
+func TestGate_DecideSignedBundle(t *testing.T) {
+	registry := NewFactRegistry()
+	registry.Register(&mockFactProvider{id: "pending_delta", value: 100})
+	registry.Register(&mockFactProvider{id: "max_pending_allowed", value: 500})
+	bundle := signedStubBundle{stubBundle: stubBundle{id: "signed-policy"}, keyID: "release-2025"}
+	audit := &recordingAuditLogger{}
+	g := NewGate(registry, &stubEngine{}, &stubPolicyProvider{bundle: bundle}, audit, SnapshotOpts{}, "config")
+
+	decision, err := g.Decide(context.Background(), DecisionRequest{DeploymentID: "dep", Stage: "canary"})
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if decision.PolicyKeyID != "release-2025" {
+		t.Errorf("Expected signing key to be stamped, got %q", decision.PolicyKeyID)
+	}
+	if audit.decisions[0].PolicyKeyID != "release-2025" {
+		t.Errorf("Expected signing key to be audited, got %q", audit.decisions[0].PolicyKeyID)
+	}
+}
+
+func TestGate_DecideInputSchema(t *testing.T) {
+	violations := []SchemaViolation{
+		{Field: "facts.pending_delta", Message: "Invalid type. Expected: integer, given: string"},
+		{Field: "facts", Message: "max_pending_allowed is required"},
+	}
+	g, policies, _, audit := newTestGate(100, nil)
+	policies.bundle = schemaStubBundle{stubBundle: stubBundle{id: "policy-sha"}, violations: violations}
+
+	_, err := g.Decide(context.Background(), DecisionRequest{DeploymentID: "dep", Stage: "canary"})
+	var pauseErr *PauseError
+	if !errors.As(err, &pauseErr) {
+		t.Fatalf("Expected PauseError, got %v", err)
+	}
+	var schemaErr *InputSchemaError
+	if !errors.As(err, &schemaErr) || !errors.Is(err, ErrInputSchema) {
+		t.Fatalf("Expected InputSchemaError wrapping ErrInputSchema, got %v", err)
+	}
+	if len(schemaErr.Violations) != 2 {
+		t.Errorf("Expected every violation, got %v", schemaErr.Violations)
+	}
+	if len(audit.systemErrors) != 1 || audit.systemErrors[0].ErrorClass != "input_schema" {
+		t.Errorf("Expected one input_schema error to be audited, got %+v", audit.systemErrors)
+	}
+
+	// A bundle whose schema accepts the input decides as usual
+	policies.bundle = schemaStubBundle{stubBundle: stubBundle{id: "policy-sha"}}
+	if _, err := g.Decide(context.Background(), DecisionRequest{DeploymentID: "dep", Stage: "canary"}); err != nil {
+		t.Errorf("Expected no error but got: %v", err)
+	}
+}
+
+func TestGate_DecideOptionalFact(t *testing.T) {
+	g, _, engine, audit := newTestGate(100, nil)
+	g.registry.Register(&mockFactProvider{
+		id:     "error_rate",
+		err:    ErrFactSourceUnavailable,
+		schema: Schema{Criticality: CriticalityOptional},
+	})
+
+	decision, err := g.Decide(context.Background(), DecisionRequest{DeploymentID: "dep", Stage: "canary"})
+	if err != nil {
+		t.Fatalf("Expected an optional fact failure not to pause, got: %v", err)
+	}
+	if !decision.Allow {
+		t.Errorf("Expected allow, got deny: %v", decision.DenyReasons)
+	}
+
+	if _, ok := engine.input["facts"].(map[string]any)["error_rate"]; ok {
+		t.Errorf("Expected error_rate to be left out of input.facts")
+	}
+	missing := engine.input["meta"].(map[string]any)["missing"]
+	if !reflect.DeepEqual(missing, []string{"error_rate"}) {
+		t.Errorf("Expected input.meta.missing to list error_rate, got %v", missing)
+	}
+	if len(audit.decisions) != 1 || !reflect.DeepEqual(audit.decisions[0].MissingFacts, []string{"error_rate"}) {
+		t.Errorf("Expected the missing fact to be audited, got %+v", audit.decisions)
+	}
+}
+
+func TestGate_DecideFactMeta(t *testing.T) {
+	g, _, engine, audit := newTestGate(100, nil)
+
+	if _, err := g.Decide(context.Background(), DecisionRequest{DeploymentID: "dep", Stage: "canary"}); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	factMeta, _ := engine.input["meta"].(map[string]any)["facts"].(map[string]any)
+	for _, id := range []string{"pending_delta", "max_pending_allowed"} {
+		meta, ok := factMeta[id].(map[string]any)
+		if !ok {
+			t.Errorf("Expected input.meta.facts.%s, got %v", id, factMeta)
+			continue
+		}
+		if _, ok := meta["age_ns"].(int64); !ok {
+			t.Errorf("Expected input.meta.facts.%s.age_ns to be an integer, got %v", id, meta)
+		}
+	}
+
+	if len(audit.decisions) != 1 || len(audit.decisions[0].FactMeta) != 2 {
+		t.Fatalf("Expected the metadata of both facts to be audited, got %+v", audit.decisions)
+	}
+}
+
+// recordingObserver records what a Gate and its registry report.
+type recordingObserver struct {
+	mu        sync.Mutex
+	facts     map[string]*FactError
+	decisions []AuditRecord
+	auditErrs []error
+}
+
+func (o *recordingObserver) FactCollected(id string, _ FactMeta, err *FactError) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.facts[id] = err
+}
+
+func (o *recordingObserver) DecisionAudited(record AuditRecord, auditErr error) {
+	o.decisions = append(o.decisions, record)
+	o.auditErrs = append(o.auditErrs, auditErr)
+}
+
+func TestGate_Observer(t *testing.T) {
+	ctx := context.Background()
+	req := DecisionRequest{DeploymentID: "dep", Stage: "canary"}
+	observe := func(g *Gate) *recordingObserver {
+		o := &recordingObserver{facts: make(map[string]*FactError)}
+		g.registry.WithObserver(o)
+		g.WithObserver(o)
+		return o
+	}
+
+	t.Run("Decision", func(t *testing.T) {
+		g, _, _, _ := newTestGate(100, nil)
+		o := observe(g)
+
+		if _, err := g.Decide(ctx, req); err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if len(o.facts) != 2 || o.facts["pending_delta"] != nil || o.facts["max_pending_allowed"] != nil {
+			t.Errorf("Expected both facts to be reported collected, got %v", o.facts)
+		}
+		if len(o.decisions) != 1 || o.decisions[0].Outcome != OutcomeAllow || o.auditErrs[0] != nil {
+			t.Errorf("Expected one audited allow, got %+v, %v", o.decisions, o.auditErrs)
+		}
+	})
+
+	t.Run("Failed fact", func(t *testing.T) {
+		g, _, _, _ := newTestGate(100, ErrFactStale)
+		o := observe(g)
+
+		if _, err := g.Decide(ctx, req); err == nil {
+			t.Fatal("Expected a pause")
+		}
+		if err := o.facts["pending_delta"]; err == nil || err.Kind != FactErrorStale {
+			t.Errorf("Expected pending_delta to be reported stale, got %v", err)
+		}
+		if len(o.decisions) != 1 || o.decisions[0].ErrorClass != "fact_stale" {
+			t.Errorf("Expected the pause to be reported, got %+v", o.decisions)
+		}
+	})
+
+	t.Run("Audit failure", func(t *testing.T) {
+		g, _, _, audit := newTestGate(100, nil)
+		audit.err = errors.New("disk full")
+		o := observe(g)
+
+		if _, err := g.Decide(ctx, req); !errors.Is(err, ErrAuditLog) {
+			t.Fatalf("Expected ErrAuditLog, got %v", err)
+		}
+		if len(o.auditErrs) != 1 || o.auditErrs[0] == nil {
+			t.Errorf("Expected the audit failure to be reported, got %v", o.auditErrs)
+		}
+	})
+}
+
+func TestGate_DecideBatch(t *testing.T) {
+	g, _, _, audit := newTestGate(100, nil)
+
+	results := g.DecideBatch(context.Background(), []DecisionRequest{
+		{DeploymentID: "dep-1", Stage: "canary"},
+		{DeploymentID: "", Stage: "canary"},
+		{DeploymentID: "dep-2", Stage: "canary"},
+	})
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+	if results[0].Err != nil || !results[0].Decision.Allow {
+		t.Errorf("Expected first request to be allowed, got %+v", results[0])
+	}
+	if !errors.Is(results[1].Err, ErrInvalidRequest) {
+		t.Errorf("Expected second request to be invalid, got %v", results[1].Err)
+	}
+	if results[2].Err != nil || !results[2].Decision.Allow {
+		t.Errorf("Expected third request to be allowed, got %+v", results[2])
+	}
+	if len(audit.decisions) != 2 {
+		t.Errorf("Expected 2 audited decisions, got %d", len(audit.decisions))
+	}
+}
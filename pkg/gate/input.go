@@ -0,0 +1,70 @@
+package gate
+
+import "time"
+
+// PolicyInput is the envelope handed to the policy engine as `input`:
+//
+//	{
+//	  "request": {"deployment_id": ..., "stage": ..., "requested_count": ..., "caller": ...},
+//	  "facts":   {"<fact id>": <value>, ...},
+//	  "meta":    {"timestamp": ..., "policy_sha": ..., "policy_key_id": ..., "config_sha": ...,
+//	              "missing": [...], "facts": {"<fact id>": {"collected_at": ..., "age_ns": ..., ...}, ...}}
+//	}
+//
+// Policies read the question being asked from input.request, the system state from
+// input.facts and evaluation context from input.meta.
+type PolicyInput struct {
+	Request DecisionRequest
+	Facts   map[string]any
+	Meta    InputMeta
+}
+
+// InputMeta carries evaluation context that is neither a request field nor a fact.
+type InputMeta struct {
+	Timestamp   time.Time // When the snapshot was taken; policies should prefer it over time.now_ns()
+	PolicySHA   string
+	PolicyKeyID string // Key that signed the policy bundle; empty if signatures are not verified
+	ConfigSHA   string
+	Missing     []string            // Optional facts that could not be collected and are absent from facts
+	Facts       map[string]FactMeta // How each fact was collected, see FactMeta.Map
+}
+
+// NewPolicyInput builds the envelope for a request and its fact snapshot.
+func NewPolicyInput(req DecisionRequest, facts map[string]any, meta InputMeta) PolicyInput {
+	return PolicyInput{Request: req, Facts: facts, Meta: meta}
+}
+
+// Map renders the envelope as the map[string]any accepted by PolicyEngine.Evaluate.
+func (in PolicyInput) Map() map[string]any {
+	facts := in.Facts
+	if facts == nil {
+		facts = map[string]any{}
+	}
+	// Always a list, so that policies can test membership without checking it exists
+	missing := in.Meta.Missing
+	if missing == nil {
+		missing = []string{}
+	}
+	factMeta := make(map[string]any, len(in.Meta.Facts))
+	for id, meta := range in.Meta.Facts {
+		factMeta[id] = meta.Map()
+	}
+
+	return map[string]any{
+		"request": map[string]any{
+			"deployment_id":   in.Request.DeploymentID,
+			"stage":           in.Request.Stage,
+			"requested_count": in.Request.RequestedCount,
+			"caller":          in.Request.Caller,
+		},
+		"facts": facts,
+		"meta": map[string]any{
+			"timestamp":     in.Meta.Timestamp.UTC().Format(time.RFC3339Nano),
+			"policy_sha":    in.Meta.PolicySHA,
+			"policy_key_id": in.Meta.PolicyKeyID,
+			"config_sha":    in.Meta.ConfigSHA,
+			"missing":       missing,
+			"facts":         factMeta,
+		},
+	}
+}
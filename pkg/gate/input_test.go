@@ -0,0 +1,68 @@
+package gate
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPolicyInput_Map(t *testing.T) {
+	ts := time.Date(2025, 4, 1, 12, 0, 0, 0, time.FixedZone("PDT", -7*60*60))
+	req := DecisionRequest{DeploymentID: "dep", Stage: "canary", RequestedCount: 10, Caller: "worker-1"}
+	facts := map[string]any{"pending_delta": 100}
+
+	factMeta := map[string]FactMeta{"pending_delta": {
+		CollectedAt: ts.Add(-5 * time.Second),
+		Age:         5 * time.Second,
+		Latency:     20 * time.Millisecond,
+		CacheHit:    true,
+		Source:      "http://levelsrv/metrics/pending_delta",
+	}}
+
+	got := NewPolicyInput(req, facts, InputMeta{
+		Timestamp:   ts,
+		PolicySHA:   "policy-sha",
+		PolicyKeyID: "release-key",
+		ConfigSHA:   "config-sha",
+		Missing:     []string{"error_rate"},
+		Facts:       factMeta,
+	}).Map()
+
+	want := map[string]any{
+		"request": map[string]any{
+			"deployment_id":   "dep",
+			"stage":           "canary",
+			"requested_count": 10,
+			"caller":          "worker-1",
+		},
+		"facts": map[string]any{"pending_delta": 100},
+		"meta": map[string]any{
+			"timestamp":     "2025-04-01T19:00:00Z",
+			"policy_sha":    "policy-sha",
+			"policy_key_id": "release-key",
+			"config_sha":    "config-sha",
+			"missing":       []string{"error_rate"},
+			"facts": map[string]any{
+				"pending_delta": map[string]any{
+					"collected_at": "2025-04-01T18:59:55Z",
+					"age_ns":       int64(5e9),
+					"latency_ns":   int64(20e6),
+					"cache_hit":    true,
+					"source":       "http://levelsrv/metrics/pending_delta",
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unexpected input envelope:\n got: %v\nwant: %v", got, want)
+	}
+}
+
+func TestPolicyInput_MapNilFacts(t *testing.T) {
+	got := NewPolicyInput(DecisionRequest{}, nil, InputMeta{}).Map()
+
+	facts, ok := got["facts"].(map[string]any)
+	if !ok || facts == nil {
+		t.Errorf("Expected facts to be an empty object, got: %#v", got["facts"])
+	}
+}
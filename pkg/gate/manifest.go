@@ -0,0 +1,84 @@
+package gate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Manifest pins a policy bundle and the configuration it must be evaluated with.
+// Publishing a new manifest is how a new policy/config pair is released:
+//
+//	{
+//	  "version": "v2",
+//	  "policy": "policy-v2.rego", "policy_digest": "sha256:…",
+//	  "config": "config-v1.pkl",  "config_digest": "sha256:…",
+//	  "input_schema": "input.json", "input_schema_digest": "sha256:…"
+//	}
+//
+// Policy, Config and InputSchema are object references, resolved relative to the manifest's
+// location. The digests are checked against the fetched content so a release is never
+// half-applied. InputSchema is optional; it is the JSON Schema policy inputs are validated
+// against, and replaces any schema a policy bundle ships.
+type Manifest struct {
+	Version           string `json:"version"`
+	Policy            string `json:"policy"`
+	PolicyDigest      string `json:"policy_digest"`
+	Config            string `json:"config"`
+	ConfigDigest      string `json:"config_digest"`
+	InputSchema       string `json:"input_schema,omitempty"`
+	InputSchemaDigest string `json:"input_schema_digest,omitempty"`
+}
+
+// ParseManifest decodes and validates a manifest. Errors wrap ErrPolicyLoad.
+func ParseManifest(data []byte) (Manifest, error) {
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return Manifest{}, fmt.Errorf("%w: decoding manifest: %v", ErrPolicyLoad, err)
+	}
+	if m.Version == "" || m.Policy == "" || m.Config == "" {
+		return Manifest{}, fmt.Errorf("%w: manifest must set version, policy and config", ErrPolicyLoad)
+	}
+	if !strings.HasPrefix(m.PolicyDigest, digestPrefix) || !strings.HasPrefix(m.ConfigDigest, digestPrefix) {
+		return Manifest{}, fmt.Errorf("%w: manifest must set sha256: policy and config digests", ErrPolicyLoad)
+	}
+	if m.InputSchema != "" && !strings.HasPrefix(m.InputSchemaDigest, digestPrefix) {
+		return Manifest{}, fmt.Errorf("%w: manifest must set a sha256: input schema digest", ErrPolicyLoad)
+	}
+	return m, nil
+}
+
+// VerifyPolicy checks fetched policy content against PolicyDigest. Errors wrap ErrPolicyLoad.
+func (m Manifest) VerifyPolicy(data []byte) error {
+	if got := Digest(data); got != m.PolicyDigest {
+		return fmt.Errorf("%w: policy %s has digest %s, manifest %s expects %s", ErrPolicyLoad, m.Policy, got, m.Version, m.PolicyDigest)
+	}
+	return nil
+}
+
+// VerifyInputSchema checks fetched input schema content against InputSchemaDigest.
+// Errors wrap ErrPolicyLoad.
+func (m Manifest) VerifyInputSchema(data []byte) error {
+	if got := Digest(data); got != m.InputSchemaDigest {
+		return fmt.Errorf("%w: input schema %s has digest %s, manifest %s expects %s", ErrPolicyLoad, m.InputSchema, got, m.Version, m.InputSchemaDigest)
+	}
+	return nil
+}
+
+// VerifyConfig checks fetched config content against ConfigDigest. Errors wrap ErrConfigLoad.
+func (m Manifest) VerifyConfig(data []byte) error {
+	if got := Digest(data); got != m.ConfigDigest {
+		return fmt.Errorf("%w: config %s has digest %s, manifest %s expects %s", ErrConfigLoad, m.Config, got, m.Version, m.ConfigDigest)
+	}
+	return nil
+}
+
+const digestPrefix = "sha256:"
+
+// Digest returns the manifest digest of data, e.g. "sha256:9f86d0…".
+func Digest(data []byte) string {
+	hash := sha256.Sum256(data)
+	return digestPrefix + hex.EncodeToString(hash[:])
+}
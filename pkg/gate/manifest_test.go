@@ -0,0 +1,61 @@
+package gate
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestParseManifest(t *testing.T) {
+	policyDigest, configDigest := Digest([]byte("policy")), Digest([]byte("config"))
+	data := fmt.Sprintf(`{"version": "v1", "policy": "policy-v1.rego", "policy_digest": %q, "config": "config-v1.pkl", "config_digest": %q}`,
+		policyDigest, configDigest)
+
+	m, err := ParseManifest([]byte(data))
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	want := Manifest{Version: "v1", Policy: "policy-v1.rego", PolicyDigest: policyDigest, Config: "config-v1.pkl", ConfigDigest: configDigest}
+	if m != want {
+		t.Errorf("Unexpected manifest: %+v", m)
+	}
+
+	for _, data := range []string{
+		`not json`,
+		`{}`,
+		`{"version": "v1", "policy": "policy-v1.rego"}`,
+		`{"version": "v1", "policy": "policy-v1.rego", "config": "config-v1.pkl"}`,
+		`{"version": "v1", "policy": "policy-v1.rego", "policy_digest": "md5:abc", "config": "config-v1.pkl", "config_digest": "md5:def"}`,
+		fmt.Sprintf(`{"version": "v1", "policy": "policy-v1.rego", "policy_digest": %q, "config": "config-v1.pkl", "config_digest": %q, "input_schema": "input.json"}`,
+			policyDigest, configDigest),
+	} {
+		if _, err := ParseManifest([]byte(data)); !errors.Is(err, ErrPolicyLoad) {
+			t.Errorf("Expected ErrPolicyLoad for %s, got: %v", data, err)
+		}
+	}
+}
+
+func TestManifest_Verify(t *testing.T) {
+	m := Manifest{Version: "v1", PolicyDigest: Digest([]byte("policy")), ConfigDigest: Digest([]byte("config"))}
+
+	if err := m.VerifyPolicy([]byte("policy")); err != nil {
+		t.Errorf("Expected policy to verify, got: %v", err)
+	}
+	if err := m.VerifyConfig([]byte("config")); err != nil {
+		t.Errorf("Expected config to verify, got: %v", err)
+	}
+	if err := m.VerifyPolicy([]byte("tampered")); !errors.Is(err, ErrPolicyLoad) {
+		t.Errorf("Expected ErrPolicyLoad for policy digest mismatch, got: %v", err)
+	}
+	if err := m.VerifyConfig([]byte("tampered")); !errors.Is(err, ErrConfigLoad) {
+		t.Errorf("Expected ErrConfigLoad for config digest mismatch, got: %v", err)
+	}
+
+	m.InputSchema, m.InputSchemaDigest = "input.json", Digest([]byte("schema"))
+	if err := m.VerifyInputSchema([]byte("schema")); err != nil {
+		t.Errorf("Expected input schema to verify, got: %v", err)
+	}
+	if err := m.VerifyInputSchema([]byte("tampered")); !errors.Is(err, ErrPolicyLoad) {
+		t.Errorf("Expected ErrPolicyLoad for input schema digest mismatch, got: %v", err)
+	}
+}
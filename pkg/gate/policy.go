@@ -8,9 +8,41 @@ type PolicyBundle interface {
 	Data() []byte // The policy data
 }
 
+// SignedPolicyBundle is a PolicyBundle that may have been signature-verified when it was loaded.
+type SignedPolicyBundle interface {
+	PolicyBundle
+	SigningKeyID() string // ID of the key that signed the bundle; empty if unsigned
+}
+
+// SchemaPolicyBundle is a PolicyBundle that may carry a schema for its input.
+type SchemaPolicyBundle interface {
+	PolicyBundle
+	// ValidateInput checks an input rendered with PolicyInput.Map against the bundle's input
+	// schema, returning an *InputSchemaError listing every violation. Bundles without a
+	// schema accept any input.
+	ValidateInput(ctx context.Context, input map[string]any) error
+}
+
+// validateInput checks input against b's input schema, if it has one.
+func validateInput(ctx context.Context, b PolicyBundle, input map[string]any) error {
+	if validated, ok := b.(SchemaPolicyBundle); ok {
+		return validated.ValidateInput(ctx, input)
+	}
+	return nil
+}
+
+// signingKeyID returns the ID of the key that signed b, or "" if it was not verified.
+func signingKeyID(b PolicyBundle) string {
+	if signed, ok := b.(SignedPolicyBundle); ok {
+		return signed.SigningKeyID()
+	}
+	return ""
+}
+
 // PolicyProvider retrieves PolicyBundles.
 type PolicyProvider interface {
 	// GetPolicyBundle fetches the current policy bundle (e.g., from file/S3).
-	// Implementations handle polling/updates. Should return ErrPolicyLoad on failure.
+	// Implementations handle polling/updates. Should return ErrPolicyLoad on failure,
+	// including when a bundle's signature cannot be verified.
 	GetPolicyBundle(ctx context.Context) (PolicyBundle, error)
 }
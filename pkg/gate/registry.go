@@ -2,7 +2,9 @@ package gate
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -10,14 +12,58 @@ import (
 )
 
 // SnapshotOpts lets the caller tune latency / staleness guarantees.
+//
+// Each fact's limits are, in order of precedence, its entry in Facts, its schema's
+// MaxStaleness and Timeout, then MaxAge and PerProviderTimeout. MaxAgeCap and TimeoutCap
+// bound them all.
 type SnapshotOpts struct {
-	MaxAge             time.Duration // zero => no age check
-	PerProviderTimeout time.Duration // enforced with ctx.WithTimeout
+	MaxAge             time.Duration // default; zero => no age check
+	PerProviderTimeout time.Duration // default; enforced with ctx.WithTimeout
+	MaxAgeCap          time.Duration // zero => no cap
+	TimeoutCap         time.Duration // zero => no cap
+	Facts              map[string]FactBudget
+}
+
+// FactBudget overrides the limits of one fact. Zero fields are unset.
+type FactBudget struct {
+	MaxAge  time.Duration
+	Timeout time.Duration
+}
+
+// budget returns the limits that apply to the fact described by schema.
+func (o SnapshotOpts) budget(schema Schema) FactBudget {
+	pick := func(override, declared, fallback, limit time.Duration) time.Duration {
+		d := fallback
+		if declared > 0 {
+			d = declared
+		}
+		if override > 0 {
+			d = override
+		}
+		if limit > 0 && (d == 0 || d > limit) {
+			d = limit
+		}
+		return d
+	}
+	override := o.Facts[schema.ID]
+	return FactBudget{
+		MaxAge:  pick(override.MaxAge, schema.MaxStaleness, o.MaxAge, o.MaxAgeCap),
+		Timeout: pick(override.Timeout, schema.Timeout, o.PerProviderTimeout, o.TimeoutCap),
+	}
+}
+
+// FactObserver is notified of every fact the registry collects, e.g. to export metrics.
+// It is called from the collecting goroutines, so it must be safe for concurrent use.
+type FactObserver interface {
+	// FactCollected reports how fact id was collected, and err if it failed.
+	// meta is partially filled in for failures: Latency is always set, Age only if the fact was read.
+	FactCollected(id string, meta FactMeta, err *FactError)
 }
 
 // FactRegistry holds a collection of FactProviders and orchestrates fact collection.
 type FactRegistry struct {
 	providers map[string]FactProvider
+	observer  FactObserver
 	mu        sync.RWMutex
 }
 
@@ -38,6 +84,15 @@ func (r *FactRegistry) Register(provider FactProvider) {
 	r.providers[schema.ID] = provider
 }
 
+// WithObserver sets the observer notified of every collected fact.
+func (r *FactRegistry) WithObserver(observer FactObserver) *FactRegistry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.observer = observer
+	return r
+}
+
 // GetProvider retrieves a FactProvider by ID.
 func (r *FactRegistry) GetProvider(factID string) (FactProvider, bool) {
 	r.mu.RLock()
@@ -54,15 +109,49 @@ func (r *FactRegistry) Snapshot(ctx context.Context, deploymentID, stage string)
 	return r.SnapshotWithOpts(ctx, deploymentID, stage, SnapshotOpts{})
 }
 
+// Schemas returns the schemas of the registered providers, sorted by fact ID.
+func (r *FactRegistry) Schemas() []Schema {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schemas := make([]Schema, 0, len(r.providers))
+	for _, provider := range r.providers {
+		schemas = append(schemas, provider.Describe())
+	}
+	sort.Slice(schemas, func(i, j int) bool { return schemas[i].ID < schemas[j].ID })
+	return schemas
+}
+
+// FactSnapshot is the result of collecting facts for one decision.
+type FactSnapshot struct {
+	Facts   map[string]any      // Fact values keyed by fact ID
+	Meta    map[string]FactMeta // How each fact in Facts was collected
+	Missing []string            // Optional facts that could not be collected, sorted
+}
+
 // SnapshotWithOpts collects all facts from registered providers with the given options.
-// Uses parallel collection with errgroup and applies staleness checks.
+// Optional facts that could not be collected are left out; see Collect.
 func (r *FactRegistry) SnapshotWithOpts(ctx context.Context, deploymentID, stage string, opts SnapshotOpts) (map[string]any, error) {
+	snapshot, err := r.Collect(ctx, deploymentID, stage, opts)
+	return snapshot.Facts, err
+}
+
+// Collect collects all facts from registered providers with the given options.
+// Uses parallel collection with errgroup and applies staleness checks. A fact is stale if
+// it is older than its limit, see SnapshotOpts, and is rejected with ErrFactInvalid if it
+// contradicts its schema.
+//
+// An optional fact that fails is left out of the facts and listed in FactSnapshot.Missing.
+// If a required fact fails, Collect returns a *SnapshotError listing every failed fact,
+// along with the facts that were collected, for diagnostics only.
+func (r *FactRegistry) Collect(ctx context.Context, deploymentID, stage string, opts SnapshotOpts) (FactSnapshot, error) {
 	r.mu.RLock()
 	// Create a copy of the providers map to avoid holding the lock during collection
 	providers := make(map[string]FactProvider, len(r.providers))
 	for id, provider := range r.providers {
 		providers[id] = provider
 	}
+	observer := r.observer
 	r.mu.RUnlock()
 
 	// Set up errgroup for parallel collection
@@ -70,9 +159,10 @@ func (r *FactRegistry) SnapshotWithOpts(ctx context.Context, deploymentID, stage
 
 	// Channel to collect results from goroutines
 	type result struct {
-		id  string
-		val any
-		err error
+		id   string
+		val  any
+		meta FactMeta
+		err  *FactError
 	}
 	results := make(chan result, len(providers))
 
@@ -80,56 +170,111 @@ func (r *FactRegistry) SnapshotWithOpts(ctx context.Context, deploymentID, stage
 	for id, provider := range providers {
 		id, provider := id, provider // Capture loop variables
 		g.Go(func() error {
+			schema := provider.Describe()
+			budget := opts.budget(schema)
+
 			// Apply per-provider timeout if specified
 			pctx := gctx
-			if opts.PerProviderTimeout > 0 {
+			if budget.Timeout > 0 {
 				var cancel context.CancelFunc
-				pctx, cancel = context.WithTimeout(gctx, opts.PerProviderTimeout)
+				pctx, cancel = context.WithTimeout(gctx, budget.Timeout)
 				defer cancel()
 			}
 
 			// Collect the fact
+			var meta FactMeta
+			fail := func(kind FactErrorKind, err error) error {
+				factErr := &FactError{FactID: id, Kind: kind, Optional: schema.Optional(), Err: err}
+				if observer != nil {
+					observer.FactCollected(id, meta, factErr)
+				}
+				results <- result{id: id, err: factErr}
+				return nil // We collect errors via channel, don't fail the errgroup
+			}
+			start := time.Now()
 			fact, err := provider.Collect(pctx, deploymentID, stage)
+			meta.Latency = time.Since(start)
 			if err != nil {
-				results <- result{id: id, err: fmt.Errorf("collecting fact %s: %w", id, err)}
-				return nil // We collect errors via channel, don't fail the errgroup
+				kind := factErrorKind(err)
+				if errors.Is(pctx.Err(), context.DeadlineExceeded) {
+					// Providers do not always wrap the context's error
+					kind = FactErrorTimeout
+				}
+				return fail(kind, err)
 			}
 
-			// Check staleness if MaxAge is specified
-			if opts.MaxAge > 0 && time.Since(fact.Timestamp()) > opts.MaxAge {
-				results <- result{id: id, err: fmt.Errorf("collecting fact %s: %w", id, ErrFactStale)}
-				return nil
+			// Check staleness against the fact's limit
+			meta.CollectedAt = fact.Timestamp().UTC()
+			meta.Age = time.Since(fact.Timestamp())
+			if budget.MaxAge > 0 && meta.Age > budget.MaxAge {
+				return fail(FactErrorStale, fmt.Errorf("%w: %s old, limit %s", ErrFactStale, meta.Age.Round(time.Millisecond), budget.MaxAge))
+			}
+
+			// Reject output that contradicts the provider's declared schema
+			if fact.ID() != id {
+				return fail(FactErrorOther, fmt.Errorf("%w: provider returned fact %s", ErrFactInvalid, fact.ID()))
+			}
+			if err := schema.Validate(fact.Value()); err != nil {
+				return fail(FactErrorOther, err)
 			}
 
 			// Send successful result
-			results <- result{id: fact.ID(), val: fact.Value(), err: nil}
+			if sourced, ok := fact.(SourcedFact); ok {
+				meta.Source, meta.CacheHit = sourced.Source(), sourced.CacheHit()
+			}
+			if observer != nil {
+				observer.FactCollected(id, meta, nil)
+			}
+			results <- result{id: fact.ID(), val: fact.Value(), meta: meta}
 			return nil
 		})
 	}
 
 	// Wait for all goroutines to complete
 	if err := g.Wait(); err != nil {
-		return nil, err // This shouldn't happen as we collect errors via channel
+		return FactSnapshot{}, err // This shouldn't happen as we collect errors via channel
 	}
 	close(results)
 
 	// Process results
-	resultMap := make(map[string]any, len(providers))
-	var firstErr error
+	snapshot := FactSnapshot{
+		Facts: make(map[string]any, len(providers)),
+		Meta:  make(map[string]FactMeta, len(providers)),
+	}
+	var failed []*FactError
+	requiredFailed := false
 
 	for res := range results {
-		if res.err != nil {
-			if firstErr == nil {
-				firstErr = res.err
-			}
+		if res.err == nil {
+			snapshot.Facts[res.id], snapshot.Meta[res.id] = res.val, res.meta
 			continue
 		}
-		resultMap[res.id] = res.val
+		failed = append(failed, res.err)
+		if res.err.Optional {
+			snapshot.Missing = append(snapshot.Missing, res.id)
+		} else {
+			requiredFailed = true
+		}
 	}
+	sort.Strings(snapshot.Missing)
 
-	if firstErr != nil {
-		return nil, firstErr
+	if requiredFailed {
+		sort.Slice(failed, func(i, j int) bool { return failed[i].FactID < failed[j].FactID })
+		return snapshot, &SnapshotError{Errors: failed}
 	}
+	return snapshot, nil
+}
 
-	return resultMap, nil
+// factErrorKind classifies an error returned by FactProvider.Collect.
+func factErrorKind(err error) FactErrorKind {
+	switch {
+	case errors.Is(err, ErrFactStale):
+		return FactErrorStale
+	case errors.Is(err, context.DeadlineExceeded):
+		return FactErrorTimeout
+	case errors.Is(err, ErrFactSourceUnavailable):
+		return FactErrorUnavailable
+	default:
+		return FactErrorOther
+	}
 }
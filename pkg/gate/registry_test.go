@@ -3,27 +3,47 @@ package gate
 import (
 	"context"
 	"errors"
+	"fmt"
+	"reflect"
 	"testing"
 	"time"
 )
 
 // mockFactProvider is an in-package mock for testing
 type mockFactProvider struct {
-	id    string
-	desc  string
-	value any
-	err   error
+	id     string
+	desc   string
+	value  any
+	err    error
+	schema Schema    // declared schema; ID and Description come from id and desc
+	at     time.Time // fact timestamp; zero means now
 }
 
 func (m *mockFactProvider) Describe() Schema {
-	return Schema{ID: m.id, Description: m.desc}
+	schema := m.schema
+	schema.ID, schema.Description = m.id, m.desc
+	return schema
 }
 
 func (m *mockFactProvider) Collect(ctx context.Context, deploymentID, stage string) (Fact, error) {
 	if m.err != nil {
 		return nil, m.err
 	}
-	return NewFact(m.id, m.value, time.Now()), nil
+	at := m.at
+	if at.IsZero() {
+		at = time.Now()
+	}
+	return NewFact(m.id, m.value, at), nil
+}
+
+// slowFactProvider blocks until its context is done.
+type slowFactProvider struct{ id string }
+
+func (p slowFactProvider) Describe() Schema { return Schema{ID: p.id} }
+
+func (p slowFactProvider) Collect(ctx context.Context, _, _ string) (Fact, error) {
+	<-ctx.Done()
+	return nil, errors.New("request canceled") // as providers that do not wrap ctx.Err() report it
 }
 
 func TestFactRegistry(t *testing.T) {
@@ -99,4 +119,209 @@ func TestFactRegistry(t *testing.T) {
 			t.Errorf("Unexpected error message: %v", err)
 		}
 	})
+
+	t.Run("Collect reports every failed fact", func(t *testing.T) {
+		registry := NewFactRegistry()
+		registry.Register(&mockFactProvider{id: "pending_delta", value: 100})
+		registry.Register(&mockFactProvider{id: "max_pending_allowed", value: 500, at: time.Now().Add(-time.Hour)})
+		registry.Register(&mockFactProvider{id: "crash_rate", err: fmt.Errorf("%w: connection refused", ErrFactSourceUnavailable)})
+		registry.Register(slowFactProvider{id: "error_rate"})
+		registry.Register(&mockFactProvider{id: "canary_health", err: errors.New("boom"), schema: Schema{Criticality: CriticalityOptional}})
+
+		snapshot, err := registry.Collect(context.Background(), "test-deployment", "test-stage", SnapshotOpts{
+			MaxAge:             time.Minute,
+			PerProviderTimeout: 10 * time.Millisecond,
+		})
+		var snapshotErr *SnapshotError
+		if !errors.As(err, &snapshotErr) {
+			t.Fatalf("Expected a SnapshotError, got %v", err)
+		}
+
+		type failure struct {
+			id       string
+			kind     FactErrorKind
+			optional bool
+		}
+		var got []failure
+		for _, fe := range snapshotErr.Errors {
+			got = append(got, failure{fe.FactID, fe.Kind, fe.Optional})
+		}
+		want := []failure{
+			{"canary_health", FactErrorOther, true},
+			{"crash_rate", FactErrorUnavailable, false},
+			{"error_rate", FactErrorTimeout, false},
+			{"max_pending_allowed", FactErrorStale, false},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Expected failures %v, got %v", want, got)
+		}
+
+		if !errors.Is(err, ErrFactStale) || !errors.Is(err, ErrFactSourceUnavailable) {
+			t.Errorf("Expected the error to match ErrFactStale and ErrFactSourceUnavailable: %v", err)
+		}
+		if !reflect.DeepEqual(snapshot.Facts, map[string]any{"pending_delta": 100}) {
+			t.Errorf("Expected the collected facts alongside the error, got %v", snapshot.Facts)
+		}
+		if !reflect.DeepEqual(snapshot.Missing, []string{"canary_health"}) {
+			t.Errorf("Expected canary_health to be missing, got %v", snapshot.Missing)
+		}
+	})
+	t.Run("Snapshot rejects values that contradict the schema", func(t *testing.T) {
+		count := Schema{Type: TypeInteger, Minimum: Bound(0)}
+		for name, value := range map[string]any{"wrong type": "100", "out of range": -1} {
+			registry := NewFactRegistry()
+			registry.Register(&mockFactProvider{id: "pending_delta", value: value, schema: count})
+
+			_, err := registry.Snapshot(context.Background(), "test-deployment", "test-stage")
+			if !errors.Is(err, ErrFactInvalid) {
+				t.Errorf("%s: expected ErrFactInvalid, got %v", name, err)
+			}
+		}
+	})
+
+	t.Run("Collect records how each fact was collected", func(t *testing.T) {
+		registry := NewFactRegistry()
+		collectedAt := time.Now().Add(-time.Minute)
+		registry.Register(&mockFactProvider{id: "pending_delta", value: 100, at: collectedAt})
+
+		snapshot, err := registry.Collect(context.Background(), "test-deployment", "test-stage", SnapshotOpts{})
+		if err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		meta, ok := snapshot.Meta["pending_delta"]
+		if !ok {
+			t.Fatalf("Expected metadata for pending_delta, got %v", snapshot.Meta)
+		}
+		if !meta.CollectedAt.Equal(collectedAt) || meta.CollectedAt.Location() != time.UTC {
+			t.Errorf("Expected collected_at %v in UTC, got %v", collectedAt, meta.CollectedAt)
+		}
+		if meta.Age < time.Minute || meta.Age > 2*time.Minute {
+			t.Errorf("Expected an age of about a minute, got %v", meta.Age)
+		}
+		if meta.Latency < 0 || meta.CacheHit || meta.Source != "" {
+			t.Errorf("Unexpected metadata %+v", meta)
+		}
+	})
+
+	t.Run("Collect leaves out optional facts that fail", func(t *testing.T) {
+		registry := NewFactRegistry()
+		registry.Register(&mockFactProvider{id: "pending_delta", value: 100})
+		registry.Register(&mockFactProvider{id: "error_rate", err: ErrFactSourceUnavailable, schema: Schema{Criticality: CriticalityOptional}})
+		registry.Register(&mockFactProvider{id: "crash_rate", value: "high", schema: Schema{Type: TypeNumber, Criticality: CriticalityOptional}})
+
+		snapshot, err := registry.Collect(context.Background(), "test-deployment", "test-stage", SnapshotOpts{})
+		if err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if !reflect.DeepEqual(snapshot.Facts, map[string]any{"pending_delta": 100}) {
+			t.Errorf("Expected only pending_delta, got %v", snapshot.Facts)
+		}
+		if !reflect.DeepEqual(snapshot.Missing, []string{"crash_rate", "error_rate"}) {
+			t.Errorf("Expected crash_rate and error_rate to be missing, got %v", snapshot.Missing)
+		}
+
+		// A required fact failing still fails the snapshot
+		registry.Register(&mockFactProvider{id: "max_pending_allowed", err: ErrFactStale})
+		if _, err := registry.Collect(context.Background(), "test-deployment", "test-stage", SnapshotOpts{}); !errors.Is(err, ErrFactStale) {
+			t.Errorf("Expected ErrFactStale, got %v", err)
+		}
+	})
+
+	t.Run("Snapshot applies each fact's staleness limit", func(t *testing.T) {
+		registry := NewFactRegistry()
+		registry.Register(&mockFactProvider{
+			id:     "error_rate",
+			value:  0.01,
+			schema: Schema{MaxStaleness: time.Minute},
+			at:     time.Now().Add(-2 * time.Minute),
+		})
+		registry.Register(&mockFactProvider{
+			id:     "crash_rate",
+			value:  0.001,
+			schema: Schema{MaxStaleness: 5 * time.Minute},
+			at:     time.Now().Add(-2 * time.Minute),
+		})
+		ctx := context.Background()
+
+		// Each fact's own limit replaces the default
+		_, err := registry.SnapshotWithOpts(ctx, "test-deployment", "test-stage", SnapshotOpts{MaxAge: 30 * time.Second})
+		var snapshotErr *SnapshotError
+		if !errors.As(err, &snapshotErr) || len(snapshotErr.Errors) != 1 || snapshotErr.Errors[0].FactID != "error_rate" {
+			t.Errorf("Expected only error_rate to be stale, got %v", err)
+		}
+
+		// Overrides replace the fact's own limit
+		facts, err := registry.SnapshotWithOpts(ctx, "test-deployment", "test-stage", SnapshotOpts{
+			MaxAge: 30 * time.Second,
+			Facts:  map[string]FactBudget{"error_rate": {MaxAge: 3 * time.Minute}},
+		})
+		if err != nil || len(facts) != 2 {
+			t.Errorf("Expected both facts to be fresh, got %v, %v", facts, err)
+		}
+
+		// The cap bounds every limit
+		_, err = registry.SnapshotWithOpts(ctx, "test-deployment", "test-stage", SnapshotOpts{
+			MaxAgeCap: time.Minute,
+			Facts:     map[string]FactBudget{"error_rate": {MaxAge: 3 * time.Minute}},
+		})
+		if !errors.As(err, &snapshotErr) || len(snapshotErr.Errors) != 2 {
+			t.Errorf("Expected both facts to be stale, got %v", err)
+		}
+	})
+
+	t.Run("Snapshot applies each fact's timeout", func(t *testing.T) {
+		registry := NewFactRegistry()
+		registry.Register(slowFactProvider{id: "error_rate"})
+
+		start := time.Now()
+		_, err := registry.SnapshotWithOpts(context.Background(), "test-deployment", "test-stage", SnapshotOpts{
+			PerProviderTimeout: time.Minute,
+			Facts:              map[string]FactBudget{"error_rate": {Timeout: 10 * time.Millisecond}},
+		})
+		if !errors.Is(err, ErrFactSourceUnavailable) {
+			t.Errorf("Expected a timeout, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > 10*time.Second {
+			t.Errorf("Expected the fact's timeout to apply, took %v", elapsed)
+		}
+	})
+
+	t.Run("Schemas", func(t *testing.T) {
+		registry := NewFactRegistry()
+		registry.Register(&mockFactProvider{id: "fact2", desc: "Fact 2"})
+		registry.Register(&mockFactProvider{id: "fact1", desc: "Fact 1", schema: Schema{Type: TypeInteger}})
+
+		schemas := registry.Schemas()
+		if len(schemas) != 2 || schemas[0].ID != "fact1" || schemas[1].ID != "fact2" {
+			t.Fatalf("Expected schemas for fact1 and fact2, got %+v", schemas)
+		}
+		if schemas[0].Type != TypeInteger {
+			t.Errorf("Expected fact1 to be an integer, got %q", schemas[0].Type)
+		}
+	})
+}
+
+func TestSnapshotOpts_budget(t *testing.T) {
+	declared := Schema{ID: "crash_rate", MaxStaleness: 5 * time.Minute, Timeout: 3 * time.Second}
+
+	tests := []struct {
+		name   string
+		opts   SnapshotOpts
+		schema Schema
+		want   FactBudget
+	}{
+		{"defaults", SnapshotOpts{MaxAge: 30 * time.Second, PerProviderTimeout: time.Second}, Schema{ID: "crash_rate"}, FactBudget{30 * time.Second, time.Second}},
+		{"declared", SnapshotOpts{MaxAge: 30 * time.Second, PerProviderTimeout: time.Second}, declared, FactBudget{5 * time.Minute, 3 * time.Second}},
+		{"override", SnapshotOpts{Facts: map[string]FactBudget{"crash_rate": {MaxAge: 10 * time.Minute}}}, declared, FactBudget{10 * time.Minute, 3 * time.Second}},
+		{"other fact's override", SnapshotOpts{Facts: map[string]FactBudget{"error_rate": {MaxAge: time.Second}}}, declared, FactBudget{5 * time.Minute, 3 * time.Second}},
+		{"capped", SnapshotOpts{MaxAgeCap: time.Minute, TimeoutCap: 2 * time.Second}, declared, FactBudget{time.Minute, 2 * time.Second}},
+		{"cap without a limit", SnapshotOpts{MaxAgeCap: time.Minute}, Schema{}, FactBudget{MaxAge: time.Minute}},
+		{"none", SnapshotOpts{}, Schema{}, FactBudget{}},
+	}
+
+	for _, tt := range tests {
+		if got := tt.opts.budget(tt.schema); got != tt.want {
+			t.Errorf("%s: budget() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
 }
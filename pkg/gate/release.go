@@ -0,0 +1,62 @@
+package gate
+
+import (
+	"context"
+	"strings"
+)
+
+// Release is a policy bundle together with the configuration it must be evaluated with.
+// A Release is immutable; hot reloads swap in a new Release rather than modifying one.
+type Release struct {
+	Manifest  Manifest
+	Bundle    PolicyBundle
+	ConfigSHA string
+	Config    any // The decoded configuration, e.g. *config.AppConfig
+}
+
+// ReleaseProvider supplies the current Release.
+type ReleaseProvider interface {
+	// CurrentRelease returns the release decisions should use right now.
+	// Should return ErrPolicyLoad or ErrConfigLoad if no release is available.
+	CurrentRelease(ctx context.Context) (*Release, error)
+}
+
+// NewRelease creates a Release from a manifest, its compiled bundle and its decoded config.
+// The config SHA is taken from the manifest's config digest.
+func NewRelease(m Manifest, bundle PolicyBundle, config any) *Release {
+	return &Release{
+		Manifest:  m,
+		Bundle:    bundle,
+		ConfigSHA: strings.TrimPrefix(m.ConfigDigest, digestPrefix),
+		Config:    config,
+	}
+}
+
+type releaseKey struct{}
+
+// WithRelease returns a context carrying the release a decision is being made with.
+// Gate sets it before collecting facts so that config-backed fact providers can read
+// the configuration paired with the policy being evaluated.
+func WithRelease(ctx context.Context, r *Release) context.Context {
+	return context.WithValue(ctx, releaseKey{}, r)
+}
+
+// ReleaseFromContext returns the release set by WithRelease, if any.
+func ReleaseFromContext(ctx context.Context) (*Release, bool) {
+	r, ok := ctx.Value(releaseKey{}).(*Release)
+	return r, ok && r != nil
+}
+
+// policyRelease adapts a PolicyProvider and a fixed config SHA to a ReleaseProvider.
+type policyRelease struct {
+	policies  PolicyProvider
+	configSHA string
+}
+
+func (p policyRelease) CurrentRelease(ctx context.Context) (*Release, error) {
+	bundle, err := p.policies.GetPolicyBundle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Release{Bundle: bundle, ConfigSHA: p.configSHA}, nil
+}
@@ -0,0 +1,30 @@
+package gate
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNewRelease(t *testing.T) {
+	m := Manifest{Version: "v1", ConfigDigest: Digest([]byte("config"))}
+	r := NewRelease(m, stubBundle{id: "policy-sha"}, 42)
+
+	if r.ConfigSHA != m.ConfigDigest[len("sha256:"):] {
+		t.Errorf("Expected config SHA to be the bare config digest, got %q", r.ConfigSHA)
+	}
+	if r.Bundle.ID() != "policy-sha" || r.Config != 42 {
+		t.Errorf("Unexpected release: %+v", r)
+	}
+}
+
+func TestReleaseFromContext(t *testing.T) {
+	if _, ok := ReleaseFromContext(context.Background()); ok {
+		t.Errorf("Expected no release on a bare context")
+	}
+
+	r := &Release{ConfigSHA: "config-sha"}
+	got, ok := ReleaseFromContext(WithRelease(context.Background(), r))
+	if !ok || got != r {
+		t.Errorf("Expected release to round-trip through the context, got %v", got)
+	}
+}
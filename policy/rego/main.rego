@@ -1,16 +1,24 @@
 package gate
 
+# Input envelope (see pkg/gate/input.go):
+#   input.request - deployment_id, stage, requested_count, caller
+#   input.facts   - fact values keyed by fact ID
+#   input.meta    - timestamp, policy_sha, policy_key_id, config_sha
+
 default allow := false
 default deny_reasons := []
 
-# Simple policy for initial testing: check if pending_delta is within allowed limit
+# Devices that would be pending if this request were granted
+projected_pending := input.facts.pending_delta + object.get(input.request, "requested_count", 0)
+
+# Allow the request only if it keeps pending devices within the allowed limit
 allow if {
-    input.pending_delta <= input.max_pending_allowed
+    projected_pending <= input.facts.max_pending_allowed
 }
 
 deny_reasons := ["pending_delta exceeds allowed limit"] if {
     not allow
-    input.pending_delta > input.max_pending_allowed
+    projected_pending > input.facts.max_pending_allowed
 }
 
 # Return a structured response for easier consumption by the engine
@@ -3,8 +3,9 @@ package gate
 test_allow_when_within_limit if {
     # Setup test case
     test_data := {
-        "pending_delta": 100,
-        "max_pending_allowed": 500
+        "request": {"deployment_id": "dep-1", "stage": "canary", "requested_count": 10},
+        "facts": {"pending_delta": 100, "max_pending_allowed": 500},
+        "meta": {}
     }
 
     # Evaluate the policy
@@ -18,8 +19,9 @@ test_allow_when_within_limit if {
 test_deny_when_exceeds_limit if {
     # Setup test case
     test_data := {
-        "pending_delta": 600,
-        "max_pending_allowed": 500
+        "request": {"deployment_id": "dep-1", "stage": "canary", "requested_count": 0},
+        "facts": {"pending_delta": 600, "max_pending_allowed": 500},
+        "meta": {}
     }
 
     # Evaluate the policy
@@ -29,3 +31,34 @@ test_deny_when_exceeds_limit if {
     result.allow == false
     result.deny_reasons[0] == "pending_delta exceeds allowed limit"
 }
+
+test_deny_when_requested_count_exceeds_headroom if {
+    # Setup test case: 450 pending leaves room for 50 more devices
+    test_data := {
+        "request": {"deployment_id": "dep-1", "stage": "canary", "requested_count": 100},
+        "facts": {"pending_delta": 450, "max_pending_allowed": 500},
+        "meta": {}
+    }
+
+    # Evaluate the policy
+    result := response with input as test_data
+
+    # Assert the result
+    result.allow == false
+    result.deny_reasons[0] == "pending_delta exceeds allowed limit"
+}
+
+test_allow_when_requested_count_fills_headroom if {
+    # Setup test case
+    test_data := {
+        "request": {"deployment_id": "dep-1", "stage": "canary", "requested_count": 50},
+        "facts": {"pending_delta": 450, "max_pending_allowed": 500},
+        "meta": {}
+    }
+
+    # Evaluate the policy
+    result := response with input as test_data
+
+    # Assert the result
+    result.allow == true
+}
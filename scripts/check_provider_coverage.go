@@ -17,14 +17,9 @@ import (
 
 // Schema represents a JSON Schema object from input.json
 type Schema struct {
-	Type       string              `json:"type"`
-	Properties map[string]Property `json:"properties"`
-	Required   []string            `json:"required"`
-}
-
-// Property represents a JSON Schema property
-type Property struct {
-	Type string `json:"type"`
+	Type       string            `json:"type"`
+	Properties map[string]Schema `json:"properties"`
+	Required   []string          `json:"required"`
 }
 
 // getRequiredFacts parses the policy input schema to get required fact IDs
@@ -39,8 +34,14 @@ func getRequiredFacts(schemaPath string) (map[string]bool, error) {
 		return nil, fmt.Errorf("failed to parse schema JSON: %w", err)
 	}
 
+	// Facts live under input.facts in the policy input envelope
+	facts, ok := schema.Properties["facts"]
+	if !ok {
+		return nil, fmt.Errorf("schema has no facts property")
+	}
+
 	requiredFacts := make(map[string]bool)
-	for _, factID := range schema.Required {
+	for _, factID := range facts.Required {
 		requiredFacts[factID] = false // not found yet
 	}
 
@@ -0,0 +1,94 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	auditdynamodb "github.com/asimihsan/planning_engine/internal/audit/dynamodb"
+	"github.com/asimihsan/planning_engine/internal/audit/dynamodb_mock"
+	"github.com/asimihsan/planning_engine/internal/engine/opa"
+	"github.com/asimihsan/planning_engine/internal/fact/mock"
+	"github.com/asimihsan/planning_engine/internal/policy/file"
+	"github.com/asimihsan/planning_engine/pkg/gate"
+)
+
+// TestDynamoDBAuditIntegration runs decisions through the gate and reads the audit trail back
+// from DynamoDB. Set DYNAMODB_ENDPOINT (e.g. http://localhost:8000) to run against DynamoDB
+// Local; otherwise an in-process mock is used.
+func TestDynamoDBAuditIntegration(t *testing.T) {
+	ctx := context.Background()
+
+	endpoint := os.Getenv("DYNAMODB_ENDPOINT")
+	if endpoint == "" {
+		mockServer := dynamodb_mock.NewServer()
+		defer mockServer.Close()
+		endpoint = mockServer.URL()
+	}
+	client := dynamodb.New(dynamodb.Options{
+		BaseEndpoint: aws.String(endpoint),
+		Region:       "us-east-1",
+		Credentials:  credentials.NewStaticCredentialsProvider("local", "local", ""),
+	})
+
+	// A fresh table per run keeps DynamoDB Local runs independent
+	table := fmt.Sprintf("DeploymentGateAuditLog-%d", time.Now().UnixNano())
+	if err := auditdynamodb.CreateTable(ctx, client, table); err != nil {
+		t.Fatalf("Failed to create audit table: %v", err)
+	}
+
+	registry := gate.NewFactRegistry()
+	pendingDelta := mock.NewProvider("pending_delta", 100, "Number of devices newly targeted")
+	registry.Register(pendingDelta)
+	registry.Register(mock.NewProvider("max_pending_allowed", 500, "Maximum allowed devices in pending state"))
+
+	g := gate.NewGate(
+		registry,
+		opa.NewEngine(),
+		file.New("../../policy/rego/main.rego", "data.gate.response"),
+		auditdynamodb.New(client, table),
+		gate.SnapshotOpts{MaxAge: 30 * time.Second, PerProviderTimeout: 2 * time.Second},
+		"test-config",
+	)
+	req := gate.DecisionRequest{DeploymentID: "test-deployment", Stage: "test-stage"}
+
+	// Allow, then deny, then pause on a fact failure
+	if _, err := g.Decide(ctx, req); err != nil {
+		t.Fatalf("Failed to decide: %v", err)
+	}
+	pendingDelta.Value = 600
+	if _, err := g.Decide(ctx, req); err != nil {
+		t.Fatalf("Failed to decide: %v", err)
+	}
+	pendingDelta.WithError(gate.ErrFactSourceUnavailable)
+	if _, err := g.Decide(ctx, req); err == nil {
+		t.Fatalf("Expected a pause when the fact source is unavailable")
+	}
+
+	out, err := client.Query(ctx, &dynamodb.QueryInput{
+		TableName:                 aws.String(table),
+		KeyConditionExpression:    aws.String("#pk = :pk"),
+		ExpressionAttributeNames:  map[string]string{"#pk": auditdynamodb.AttrPartitionKey},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":pk": &types.AttributeValueMemberS{Value: "test-deployment#test-stage"}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to query audit table: %v", err)
+	}
+
+	var outcomes []gate.Outcome
+	for _, item := range out.Items {
+		outcome, _ := item[auditdynamodb.AttrOutcome].(*types.AttributeValueMemberS)
+		outcomes = append(outcomes, gate.Outcome(outcome.Value))
+	}
+	want := []gate.Outcome{gate.OutcomeAllow, gate.OutcomeDeny, gate.OutcomeError}
+	if fmt.Sprint(outcomes) != fmt.Sprint(want) {
+		t.Errorf("Expected audit outcomes %v in order, got %v", want, outcomes)
+	}
+}
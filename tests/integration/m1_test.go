@@ -2,6 +2,7 @@ package integration
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -41,62 +42,30 @@ func TestBasicIntegration(t *testing.T) {
 	registry.Register(pendingDeltaProvider)
 	registry.Register(maxPendingProvider)
 
-	// Get the policy bundle
-	policyBundle, err := policyProvider.GetPolicyBundle(ctx)
-	if err != nil {
-		t.Fatalf("Failed to get policy bundle: %v", err)
-	}
-
-	// Get the facts using the new SnapshotWithOpts method
-	facts, err := registry.SnapshotWithOpts(ctx, "test-deployment", "test-stage", snapshotOpts)
-	if err != nil {
-		t.Fatalf("Failed to get facts: %v", err)
-	}
-
-	// Evaluate the policy
-	start := time.Now()
-	decision, err := engine.Evaluate(ctx, policyBundle, facts)
-	evalDuration := time.Since(start)
-	if err != nil {
-		t.Fatalf("Failed to evaluate policy: %v", err)
-	}
+	// The gate snapshots, evaluates and audits in one call
+	g := gate.NewGate(registry, engine, policyProvider, logger, snapshotOpts, "test-config")
+	req := gate.DecisionRequest{DeploymentID: "test-deployment", Stage: "test-stage"}
 
-	// Log the decision
-	err = logger.LogDecision(ctx, facts, decision, policyBundle.ID(), "test-config", evalDuration)
+	decision, err := g.Decide(ctx, req)
 	if err != nil {
-		t.Fatalf("Failed to log decision: %v", err)
+		t.Fatalf("Failed to decide: %v", err)
 	}
 
 	// Verify the decision (allow since pending_delta < max_pending_allowed)
 	if !decision.Allow {
 		t.Errorf("Expected decision to be allow, got deny with reasons: %v", decision.DenyReasons)
 	}
+	if decision.PolicySHA == "" || decision.ConfigSHA != "test-config" {
+		t.Errorf("Expected decision to be stamped with versions, got policy=%q config=%q", decision.PolicySHA, decision.ConfigSHA)
+	}
 
 	// Test a deny scenario by modifying the pending_delta
 	pendingDeltaProvider = mock.NewProvider("pending_delta", 600, "Number of devices newly targeted")
-	maxPendingProvider = mock.NewProvider("max_pending_allowed", 500, "Maximum allowed devices in pending state")
-	registry = gate.NewFactRegistry() // Reset the registry
-	registry.Register(pendingDeltaProvider)
-	registry.Register(maxPendingProvider)
-
-	// Get the facts again using the options
-	facts, err = registry.SnapshotWithOpts(ctx, "test-deployment", "test-stage", snapshotOpts)
-	if err != nil {
-		t.Fatalf("Failed to get facts: %v", err)
-	}
-
-	// Evaluate the policy again
-	start = time.Now()
-	decision, err = engine.Evaluate(ctx, policyBundle, facts)
-	evalDuration = time.Since(start)
-	if err != nil {
-		t.Fatalf("Failed to evaluate policy: %v", err)
-	}
+	registry.Register(pendingDeltaProvider) // Replaces the existing provider
 
-	// Log the decision
-	err = logger.LogDecision(ctx, facts, decision, policyBundle.ID(), "test-config", evalDuration)
+	decision, err = g.Decide(ctx, req)
 	if err != nil {
-		t.Fatalf("Failed to log decision: %v", err)
+		t.Fatalf("Failed to decide: %v", err)
 	}
 
 	// Verify the decision (deny since pending_delta > max_pending_allowed)
@@ -151,3 +120,38 @@ func TestStalenessBehavior(t *testing.T) {
 		t.Errorf("Expected test_fact=123, got: %v", facts["test_fact"])
 	}
 }
+
+// TestFailSafePause verifies that a fact failure surfaces as a pause rather than a deny
+func TestFailSafePause(t *testing.T) {
+	ctx := context.Background()
+
+	registry := gate.NewFactRegistry()
+	registry.Register(mock.NewProvider("pending_delta", 100, "Number of devices newly targeted").
+		WithError(gate.ErrFactSourceUnavailable))
+	registry.Register(mock.NewProvider("max_pending_allowed", 500, "Maximum allowed devices in pending state"))
+
+	g := gate.NewGate(
+		registry,
+		opa.NewEngine(),
+		file.New("../../policy/rego/main.rego", "data.gate.response"),
+		stdout.New(),
+		gate.SnapshotOpts{},
+		"test-config",
+	)
+
+	decision, err := g.Decide(ctx, gate.DecisionRequest{DeploymentID: "test-deployment", Stage: "test-stage"})
+
+	var pauseErr *gate.PauseError
+	if !errors.As(err, &pauseErr) {
+		t.Fatalf("Expected *gate.PauseError, got: %v", err)
+	}
+	if !gate.IsWrappingError(err, gate.ErrFactSourceUnavailable) {
+		t.Errorf("Expected ErrFactSourceUnavailable, got: %v", err)
+	}
+	if pauseErr.PolicySHA == "" || pauseErr.ConfigSHA != "test-config" {
+		t.Errorf("Expected pause to carry versions, got policy=%q config=%q", pauseErr.PolicySHA, pauseErr.ConfigSHA)
+	}
+	if decision.Allow {
+		t.Errorf("Expected fail-safe decision to deny")
+	}
+}
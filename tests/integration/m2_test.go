@@ -3,7 +3,6 @@ package integration
 import (
 	"context"
 	"testing"
-	"time"
 
 	"github.com/apple/pkl-go/pkl"
 
@@ -69,11 +68,9 @@ func TestLevelServerIntegration(t *testing.T) {
 	registry.Register(pendingDeltaProvider)
 	registry.Register(maxPendingProvider)
 
-	// Get the policy bundle
-	policyBundle, err := policyProvider.GetPolicyBundle(ctx)
-	if err != nil {
-		t.Fatalf("Failed to get policy bundle: %v", err)
-	}
+	// The gate snapshots, evaluates and audits in one call
+	g := gate.NewGate(registry, engine, policyProvider, logger, snapshotOpts, "test-config")
+	req := gate.DecisionRequest{DeploymentID: "test-deployment", Stage: "test-stage"}
 
 	// Get the facts using the registry
 	facts, err := registry.SnapshotWithOpts(ctx, "test-deployment", "test-stage", snapshotOpts)
@@ -89,18 +86,10 @@ func TestLevelServerIntegration(t *testing.T) {
 		t.Errorf("Expected max_pending_allowed=500, got: %v", facts["max_pending_allowed"])
 	}
 
-	// Evaluate the policy
-	start := time.Now()
-	decision, err := engine.Evaluate(ctx, policyBundle, facts)
-	evalDuration := time.Since(start)
-	if err != nil {
-		t.Fatalf("Failed to evaluate policy: %v", err)
-	}
-
-	// Log the decision
-	err = logger.LogDecision(ctx, facts, decision, policyBundle.ID(), "test-config", evalDuration)
+	// Decide
+	decision, err := g.Decide(ctx, req)
 	if err != nil {
-		t.Fatalf("Failed to log decision: %v", err)
+		t.Fatalf("Failed to decide: %v", err)
 	}
 
 	// Verify the decision (allow since pending_delta < max_pending_allowed)
@@ -120,10 +109,8 @@ func TestLevelServerIntegration(t *testing.T) {
 		"Number of devices newly targeted",
 	)
 
-	// Reset the registry and re-register providers
-	registry = gate.NewFactRegistry()
+	// Replace the cached provider in the registry the gate uses
 	registry.Register(pendingDeltaProvider)
-	registry.Register(maxPendingProvider)
 
 	// Get the facts again
 	facts, err = registry.SnapshotWithOpts(ctx, "test-deployment", "test-stage", snapshotOpts)
@@ -136,18 +123,10 @@ func TestLevelServerIntegration(t *testing.T) {
 		t.Errorf("Expected pending_delta=600, got: %v", facts["pending_delta"])
 	}
 
-	// Evaluate the policy again
-	start = time.Now()
-	decision, err = engine.Evaluate(ctx, policyBundle, facts)
-	evalDuration = time.Since(start)
-	if err != nil {
-		t.Fatalf("Failed to evaluate policy: %v", err)
-	}
-
-	// Log the decision
-	err = logger.LogDecision(ctx, facts, decision, policyBundle.ID(), "test-config", evalDuration)
+	// Decide again
+	decision, err = g.Decide(ctx, req)
 	if err != nil {
-		t.Fatalf("Failed to log decision: %v", err)
+		t.Fatalf("Failed to decide: %v", err)
 	}
 
 	// Verify the decision (deny since pending_delta > max_pending_allowed)